@@ -0,0 +1,67 @@
+/*
+Package main 多租户casbin示例服务
+
+	gin-casbin web -c ./configs/config.toml -m ./configs/model.conf --menu ./configs/menu.yaml
+*/
+package main
+
+import (
+	"context"
+	"os"
+
+	"gin-casbin/internal/app"
+	"gin-casbin/pkg/logger"
+
+	"github.com/urfave/cli/v2"
+)
+
+// VERSION 版本号
+var VERSION = "0.1.0"
+
+func main() {
+	logger.SetVersion(VERSION)
+	ctx := logger.NewTraceIDContext(context.Background(), "main")
+
+	cliApp := cli.NewApp()
+	cliApp.Name = "gin-casbin"
+	cliApp.Version = VERSION
+	cliApp.Usage = "Multiple tenancy casbin use case based on GIN + GORM + CASBIN + WIRE"
+	cliApp.Commands = []*cli.Command{
+		newWebCmd(ctx),
+	}
+	err := cliApp.Run(os.Args)
+	if err != nil {
+		logger.Errorf(ctx, err.Error())
+	}
+}
+
+func newWebCmd(ctx context.Context) *cli.Command {
+	return &cli.Command{
+		Name:  "web",
+		Usage: "Run web server",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "conf",
+				Aliases:  []string{"c"},
+				Usage:    "config file(.json,.yaml,.toml)",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "model",
+				Aliases:  []string{"m"},
+				Usage:    "casbin model file(.conf)",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "menu",
+				Usage: "menu data file(.yaml) to initialize an empty menu table",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return app.Run(ctx,
+				app.SetConfigFile(c.String("conf")),
+				app.SetModelFile(c.String("model")),
+				app.SetMenuFile(c.String("menu")))
+		},
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Package main 离线策略测试工具
+
+使用模型文件和CSV策略文件(或已初始化数据的SQLite数据库)执行YAML策略断言:
+
+	policy-test -m ./configs/model.conf -p ./policy.csv ./internal/app/test/testdata
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gin-casbin/internal/app/module/policytest"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/urfave/cli/v2"
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "policy-test"
+	app.Usage = "Run policy assertion suites against model.conf and seeded policies"
+	app.ArgsUsage = "<suite.yaml|dir>..."
+	app.Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:    "model",
+			Aliases: []string{"m"},
+			Usage:   "casbin model file",
+			Value:   "./configs/model.conf",
+		},
+		&cli.StringFlag{
+			Name:    "policy",
+			Aliases: []string{"p"},
+			Usage:   "policy csv file(same format as policy export)",
+		},
+		&cli.StringFlag{
+			Name:  "sqlite",
+			Usage: "seeded sqlite3 database file",
+		},
+	}
+	app.Action = run
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+func run(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return cli.Exit("no policy suite specified", 2)
+	}
+
+	var (
+		e   *casbin.SyncedEnforcer
+		err error
+	)
+	switch {
+	case c.String("policy") != "":
+		e, err = policytest.NewCSVEnforcer(c.String("model"), c.String("policy"))
+	case c.String("sqlite") != "":
+		var cleanFunc func()
+		e, cleanFunc, err = policytest.NewSQLiteEnforcer(c.String("model"), c.String("sqlite"))
+		if err == nil {
+			defer cleanFunc()
+		}
+	default:
+		return cli.Exit("either --policy or --sqlite is required", 2)
+	}
+	if err != nil {
+		return err
+	}
+
+	suites, err := policytest.LoadPolicySuites(c.Args().Slice()...)
+	if err != nil {
+		return err
+	}
+
+	var total, failed int
+	for _, suite := range suites {
+		failures := policytest.RunPolicySuite(e, suite)
+		for _, f := range failures {
+			fmt.Println("FAIL", f.String())
+		}
+		total += len(suite.Cases)
+		failed += len(failures)
+	}
+
+	fmt.Printf("%d suites, %d cases, %d failed\n", len(suites), total, failed)
+	if failed > 0 {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"gin-casbin/internal/app/bll"
+	"gin-casbin/internal/app/ginplus"
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/wire"
+)
+
+// AccessReviewSet 注入AccessReview
+var AccessReviewSet = wire.NewSet(wire.Struct(new(AccessReview), "*"))
+
+// AccessReview 访问审查
+type AccessReview struct {
+	AccessReviewBll bll.IAccessReview
+}
+
+// Query
+func (a *AccessReview) Query(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.AccessReviewQueryParam
+	if err := ginplus.ParseQuery(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	params.Pagination = true
+	result, err := a.AccessReviewBll.Query(ctx, ginplus.GetTenantID(c), ginplus.GetUserID(c), params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResPage(c, result.Data, result.PageResult)
+}
+
+// Get
+func (a *AccessReview) Get(c *gin.Context) {
+	ctx := c.Request.Context()
+	item, err := a.AccessReviewBll.Get(ctx, ginplus.GetTenantID(c), ginplus.GetUserID(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, item)
+}
+
+// Create 创建访问审查(只有根租户可以操作)
+func (a *AccessReview) Create(c *gin.Context) {
+	ctx := c.Request.Context()
+	var item schema.AccessReview
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	} else if ginplus.GetTenantID(c) != schema.RootTenantID {
+		ginplus.ResError(c, errors.ErrNoPerm)
+		return
+	}
+
+	item.Creator = ginplus.GetUserID(c)
+	result, err := a.AccessReviewBll.Create(ctx, item)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, result)
+}
+
+// QueryItems
+func (a *AccessReview) QueryItems(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.AccessReviewItemQueryParam
+	if err := ginplus.ParseQuery(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	params.Pagination = true
+	result, err := a.AccessReviewBll.QueryItems(ctx, ginplus.GetTenantID(c), ginplus.GetUserID(c), c.Param("id"), params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResPage(c, result.Data, result.PageResult)
+}
+
+// ReviewItem
+func (a *AccessReview) ReviewItem(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.AccessReviewDecision
+	if err := ginplus.ParseJSON(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	err := a.AccessReviewBll.ReviewItem(ctx, ginplus.GetTenantID(c), c.Param("id"), ginplus.GetUserID(c), params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
+
+// Close 关闭访问审查(只有根租户可以操作)
+func (a *AccessReview) Close(c *gin.Context) {
+	ctx := c.Request.Context()
+	if ginplus.GetTenantID(c) != schema.RootTenantID {
+		ginplus.ResError(c, errors.ErrNoPerm)
+		return
+	}
+
+	err := a.AccessReviewBll.Close(ctx, c.Param("id"), ginplus.GetUserID(c))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
+
+// Export 导出审查结果(PDF)
+func (a *AccessReview) Export(c *gin.Context) {
+	ctx := c.Request.Context()
+	buf, err := a.AccessReviewBll.ExportPDF(ctx, ginplus.GetTenantID(c), ginplus.GetUserID(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="access-review-%s.pdf"`, c.Param("id")))
+	c.Data(http.StatusOK, "application/pdf", buf)
+	c.Abort()
+}
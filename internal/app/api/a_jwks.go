@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+
+	"gin-casbin/internal/app/ginplus"
+	"gin-casbin/pkg/auth"
+	"gin-casbin/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/wire"
+)
+
+// JWKSSet 注入JWKS
+var JWKSSet = wire.NewSet(wire.Struct(new(JWKS), "*"))
+
+// JWKS 令牌验证公钥
+type JWKS struct {
+	Auth auth.Auther
+}
+
+// Get 获取用于验证令牌的公钥集合(其他服务按令牌头中的kid选择公钥验证令牌)
+func (a *JWKS) Get(c *gin.Context) {
+	publisher, ok := a.Auth.(auth.KeyPublisher)
+	if !ok {
+		ginplus.ResError(c, errors.ErrNotFound)
+		return
+	}
+
+	buf, err := publisher.JWKS(c.Request.Context())
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Data(http.StatusOK, "application/json; charset=utf-8", buf)
+	c.Abort()
+}
@@ -85,7 +85,7 @@ func (a *Login) Login(c *gin.Context) {
 	ginplus.SetIsAdmin(c, isAdmin)
 
 	ctx = logger.NewUserIDContext(ctx, userID, tenantID)
-	tokenInfo, err := a.LoginBll.GenerateToken(ctx, userID, tenantID)
+	tokenInfo, err := a.LoginBll.GenerateToken(ctx, user, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
 		ginplus.ResError(c, err)
 		return
@@ -116,7 +116,7 @@ func (a *Login) GetAccessToken(c *gin.Context) {
 	ginplus.SetTenantID(c, tenantID)
 
 	ctx = logger.NewUserIDContext(ctx, userID, tenantID)
-	tokenInfo, err := a.LoginBll.GenerateToken(ctx, userID, tenantID)
+	tokenInfo, err := a.LoginBll.GenerateToken(ctx, user, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
 		ginplus.ResError(c, err)
 		return
@@ -139,10 +139,16 @@ func (a *Login) Logout(c *gin.Context) {
 	ginplus.ResOK(c)
 }
 
-// RefreshToken
+// RefreshToken 使用刷新令牌换取新的令牌(刷新令牌每次使用后轮换)
 func (a *Login) RefreshToken(c *gin.Context) {
 	ctx := c.Request.Context()
-	tokenInfo, err := a.LoginBll.GenerateToken(ctx, ginplus.GetUserID(c), ginplus.GetTenantID(c))
+	var params schema.RefreshTokenParam
+	if err := ginplus.ParseJSON(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	tokenInfo, err := a.LoginBll.RefreshToken(ctx, params.RefreshToken)
 	if err != nil {
 		ginplus.ResError(c, err)
 		return
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"gin-casbin/internal/app/bll"
+	"gin-casbin/internal/app/ginplus"
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/wire"
+)
+
+// MenuSet 注入Menu
+var MenuSet = wire.NewSet(wire.Struct(new(Menu), "*"))
+
+// Menu
+type Menu struct {
+	MenuBll bll.IMenu
+}
+
+// Query
+func (a *Menu) Query(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.MenuQueryParam
+	if err := ginplus.ParseQuery(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	params.Pagination = true
+	result, err := a.MenuBll.Query(ctx, params, schema.MenuQueryOptions{
+		OrderFields: schema.NewOrderFields(schema.NewOrderField("sequence", schema.OrderByDESC)),
+	})
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResPage(c, result.Data, result.PageResult)
+}
+
+// QueryTree
+func (a *Menu) QueryTree(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.MenuQueryParam
+	if err := ginplus.ParseQuery(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	result, err := a.MenuBll.Query(ctx, params, schema.MenuQueryOptions{
+		OrderFields: schema.NewOrderFields(schema.NewOrderField("sequence", schema.OrderByDESC)),
+	})
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResList(c, result.Data.ToTree())
+}
+
+// Get
+func (a *Menu) Get(c *gin.Context) {
+	ctx := c.Request.Context()
+	item, err := a.MenuBll.Get(ctx, c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, item)
+}
+
+// 菜单对所有租户生效，只允许根租户管理
+func (a *Menu) checkTenant(c *gin.Context) error {
+	if ginplus.GetTenantID(c) != schema.RootTenantID {
+		return errors.ErrNoPerm
+	}
+	return nil
+}
+
+// Create
+func (a *Menu) Create(c *gin.Context) {
+	ctx := c.Request.Context()
+	var item schema.Menu
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	} else if err := a.checkTenant(c); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	item.Creator = ginplus.GetUserID(c)
+	result, err := a.MenuBll.Create(ctx, item)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, result)
+}
+
+// Update
+func (a *Menu) Update(c *gin.Context) {
+	ctx := c.Request.Context()
+	var item schema.Menu
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	} else if err := a.checkTenant(c); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	err := a.MenuBll.Update(ctx, c.Param("id"), item)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
+
+// Delete
+func (a *Menu) Delete(c *gin.Context) {
+	ctx := c.Request.Context()
+	if err := a.checkTenant(c); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	err := a.MenuBll.Delete(ctx, c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
+
+// Enable
+func (a *Menu) Enable(c *gin.Context) {
+	ctx := c.Request.Context()
+	if err := a.checkTenant(c); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	err := a.MenuBll.UpdateStatus(ctx, c.Param("id"), 1)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
+
+// Disable
+func (a *Menu) Disable(c *gin.Context) {
+	ctx := c.Request.Context()
+	if err := a.checkTenant(c); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	err := a.MenuBll.UpdateStatus(ctx, c.Param("id"), 2)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"net/http"
+
+	"gin-casbin/internal/app/bll"
+	"gin-casbin/internal/app/ginplus"
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/wire"
+)
+
+// PolicySet 注入Policy
+var PolicySet = wire.NewSet(wire.Struct(new(Policy), "*"))
+
+// Policy 策略管理
+type Policy struct {
+	PolicyBll bll.IPolicy
+}
+
+// Explain 解释请求被允许或拒绝的原因
+func (a *Policy) Explain(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.PolicyExplainParam
+	if err := ginplus.ParseQuery(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	// 租户只能查询自己租户下的决策
+	if tenantID := ginplus.GetTenantID(c); tenantID != schema.RootTenantID || params.TenantID == "" {
+		params.TenantID = tenantID
+	}
+	item, err := a.PolicyBll.Explain(ctx, params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, item)
+}
+
+// GetDecision 查询策略决策记录
+func (a *Policy) GetDecision(c *gin.Context) {
+	ctx := c.Request.Context()
+	item, err := a.PolicyBll.GetDecision(ctx, c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	} else if tenantID := ginplus.GetTenantID(c); tenantID != schema.RootTenantID && item.TenantID != tenantID {
+		ginplus.ResError(c, errors.ErrNotFound)
+		return
+	}
+	ginplus.ResSuccess(c, item)
+}
+
+// CacheStats 查询策略决策缓存统计(只有根租户可以操作)
+func (a *Policy) CacheStats(c *gin.Context) {
+	ctx := c.Request.Context()
+	if ginplus.GetTenantID(c) != schema.RootTenantID {
+		ginplus.ResError(c, errors.ErrNoPerm)
+		return
+	}
+
+	item, err := a.PolicyBll.GetCacheStats(ctx)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, item)
+}
+
+// Permissions 查询用户或角色的有效权限
+func (a *Policy) Permissions(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.PermissionQueryParam
+	if err := ginplus.ParseQuery(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	// 租户只能查询自己租户下的权限
+	if tenantID := ginplus.GetTenantID(c); tenantID != schema.RootTenantID || params.TenantID == "" {
+		params.TenantID = tenantID
+	}
+	item, err := a.PolicyBll.QueryPermissions(ctx, params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, item)
+}
+
+// PermittedUsers 反向查询租户下可以执行请求的用户
+func (a *Policy) PermittedUsers(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.PermittedUserQueryParam
+	if err := ginplus.ParseQuery(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	if tenantID := ginplus.GetTenantID(c); tenantID != schema.RootTenantID || params.TenantID == "" {
+		params.TenantID = tenantID
+	}
+	result, err := a.PolicyBll.QueryPermittedUsers(ctx, params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResList(c, result)
+}
+
+// Export 导出策略(只有根租户可以操作)
+func (a *Policy) Export(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.PolicyExportParam
+	if err := ginplus.ParseQuery(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	} else if ginplus.GetTenantID(c) != schema.RootTenantID {
+		ginplus.ResError(c, errors.ErrNoPerm)
+		return
+	}
+
+	buf, err := a.PolicyBll.ExportPolicy(ctx, params.Format)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	contentType := "application/json; charset=utf-8"
+	if params.Format == schema.PolicyFormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	c.Data(http.StatusOK, contentType, buf)
+	c.Abort()
+}
+
+// Import 导入策略(只有根租户可以操作)
+func (a *Policy) Import(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.PolicyImportParam
+	if err := ginplus.ParseQuery(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	} else if ginplus.GetTenantID(c) != schema.RootTenantID {
+		ginplus.ResError(c, errors.ErrNoPerm)
+		return
+	}
+
+	data, err := c.GetRawData()
+	if err != nil {
+		ginplus.ResError(c, errors.Wrap400Response(err, "ErrBadRequest"))
+		return
+	}
+
+	result, err := a.PolicyBll.ImportPolicy(ctx, params, data)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, result)
+}
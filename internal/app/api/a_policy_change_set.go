@@ -0,0 +1,141 @@
+package api
+
+import (
+	"gin-casbin/internal/app/bll"
+	"gin-casbin/internal/app/ginplus"
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/wire"
+)
+
+// PolicyChangeSetSet 注入PolicyChangeSet
+var PolicyChangeSetSet = wire.NewSet(wire.Struct(new(PolicyChangeSet), "*"))
+
+// PolicyChangeSet 策略变更集(只有根租户可以操作)
+type PolicyChangeSet struct {
+	PolicyChangeSetBll bll.IPolicyChangeSet
+}
+
+// 检查是否根租户
+func (a *PolicyChangeSet) checkTenant(c *gin.Context) error {
+	if ginplus.GetTenantID(c) != schema.RootTenantID {
+		return errors.ErrNoPerm
+	}
+	return nil
+}
+
+// Query
+func (a *PolicyChangeSet) Query(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.PolicyChangeSetQueryParam
+	if err := ginplus.ParseQuery(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	} else if err := a.checkTenant(c); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	params.Pagination = true
+	result, err := a.PolicyChangeSetBll.Query(ctx, params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResPage(c, result.Data, result.PageResult)
+}
+
+// Get
+func (a *PolicyChangeSet) Get(c *gin.Context) {
+	ctx := c.Request.Context()
+	if err := a.checkTenant(c); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	item, err := a.PolicyChangeSetBll.Get(ctx, c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, item)
+}
+
+// Create
+func (a *PolicyChangeSet) Create(c *gin.Context) {
+	ctx := c.Request.Context()
+	var item schema.PolicyChangeSet
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	} else if err := a.checkTenant(c); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	item.Creator = ginplus.GetUserID(c)
+	result, err := a.PolicyChangeSetBll.Create(ctx, item)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, result)
+}
+
+// Preview
+func (a *PolicyChangeSet) Preview(c *gin.Context) {
+	ctx := c.Request.Context()
+	if err := a.checkTenant(c); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	result, err := a.PolicyChangeSetBll.Preview(ctx, c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, result)
+}
+
+// Approve
+func (a *PolicyChangeSet) Approve(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.PolicyChangeSetReview
+	if err := ginplus.ParseJSON(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	} else if err := a.checkTenant(c); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	err := a.PolicyChangeSetBll.Approve(ctx, c.Param("id"), ginplus.GetUserID(c), params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
+
+// Reject
+func (a *PolicyChangeSet) Reject(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.PolicyChangeSetReview
+	if err := ginplus.ParseJSON(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	} else if err := a.checkTenant(c); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	err := a.PolicyChangeSetBll.Reject(ctx, c.Param("id"), ginplus.GetUserID(c), params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
@@ -4,6 +4,7 @@ import (
 	"gin-casbin/internal/app/bll"
 	"gin-casbin/internal/app/ginplus"
 	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
@@ -47,6 +48,7 @@ func (a *Role) QuerySelect(c *gin.Context) {
 		return
 	}
 
+	params.TenantID = ginplus.GetTenantID(c)
 	result, err := a.RoleBll.Query(ctx, params, schema.RoleQueryOptions{
 		OrderFields: schema.NewOrderFields(schema.NewOrderField("sequence", schema.OrderByDESC)),
 	})
@@ -64,10 +66,31 @@ func (a *Role) Get(c *gin.Context) {
 	if err != nil {
 		ginplus.ResError(c, err)
 		return
+	} else if !item.IsVisibleTo(ginplus.GetTenantID(c)) {
+		ginplus.ResError(c, errors.ErrNotFound)
+		return
 	}
 	ginplus.ResSuccess(c, item)
 }
 
+// 检查当前租户是否可以管理角色(租户只能管理自己的自定义角色)
+func (a *Role) checkTenant(c *gin.Context, id string) error {
+	tenantID := ginplus.GetTenantID(c)
+	if tenantID == schema.RootTenantID {
+		return nil
+	}
+
+	item, err := a.RoleBll.Get(c.Request.Context(), id)
+	if err != nil {
+		return err
+	} else if !item.IsVisibleTo(tenantID) {
+		return errors.ErrNotFound
+	} else if item.TenantID != tenantID {
+		return errors.ErrNoPerm
+	}
+	return nil
+}
+
 // Create
 func (a *Role) Create(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -78,6 +101,9 @@ func (a *Role) Create(c *gin.Context) {
 	}
 
 	item.Creator = ginplus.GetUserID(c)
+	if tenantID := ginplus.GetTenantID(c); tenantID != schema.RootTenantID {
+		item.TenantID = tenantID
+	}
 	result, err := a.RoleBll.Create(ctx, item)
 	if err != nil {
 		ginplus.ResError(c, err)
@@ -93,6 +119,9 @@ func (a *Role) Update(c *gin.Context) {
 	if err := ginplus.ParseJSON(c, &item); err != nil {
 		ginplus.ResError(c, err)
 		return
+	} else if err := a.checkTenant(c, c.Param("id")); err != nil {
+		ginplus.ResError(c, err)
+		return
 	}
 
 	err := a.RoleBll.Update(ctx, c.Param("id"), item)
@@ -106,6 +135,11 @@ func (a *Role) Update(c *gin.Context) {
 // Delete
 func (a *Role) Delete(c *gin.Context) {
 	ctx := c.Request.Context()
+	if err := a.checkTenant(c, c.Param("id")); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
 	err := a.RoleBll.Delete(ctx, c.Param("id"))
 	if err != nil {
 		ginplus.ResError(c, err)
@@ -117,6 +151,11 @@ func (a *Role) Delete(c *gin.Context) {
 // Enable
 func (a *Role) Enable(c *gin.Context) {
 	ctx := c.Request.Context()
+	if err := a.checkTenant(c, c.Param("id")); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
 	err := a.RoleBll.UpdateStatus(ctx, c.Param("id"), 1)
 	if err != nil {
 		ginplus.ResError(c, err)
@@ -128,6 +167,11 @@ func (a *Role) Enable(c *gin.Context) {
 // Disable
 func (a *Role) Disable(c *gin.Context) {
 	ctx := c.Request.Context()
+	if err := a.checkTenant(c, c.Param("id")); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
 	err := a.RoleBll.UpdateStatus(ctx, c.Param("id"), 2)
 	if err != nil {
 		ginplus.ResError(c, err)
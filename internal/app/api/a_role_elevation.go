@@ -0,0 +1,116 @@
+package api
+
+import (
+	"gin-casbin/internal/app/bll"
+	"gin-casbin/internal/app/ginplus"
+	"gin-casbin/internal/app/schema"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/wire"
+)
+
+// RoleElevationSet 注入RoleElevation
+var RoleElevationSet = wire.NewSet(wire.Struct(new(RoleElevation), "*"))
+
+// RoleElevation 临时提权
+type RoleElevation struct {
+	RoleElevationBll bll.IRoleElevation
+}
+
+// Query
+func (a *RoleElevation) Query(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.RoleElevationQueryParam
+	if err := ginplus.ParseQuery(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	params.Pagination = true
+	result, err := a.RoleElevationBll.Query(ctx, ginplus.GetTenantID(c), ginplus.GetUserID(c), params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResPage(c, result.Data, result.PageResult)
+}
+
+// Get
+func (a *RoleElevation) Get(c *gin.Context) {
+	ctx := c.Request.Context()
+	item, err := a.RoleElevationBll.Get(ctx, ginplus.GetTenantID(c), ginplus.GetUserID(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, item)
+}
+
+// Create
+func (a *RoleElevation) Create(c *gin.Context) {
+	ctx := c.Request.Context()
+	var item schema.RoleElevation
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	item.UserID = ginplus.GetUserID(c)
+	result, err := a.RoleElevationBll.Create(ctx, item)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, result)
+}
+
+// Approve
+func (a *RoleElevation) Approve(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.RoleElevationReview
+	if err := ginplus.ParseJSON(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	err := a.RoleElevationBll.Approve(ctx, ginplus.GetTenantID(c), c.Param("id"), ginplus.GetUserID(c), params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
+
+// Deny
+func (a *RoleElevation) Deny(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.RoleElevationReview
+	if err := ginplus.ParseJSON(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	err := a.RoleElevationBll.Deny(ctx, ginplus.GetTenantID(c), c.Param("id"), ginplus.GetUserID(c), params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
+
+// Revoke
+func (a *RoleElevation) Revoke(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.RoleElevationReview
+	if err := ginplus.ParseJSON(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	err := a.RoleElevationBll.Revoke(ctx, ginplus.GetTenantID(c), c.Param("id"), ginplus.GetUserID(c), params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
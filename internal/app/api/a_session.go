@@ -0,0 +1,57 @@
+package api
+
+import (
+	"gin-casbin/internal/app/bll"
+	"gin-casbin/internal/app/ginplus"
+	"gin-casbin/internal/app/schema"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/wire"
+)
+
+// SessionSet 注入Session
+var SessionSet = wire.NewSet(wire.Struct(new(Session), "*"))
+
+// Session 登录会话
+type Session struct {
+	SessionBll bll.ISession
+}
+
+// QueryMine 查询当前用户的登录会话
+func (a *Session) QueryMine(c *gin.Context) {
+	ctx := c.Request.Context()
+	items, err := a.SessionBll.QueryMine(ctx, ginplus.GetUserID(c), ginplus.GetToken(c))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResList(c, items)
+}
+
+// Query 查询租户下用户的登录会话
+func (a *Session) Query(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.SessionQueryParam
+	if err := ginplus.ParseQuery(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	items, err := a.SessionBll.QueryTenant(ctx, ginplus.GetTenantID(c), ginplus.GetUserID(c), params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResList(c, items)
+}
+
+// Terminate 终止会话
+func (a *Session) Terminate(c *gin.Context) {
+	ctx := c.Request.Context()
+	err := a.SessionBll.Terminate(ctx, ginplus.GetTenantID(c), ginplus.GetUserID(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
@@ -206,3 +206,20 @@ func (a *User) Disable(c *gin.Context) {
 	}
 	ginplus.ResOK(c)
 }
+
+// QueryRoleGrants 查询有有效期的角色授权(即将生效、有效和已失效)
+func (a *User) QueryRoleGrants(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params schema.UserRoleGrantQueryParam
+	if err := ginplus.ParseQuery(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	result, err := a.UserBll.QueryRoleGrants(ctx, ginplus.GetTenantID(c), params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResList(c, result)
+}
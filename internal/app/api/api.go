@@ -5,8 +5,15 @@ import "github.com/google/wire"
 // APISet 注入api
 var APISet = wire.NewSet(
 	LoginSet,
+	MenuSet,
 	RoleSet,
 	UserSet,
 	TenantSet,
 	ResourceSet,
+	PolicySet,
+	PolicyChangeSetSet,
+	RoleElevationSet,
+	AccessReviewSet,
+	JWKSSet,
+	SessionSet,
 )
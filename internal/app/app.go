@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"gin-casbin/internal/app/config"
+	"gin-casbin/internal/app/injector"
 	"gin-casbin/pkg/logger"
 
 	_ "gin-casbin/internal/app/swagger"
@@ -17,11 +18,19 @@ import (
 type options struct {
 	ConfigFile string
 	ModelFile  string
+	MenuFile   string
 }
 
 // Option
 type Option func(*options)
 
+// SetConfigFile set config file
+func SetConfigFile(s string) Option {
+	return func(o *options) {
+		o.ConfigFile = s
+	}
+}
+
 // SetModelFile set casbin model
 func SetModelFile(s string) Option {
 	return func(o *options) {
@@ -29,6 +38,13 @@ func SetModelFile(s string) Option {
 	}
 }
 
+// SetMenuFile set menu data file
+func SetMenuFile(s string) Option {
+	return func(o *options) {
+		o.MenuFile = s
+	}
+}
+
 // Init
 func Init(ctx context.Context, opts ...Option) (func(), error) {
 	var o options
@@ -40,7 +56,26 @@ func Init(ctx context.Context, opts ...Option) (func(), error) {
 	if v := o.ModelFile; v != "" {
 		config.C.Casbin.Model = v
 	}
+	if v := o.MenuFile; v != "" {
+		config.C.Menu.Data = v
+	}
+
+	appInjector, injectorCleanFunc, err := injector.BuildInjector()
+	if err != nil {
+		return nil, err
+	}
+
+	// 初始化菜单数据
+	if config.C.Menu.Enable && config.C.Menu.Data != "" {
+		err := appInjector.MenuBll.InitData(ctx, config.C.Menu.Data)
+		if err != nil {
+			injectorCleanFunc()
+			return nil, err
+		}
+	}
+
 	return func() {
+		injectorCleanFunc()
 	}, nil
 }
 
@@ -63,6 +98,8 @@ EXIT:
 			atomic.CompareAndSwapInt32(&state, 1, 0)
 			break EXIT
 		case syscall.SIGHUP:
+			// 重新加载casbin模型，失败时保留原模型继续运行
+			_ = injector.ReloadCasbinModel(ctx)
 		default:
 			break EXIT
 		}
@@ -0,0 +1,25 @@
+package bll
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/schema"
+)
+
+// IAccessReview 访问审查业务逻辑接口
+type IAccessReview interface {
+	// 查询数据(根租户用户或租户管理员)
+	Query(ctx context.Context, tenantID, userID string, params schema.AccessReviewQueryParam, opts ...schema.AccessReviewQueryOptions) (*schema.AccessReviewQueryResult, error)
+	// 查询指定数据(根租户用户或租户管理员)
+	Get(ctx context.Context, tenantID, userID, id string, opts ...schema.AccessReviewQueryOptions) (*schema.AccessReview, error)
+	// 创建访问审查并快照所有租户的用户角色授权
+	Create(ctx context.Context, item schema.AccessReview) (*schema.IDResult, error)
+	// 查询审查项(根租户用户或租户管理员，非根租户只查询本租户的审查项)
+	QueryItems(ctx context.Context, tenantID, userID, id string, params schema.AccessReviewItemQueryParam, opts ...schema.AccessReviewItemQueryOptions) (*schema.AccessReviewItemQueryResult, error)
+	// 审查授权(保留或撤销)
+	ReviewItem(ctx context.Context, tenantID, itemID, reviewer string, params schema.AccessReviewDecision) error
+	// 关闭访问审查并撤销标记为撤销的授权
+	Close(ctx context.Context, id, closer string) error
+	// 导出审查结果(PDF，根租户用户或租户管理员)
+	ExportPDF(ctx context.Context, tenantID, userID, id string) ([]byte, error)
+}
@@ -15,8 +15,10 @@ type ILogin interface {
 	ResCaptcha(ctx context.Context, w http.ResponseWriter, captchaID string, width, height int) error
 	// 登录验证
 	Verify(ctx context.Context, userName, password string, referer string) (*schema.User, error)
-	// 生成令牌
-	GenerateToken(ctx context.Context, userID string, tenantID string) (*schema.LoginTokenInfo, error)
+	// 生成令牌并登记登录会话
+	GenerateToken(ctx context.Context, user *schema.User, userAgent, ip string) (*schema.LoginTokenInfo, error)
+	// 使用刷新令牌生成新的令牌
+	RefreshToken(ctx context.Context, refreshToken string) (*schema.LoginTokenInfo, error)
 	// 销毁令牌
 	DestroyToken(ctx context.Context, tokenString string) error
 	// 获取用户登录信息
@@ -0,0 +1,29 @@
+package bll
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/schema"
+)
+
+// IPolicy 策略管理业务逻辑接口
+type IPolicy interface {
+	// 解释请求被允许或拒绝的原因
+	Explain(ctx context.Context, params schema.PolicyExplainParam) (*schema.PolicyDecision, error)
+	// 加载策略条件使用的属性
+	LoadAttributes(ctx context.Context, params schema.PolicyAttributeParam) (map[string]interface{}, error)
+	// 解释并记录策略决策
+	RecordDecision(ctx context.Context, params schema.PolicyExplainParam) (*schema.PolicyDecision, error)
+	// 查询指定的策略决策
+	GetDecision(ctx context.Context, id string) (*schema.PolicyDecision, error)
+	// 查询策略决策缓存统计
+	GetCacheStats(ctx context.Context) (*schema.DecisionCacheStats, error)
+	// 查询用户或角色的有效权限
+	QueryPermissions(ctx context.Context, params schema.PermissionQueryParam) (*schema.PermissionReport, error)
+	// 反向查询租户下可以执行请求的用户
+	QueryPermittedUsers(ctx context.Context, params schema.PermittedUserQueryParam) ([]*schema.PermittedUser, error)
+	// 导出策略(csv/json)
+	ExportPolicy(ctx context.Context, format string) ([]byte, error)
+	// 导入策略(csv/json)，预演时只返回差异
+	ImportPolicy(ctx context.Context, params schema.PolicyImportParam, data []byte) (*schema.PolicyImportResult, error)
+}
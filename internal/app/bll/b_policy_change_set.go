@@ -0,0 +1,23 @@
+package bll
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/schema"
+)
+
+// IPolicyChangeSet 策略变更集业务逻辑接口
+type IPolicyChangeSet interface {
+	// 查询数据
+	Query(ctx context.Context, params schema.PolicyChangeSetQueryParam, opts ...schema.PolicyChangeSetQueryOptions) (*schema.PolicyChangeSetQueryResult, error)
+	// 查询指定数据
+	Get(ctx context.Context, id string, opts ...schema.PolicyChangeSetQueryOptions) (*schema.PolicyChangeSet, error)
+	// 创建待审核的变更集
+	Create(ctx context.Context, item schema.PolicyChangeSet) (*schema.IDResult, error)
+	// 预览变更集相对当前策略的差异及用户访问权限变化
+	Preview(ctx context.Context, id string) (*schema.PolicyChangeSetPreview, error)
+	// 批准并提交变更集
+	Approve(ctx context.Context, id, reviewer string, params schema.PolicyChangeSetReview) error
+	// 拒绝变更集
+	Reject(ctx context.Context, id, reviewer string, params schema.PolicyChangeSetReview) error
+}
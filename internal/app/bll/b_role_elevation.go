@@ -0,0 +1,23 @@
+package bll
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/schema"
+)
+
+// IRoleElevation 临时提权业务逻辑接口
+type IRoleElevation interface {
+	// 查询数据(租户管理员查询租户下所有申请，其他用户只查询自己的申请)
+	Query(ctx context.Context, tenantID, userID string, params schema.RoleElevationQueryParam, opts ...schema.RoleElevationQueryOptions) (*schema.RoleElevationQueryResult, error)
+	// 查询指定数据(租户管理员可以查询租户下所有申请，其他用户只能查询自己的申请)
+	Get(ctx context.Context, tenantID, userID, id string, opts ...schema.RoleElevationQueryOptions) (*schema.RoleElevation, error)
+	// 创建待审批的申请
+	Create(ctx context.Context, item schema.RoleElevation) (*schema.IDResult, error)
+	// 批准申请并授予有有效期的角色
+	Approve(ctx context.Context, tenantID, id, reviewer string, params schema.RoleElevationReview) error
+	// 拒绝申请
+	Deny(ctx context.Context, tenantID, id, reviewer string, params schema.RoleElevationReview) error
+	// 提前撤销已批准的授权
+	Revoke(ctx context.Context, tenantID, id, revoker string, params schema.RoleElevationReview) error
+}
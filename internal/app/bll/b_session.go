@@ -0,0 +1,17 @@
+package bll
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/schema"
+)
+
+// ISession 登录会话业务逻辑接口
+type ISession interface {
+	// 查询用户自己的登录会话(标记当前令牌所属的会话)
+	QueryMine(ctx context.Context, userID, token string) (schema.Sessions, error)
+	// 查询租户下用户的登录会话(租户管理员或根租户用户)
+	QueryTenant(ctx context.Context, tenantID, userID string, params schema.SessionQueryParam) (schema.Sessions, error)
+	// 终止会话(会话所属用户、会话所属租户的管理员或根租户用户)
+	Terminate(ctx context.Context, tenantID, userID, id string) error
+}
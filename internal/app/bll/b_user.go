@@ -24,4 +24,6 @@ type IUser interface {
 	Delete(ctx context.Context, id string) error
 	// 更新状态
 	UpdateStatus(ctx context.Context, id string, status int) error
+	// 查询有有效期的角色授权
+	QueryRoleGrants(ctx context.Context, tenantID string, params schema.UserRoleGrantQueryParam) ([]*schema.UserRoleGrant, error)
 }
@@ -0,0 +1,424 @@
+package bll
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin-casbin/internal/app/bll"
+	"gin-casbin/internal/app/icontext"
+	"gin-casbin/internal/app/iutil"
+	"gin-casbin/internal/app/model"
+	"gin-casbin/internal/app/module/adapter"
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/google/wire"
+	"github.com/johnfercher/maroto/pkg/consts"
+	"github.com/johnfercher/maroto/pkg/pdf"
+	"github.com/johnfercher/maroto/pkg/props"
+)
+
+var _ bll.IAccessReview = (*AccessReview)(nil)
+
+// AccessReviewSet 注入AccessReview
+var AccessReviewSet = wire.NewSet(wire.Struct(new(AccessReview), "*"), wire.Bind(new(bll.IAccessReview), new(*AccessReview)))
+
+// AccessReview 访问审查(定期快照用户角色授权，审查者逐条确认保留或撤销，关闭时执行撤销)
+type AccessReview struct {
+	Enforcer                 *casbin.SyncedEnforcer
+	TransModel               model.ITrans
+	UserModel                model.IUser
+	UserRoleModel            model.IUserRole
+	RoleModel                model.IRole
+	TenantAdministratorModel model.ITenantAdministrator
+	AccessReviewModel        model.IAccessReview
+	AccessReviewItemModel    model.IAccessReviewItem
+}
+
+// Query 查询数据(根租户用户或租户管理员)
+func (a *AccessReview) Query(ctx context.Context, tenantID, userID string, params schema.AccessReviewQueryParam, opts ...schema.AccessReviewQueryOptions) (*schema.AccessReviewQueryResult, error) {
+	err := a.checkReviewer(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return a.AccessReviewModel.Query(ctx, params, opts...)
+}
+
+// Get 查询指定数据(根租户用户或租户管理员)
+func (a *AccessReview) Get(ctx context.Context, tenantID, userID, id string, opts ...schema.AccessReviewQueryOptions) (*schema.AccessReview, error) {
+	err := a.checkReviewer(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return a.get(ctx, id, opts...)
+}
+
+// 查询指定数据(不检查查询者)
+func (a *AccessReview) get(ctx context.Context, id string, opts ...schema.AccessReviewQueryOptions) (*schema.AccessReview, error) {
+	item, err := a.AccessReviewModel.Get(ctx, id, opts...)
+	if err != nil {
+		return nil, err
+	} else if item == nil {
+		return nil, errors.ErrNotFound
+	}
+	return item, nil
+}
+
+// Create 创建访问审查并快照所有租户的用户角色授权(已失效的授权不参与审查)
+func (a *AccessReview) Create(ctx context.Context, item schema.AccessReview) (*schema.IDResult, error) {
+	items, err := a.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	item.ID = iutil.NewID()
+	item.Status = schema.AccessReviewOpen
+	item.ItemCount = len(items)
+	item.KeepCount = 0
+	item.RevokeCount = 0
+	item.ClosedBy = ""
+	item.ClosedAt = nil
+	err = ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		for _, ritem := range items {
+			ritem.ID = iutil.NewID()
+			ritem.ReviewID = item.ID
+			err := a.AccessReviewItemModel.Create(ctx, *ritem)
+			if err != nil {
+				return err
+			}
+		}
+		return a.AccessReviewModel.Create(ctx, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schema.NewIDResult(item.ID), nil
+}
+
+// 快照所有租户的用户角色授权
+func (a *AccessReview) snapshot(ctx context.Context) (schema.AccessReviewItems, error) {
+	userResult, err := a.UserModel.Query(ctx, schema.UserQueryParam{})
+	if err != nil {
+		return nil, err
+	} else if len(userResult.Data) == 0 {
+		return nil, nil
+	}
+
+	userRoleResult, err := a.UserRoleModel.Query(ctx, schema.UserRoleQueryParam{
+		UserIDs: userResult.Data.ToIDs(),
+	})
+	if err != nil {
+		return nil, err
+	} else if len(userRoleResult.Data) == 0 {
+		return nil, nil
+	}
+
+	roleResult, err := a.RoleModel.Query(ctx, schema.RoleQueryParam{
+		IDs: userRoleResult.Data.ToRoleIDs(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	mRoles := roleResult.Data.ToMap()
+	mUserRoles := userRoleResult.Data.ToUserIDMap()
+
+	now := time.Now()
+	var items schema.AccessReviewItems
+	for _, user := range userResult.Data {
+		for _, ur := range mUserRoles[user.ID] {
+			if ur.GetStatus(now) == schema.UserRoleExpired {
+				continue
+			}
+
+			ritem := &schema.AccessReviewItem{
+				TenantID:   user.TenantID,
+				UserID:     user.ID,
+				UserName:   user.UserName,
+				RoleID:     ur.RoleID,
+				UserRoleID: ur.ID,
+				ValidFrom:  ur.ValidFrom,
+				ValidUntil: ur.ValidUntil,
+				Decision:   schema.ReviewDecisionPending,
+			}
+			if role, ok := mRoles[ur.RoleID]; ok {
+				ritem.RoleName = role.Name
+			}
+			items = append(items, ritem)
+		}
+	}
+	return items, nil
+}
+
+// QueryItems 查询审查项(查询者是根租户用户或租户管理员，非根租户只查询本租户的审查项)
+func (a *AccessReview) QueryItems(ctx context.Context, tenantID, userID, id string, params schema.AccessReviewItemQueryParam, opts ...schema.AccessReviewItemQueryOptions) (*schema.AccessReviewItemQueryResult, error) {
+	err := a.checkReviewer(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = a.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return a.queryItems(ctx, tenantID, id, params, opts...)
+}
+
+// 检查用户是否可以查看租户的审查项(根租户用户或租户管理员)
+func (a *AccessReview) checkReviewer(ctx context.Context, tenantID, userID string) error {
+	if tenantID == schema.RootTenantID {
+		return nil
+	}
+
+	isAdmin, err := isTenantAdmin(ctx, a.TenantAdministratorModel, a.UserRoleModel, a.RoleModel, tenantID, userID)
+	if err != nil {
+		return err
+	} else if !isAdmin {
+		return errors.ErrNoPerm
+	}
+	return nil
+}
+
+// 查询审查项(非根租户只查询本租户的审查项)
+func (a *AccessReview) queryItems(ctx context.Context, tenantID, id string, params schema.AccessReviewItemQueryParam, opts ...schema.AccessReviewItemQueryOptions) (*schema.AccessReviewItemQueryResult, error) {
+	params.ReviewID = id
+	if tenantID != schema.RootTenantID {
+		params.TenantID = tenantID
+	}
+	return a.AccessReviewItemModel.Query(ctx, params, opts...)
+}
+
+// ReviewItem 审查授权(访问审查进行中，审查者是根租户用户或审查项所属租户的管理员，且不能审查自己的授权)
+func (a *AccessReview) ReviewItem(ctx context.Context, tenantID, itemID, reviewer string, params schema.AccessReviewDecision) error {
+	return ExecTrans(icontext.NewTransLock(ctx), a.TransModel, func(ctx context.Context) error {
+		item, err := a.AccessReviewItemModel.Get(ctx, itemID)
+		if err != nil {
+			return err
+		} else if item == nil || (tenantID != schema.RootTenantID && item.TenantID != tenantID) {
+			return errors.ErrNotFound
+		} else if item.UserID == reviewer {
+			return errors.New400Response("ErrAccessReviewSelfReview")
+		}
+
+		review, err := a.get(ctx, item.ReviewID)
+		if err != nil {
+			return err
+		} else if review.Status != schema.AccessReviewOpen {
+			return errors.New400Response("ErrAccessReviewClosed")
+		}
+
+		err = a.checkReviewer(ctx, tenantID, reviewer)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		item.Decision = params.Decision
+		item.Reviewer = reviewer
+		item.Comment = params.Comment
+		item.ReviewedAt = &now
+		return a.AccessReviewItemModel.Update(ctx, item.ID, *item)
+	})
+}
+
+// Close 关闭访问审查并撤销标记为撤销的授权(待审查的授权保持不变)
+func (a *AccessReview) Close(ctx context.Context, id, closer string) error {
+	var revokeRules [][]string
+	err := ExecTrans(icontext.NewTransLock(ctx), a.TransModel, func(ctx context.Context) error {
+		review, err := a.get(ctx, id)
+		if err != nil {
+			return err
+		} else if review.Status != schema.AccessReviewOpen {
+			return errors.New400Response("ErrAccessReviewClosed")
+		}
+
+		itemResult, err := a.AccessReviewItemModel.Query(ctx, schema.AccessReviewItemQueryParam{
+			ReviewID: id,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, item := range itemResult.Data {
+			if item.Decision != schema.ReviewDecisionRevoke {
+				continue
+			}
+
+			// 快照后已删除的授权不再撤销
+			ur, err := a.UserRoleModel.Get(ctx, item.UserRoleID)
+			if err != nil {
+				return err
+			} else if ur == nil {
+				continue
+			}
+
+			err = a.UserRoleModel.Delete(ctx, ur.ID)
+			if err != nil {
+				return err
+			}
+
+			item.Applied = true
+			err = a.AccessReviewItemModel.Update(ctx, item.ID, *item)
+			if err != nil {
+				return err
+			}
+			revokeRules = append(revokeRules, adapter.NewUserRule(item.TenantID, item.UserID, item.RoleID))
+		}
+
+		now := time.Now()
+		review.Status = schema.AccessReviewClosed
+		review.KeepCount = itemResult.Data.CountDecision(schema.ReviewDecisionKeep)
+		review.RevokeCount = itemResult.Data.CountDecision(schema.ReviewDecisionRevoke)
+		review.ClosedBy = closer
+		review.ClosedAt = &now
+		return a.AccessReviewModel.Update(ctx, review.ID, *review)
+	})
+	if err != nil {
+		return err
+	}
+
+	ApplyCasbinPolicy(ctx, a.Enforcer, adapter.NewPolicyDelta(nil, nil, revokeRules, nil))
+	return nil
+}
+
+// ExportPDF 导出审查结果(导出者是根租户用户或租户管理员，非根租户只导出本租户的审查项)
+func (a *AccessReview) ExportPDF(ctx context.Context, tenantID, userID, id string) ([]byte, error) {
+	err := a.checkReviewer(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	review, err := a.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	itemResult, err := a.queryItems(ctx, tenantID, id, schema.AccessReviewItemQueryParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	mUserNames := make(map[string]string)
+	for _, userID := range []string{review.Creator, review.ClosedBy} {
+		mUserNames[userID] = ""
+	}
+	for _, item := range itemResult.Data {
+		mUserNames[item.Reviewer] = ""
+	}
+	for userID := range mUserNames {
+		if userID == "" {
+			continue
+		}
+		user, err := a.UserModel.Get(ctx, userID)
+		if err != nil {
+			return nil, err
+		} else if user != nil {
+			mUserNames[userID] = user.UserName
+		}
+	}
+
+	return renderAccessReviewPDF(review, itemResult.Data, mUserNames)
+}
+
+const accessReviewTimeLayout = "2006-01-02 15:04"
+
+// 生成访问审查报告
+func renderAccessReviewPDF(review *schema.AccessReview, items schema.AccessReviewItems, mUserNames map[string]string) ([]byte, error) {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.Format(accessReviewTimeLayout)
+	}
+
+	status := "Open"
+	if review.Status == schema.AccessReviewClosed {
+		status = "Closed"
+	}
+	summary := [][]string{
+		{"Campaign", review.Name},
+		{"Status", status},
+		{"Created", fmt.Sprintf("%s by %s", review.CreatedAt.Format(accessReviewTimeLayout), mUserNames[review.Creator])},
+		{"Due", formatTime(review.DueAt)},
+		{"Closed", formatTime(review.ClosedAt)},
+		{"Assignments", fmt.Sprintf("%d (keep %d, revoke %d, pending %d)", len(items),
+			items.CountDecision(schema.ReviewDecisionKeep),
+			items.CountDecision(schema.ReviewDecisionRevoke),
+			items.CountDecision(schema.ReviewDecisionPending))},
+	}
+
+	contents := make([][]string, len(items))
+	for i, item := range items {
+		decision := "Pending"
+		switch item.Decision {
+		case schema.ReviewDecisionKeep:
+			decision = "Keep"
+		case schema.ReviewDecisionRevoke:
+			decision = "Revoke"
+			if item.Applied {
+				decision = "Revoked"
+			}
+		}
+		contents[i] = []string{
+			item.TenantID,
+			item.UserName,
+			item.RoleName,
+			decision,
+			mUserNames[item.Reviewer],
+			formatTime(item.ReviewedAt),
+			item.Comment,
+		}
+	}
+
+	m := pdf.NewMaroto(consts.Landscape, consts.A4)
+	m.SetPageMargins(10, 15, 10)
+	m.Row(12, func() {
+		m.Col(12, func() {
+			m.Text("Access Review Report", props.Text{
+				Size:  16,
+				Style: consts.Bold,
+				Align: consts.Center,
+			})
+		})
+	})
+	for _, line := range summary {
+		line := line
+		m.Row(6, func() {
+			m.Col(2, func() {
+				m.Text(line[0], props.Text{Size: 9, Style: consts.Bold})
+			})
+			m.Col(10, func() {
+				m.Text(line[1], props.Text{Size: 9})
+			})
+		})
+	}
+	if review.Description != "" {
+		m.Row(10, func() {
+			m.Col(12, func() {
+				m.Text(review.Description, props.Text{Size: 9, Top: 2})
+			})
+		})
+	}
+	m.Row(6, func() {})
+
+	gridSizes := []uint{2, 2, 2, 1, 2, 2, 1}
+	m.TableList([]string{"Tenant", "User", "Role", "Decision", "Reviewer", "Reviewed At", "Comment"}, contents, props.TableList{
+		HeaderProp: props.TableListContent{
+			Size:      9,
+			GridSizes: gridSizes,
+		},
+		ContentProp: props.TableListContent{
+			Size:      8,
+			GridSizes: gridSizes,
+		},
+		Align:              consts.Left,
+		HeaderContentSpace: 1,
+	})
+
+	buf, err := m.Output()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), nil
+}
@@ -2,8 +2,11 @@ package bll
 
 import (
 	"context"
+	"time"
 
 	"gin-casbin/internal/app/config"
+	"gin-casbin/internal/app/module/adapter"
+	"gin-casbin/internal/app/schema"
 	"gin-casbin/pkg/logger"
 
 	"github.com/casbin/casbin/v2"
@@ -20,10 +23,12 @@ func init() {
 	chCasbinPolicy = make(chan *chCasbinPolicyItem, 1)
 	go func() {
 		for item := range chCasbinPolicy {
-			err := item.e.LoadPolicy()
+			err := adapter.ReloadPolicy(item.e)
 			if err != nil {
 				logger.Errorf(item.ctx, "The load casbin policy error: %s", err.Error())
+				continue
 			}
+			adapter.NotifyReload(item.e)
 		}
 	}()
 }
@@ -44,3 +49,29 @@ func LoadCasbinPolicy(ctx context.Context, e *casbin.SyncedEnforcer) {
 		e:   e,
 	}
 }
+
+// ApplyCasbinPolicy 增量应用策略变更，失败时回退为全量加载
+func ApplyCasbinPolicy(ctx context.Context, e *casbin.SyncedEnforcer, delta *adapter.PolicyDelta) {
+	if !config.C.Casbin.Enable || delta.IsEmpty() {
+		return
+	}
+
+	err := adapter.ApplyPolicyDelta(e, delta)
+	if err != nil {
+		logger.Errorf(ctx, "The apply casbin policy error: %s", err.Error())
+		LoadCasbinPolicy(ctx, e)
+	}
+}
+
+// 生成用户的策略规则(只有启用状态的用户和当前有效的授权才有策略)
+func newUserPolicies(tenantID, userID string, status int, userRoles schema.UserRoles) [][]string {
+	if status != 1 {
+		return nil
+	}
+
+	var rules [][]string
+	for _, roleID := range userRoles.ValidAt(time.Now()).ToRoleIDs() {
+		rules = append(rules, adapter.NewUserRule(tenantID, userID, roleID))
+	}
+	return rules
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"time"
 
 	"gin-casbin/internal/app/bll"
 	"gin-casbin/internal/app/config"
@@ -13,6 +14,7 @@ import (
 	"gin-casbin/internal/app/schema"
 	"gin-casbin/pkg/auth"
 	"gin-casbin/pkg/errors"
+	"gin-casbin/pkg/logger"
 	"gin-casbin/pkg/mail"
 	"gin-casbin/pkg/util"
 
@@ -35,6 +37,7 @@ type Login struct {
 	TenantModel     model.ITenant
 	RoleModel       model.IRole
 	RoleMenuModel   model.IRoleMenu
+	RoleParentModel model.IRoleParent
 	MenuModel       model.IMenu
 	MenuActionModel model.IMenuAction
 	Mailer          *mail.Mailer
@@ -68,11 +71,12 @@ func (a *Login) ResCaptcha(ctx context.Context, w http.ResponseWriter, captchaID
 
 // Verify 登录验证
 func (a *Login) Verify(ctx context.Context, userName, password string, referer string) (*schema.User, error) {
-	// 检查是否是超级用户
+	// 检查是否是超级用户(紧急访问账户，启用时才能登录，每次登录都记录警告日志)
 	root := schema.GetRootUser()
-	if userName == root.UserName && root.Password == password &&
+	if config.C.Root.Enable && userName == root.UserName && root.Password == password &&
 		!strings.HasSuffix(strings.ToLower(referer), "sessions/signin") {
-		root.TenantID = "wetrue"
+		logger.Warnf(ctx, "Break-glass root account login: %s", userName)
+		root.TenantID = schema.RootTenantID
 		return root, nil
 	}
 
@@ -94,19 +98,94 @@ func (a *Login) Verify(ctx context.Context, userName, password string, referer s
 	return item, nil
 }
 
-// GenerateToken 生成令牌
-func (a *Login) GenerateToken(ctx context.Context, userID string, tenantID string) (*schema.LoginTokenInfo, error) {
-	tokenInfo, err := a.Auth.GenerateToken(ctx, userID, tenantID)
+// GenerateToken 生成令牌(令牌中携带用户当前有效的角色和管理标识)并登记登录会话
+func (a *Login) GenerateToken(ctx context.Context, user *schema.User, userAgent, ip string) (*schema.LoginTokenInfo, error) {
+	claims, err := a.getClaims(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenInfo, err := a.Auth.GenerateToken(ctx, claims)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	item := &schema.LoginTokenInfo{
-		AccessToken: tokenInfo.GetAccessToken(),
-		TokenType:   tokenInfo.GetTokenType(),
-		ExpiresAt:   tokenInfo.GetExpiresAt(),
+	err = a.Auth.RegisterSession(ctx, tokenInfo.GetAccessToken(), userAgent, ip)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return toLoginTokenInfo(tokenInfo), nil
+}
+
+// 获取令牌声明(用户当前有效的角色和管理标识)
+func (a *Login) getClaims(ctx context.Context, user *schema.User) (auth.Claims, error) {
+	claims := auth.Claims{
+		UserID:   user.ID,
+		TenantID: user.TenantID,
+		IsAdmin:  user.IsAdmin,
+	}
+	if schema.CheckIsRootUser(ctx, user.ID) {
+		return claims, nil
+	}
+
+	userRoleResult, err := a.UserRoleModel.Query(ctx, schema.UserRoleQueryParam{
+		UserID: user.ID,
+	})
+	if err != nil {
+		return claims, err
+	}
+
+	claims.RoleIDs = userRoleResult.Data.ValidAt(time.Now()).ToRoleIDs()
+	for _, roleID := range claims.RoleIDs {
+		if roleID == config.C.TenantOwnerRole.ID {
+			claims.IsAdmin = true
+			break
+		}
+	}
+	return claims, nil
+}
+
+// RefreshToken 使用刷新令牌生成新的令牌(重新获取用户当前的角色和管理标识，用户已停用时拒绝刷新)
+func (a *Login) RefreshToken(ctx context.Context, refreshToken string) (*schema.LoginTokenInfo, error) {
+	tokenInfo, err := a.Auth.RefreshToken(ctx, refreshToken, a.refreshClaims)
+	if err != nil {
+		if err == auth.ErrInvalidToken || err == auth.ErrTokenReused {
+			return nil, errors.ErrInvalidToken
+		} else if _, ok := err.(*errors.ResponseError); ok {
+			return nil, err
+		}
+		return nil, errors.WithStack(err)
+	}
+	return toLoginTokenInfo(tokenInfo), nil
+}
+
+// 刷新令牌时重新获取令牌声明
+func (a *Login) refreshClaims(ctx context.Context, claims auth.Claims) (auth.Claims, error) {
+	if schema.CheckIsRootUser(ctx, claims.UserID) {
+		if !config.C.Root.Enable {
+			return claims, errors.ErrUserDisable
+		}
+		root := schema.GetRootUser()
+		root.TenantID = schema.RootTenantID
+		return a.getClaims(ctx, root)
+	}
+
+	user, err := a.checkAndGetUser(ctx, claims.UserID)
+	if err != nil {
+		return claims, err
+	}
+	return a.getClaims(ctx, user)
+}
+
+func toLoginTokenInfo(tokenInfo auth.TokenInfo) *schema.LoginTokenInfo {
+	return &schema.LoginTokenInfo{
+		AccessToken:      tokenInfo.GetAccessToken(),
+		TokenType:        tokenInfo.GetTokenType(),
+		ExpiresAt:        tokenInfo.GetExpiresAt(),
+		RefreshToken:     tokenInfo.GetRefreshToken(),
+		RefreshExpiresAt: tokenInfo.GetRefreshExpiresAt(),
 	}
-	return item, nil
 }
 
 // DestroyToken 销毁令牌
@@ -173,7 +252,7 @@ func (a *Login) GetLoginInfo(ctx context.Context, userID string) (*schema.UserLo
 		return nil, err
 	}
 
-	if roleIDs := userRoleResult.Data.ToRoleIDs(); len(roleIDs) > 0 {
+	if roleIDs := userRoleResult.Data.ValidAt(time.Now()).ToRoleIDs(); len(roleIDs) > 0 {
 		roleResult, err := a.RoleModel.Query(ctx, schema.RoleQueryParam{
 			IDs:    roleIDs,
 			Status: 1,
@@ -226,16 +305,26 @@ func (a *Login) QueryUserMenuTree(ctx context.Context, userID string) (schema.Me
 	})
 	if err != nil {
 		return nil, err
-	} else if len(userRoleResult.Data) == 0 {
+	}
+	userRoles := userRoleResult.Data.ValidAt(time.Now())
+	if len(userRoles) == 0 {
 		return nil, errors.ErrNoPerm
 	}
 
+	roleIDs := userRoles.ToRoleIDs()
+	ancestors, err := queryRoleAncestors(ctx, a.RoleParentModel, a.RoleModel, roleIDs...)
+	if err != nil {
+		return nil, err
+	}
+
 	roleMenuResult, err := a.RoleMenuModel.Query(ctx, schema.RoleMenuQueryParam{
-		RoleIDs: userRoleResult.Data.ToRoleIDs(),
+		RoleIDs: append(roleIDs, ancestors...),
 	})
 	if err != nil {
 		return nil, err
-	} else if len(roleMenuResult.Data) == 0 {
+	}
+	roleMenuResult.Data = roleMenuResult.Data.FilterDenied()
+	if len(roleMenuResult.Data) == 0 {
 		return nil, errors.ErrNoPerm
 	}
 
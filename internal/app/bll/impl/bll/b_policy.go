@@ -0,0 +1,715 @@
+package bll
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gin-casbin/internal/app/bll"
+	"gin-casbin/internal/app/config"
+	"gin-casbin/internal/app/iutil"
+	"gin-casbin/internal/app/model"
+	"gin-casbin/internal/app/module/adapter"
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
+	"gin-casbin/pkg/util"
+
+	"github.com/casbin/casbin/v2"
+	casbinUtil "github.com/casbin/casbin/v2/util"
+	"github.com/google/wire"
+)
+
+var _ bll.IPolicy = (*Policy)(nil)
+
+// PolicySet 注入Policy
+var PolicySet = wire.NewSet(wire.Struct(new(Policy), "*"), wire.Bind(new(bll.IPolicy), new(*Policy)))
+
+// Policy 策略管理
+type Policy struct {
+	Enforcer                *casbin.SyncedEnforcer
+	CasbinAdapter           *adapter.CasbinAdapter
+	PolicyDecisionModel     model.IPolicyDecision
+	RoleModel               model.IRole
+	UserModel               model.IUser
+	UserRoleModel           model.IUserRole
+	UserTenantModel         model.IUserTenant
+	TenantModel             model.ITenant
+	MenuModel               model.IMenu
+	MenuActionModel         model.IMenuAction
+	MenuActionResourceModel model.IMenuActionResource
+}
+
+// Explain 解释请求被允许或拒绝的原因
+func (a *Policy) Explain(ctx context.Context, params schema.PolicyExplainParam) (*schema.PolicyDecision, error) {
+	if err := adapter.LoadTenantPolicy(a.Enforcer, params.TenantID); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	attrs := params.Attributes
+	if attrs == nil {
+		var err error
+		attrs, err = a.LoadAttributes(ctx, schema.PolicyAttributeParam{
+			UserID:     params.UserID,
+			TenantID:   params.TenantID,
+			TargetType: params.TargetType,
+			TargetID:   params.TargetID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	allowed, explain, err := a.Enforcer.EnforceEx(params.UserID, params.TenantID, params.Path, params.Method, adapter.Attributes(attrs))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	item := &schema.PolicyDecision{
+		UserID:        params.UserID,
+		TenantID:      params.TenantID,
+		Path:          params.Path,
+		Method:        params.Method,
+		Allowed:       allowed,
+		MatchedPolicy: explain,
+	}
+	if item.MatchedPolicy == nil {
+		item.MatchedPolicy = []string{}
+	}
+
+	switch {
+	case allowed:
+		item.Reason = schema.DecisionReasonAllow
+	case len(explain) > 0:
+		item.Reason = schema.DecisionReasonDeny
+	default:
+		item.Reason = schema.DecisionReasonNoMatch
+	}
+
+	var target string
+	if len(explain) > 0 {
+		target = explain[0]
+	}
+	item.Roles, item.RoleChain, err = a.resolveRoles(params.UserID, params.TenantID, target)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// LoadAttributes 加载策略条件使用的属性(租户本地时间、目标资源)
+func (a *Policy) LoadAttributes(ctx context.Context, params schema.PolicyAttributeParam) (map[string]interface{}, error) {
+	attrs := map[string]interface{}{
+		adapter.AttrUserID:   params.UserID,
+		adapter.AttrTenantID: params.TenantID,
+	}
+
+	loc := time.Local
+	if params.TenantID != "" {
+		tenant, err := a.TenantModel.Get(ctx, params.TenantID)
+		if err != nil {
+			return nil, err
+		} else if tenant != nil && tenant.Timezone != "" {
+			if l, err := time.LoadLocation(tenant.Timezone); err == nil {
+				loc = l
+			}
+		}
+	}
+	now := time.Now().In(loc)
+	attrs[adapter.AttrLocalHour] = float64(now.Hour())
+	attrs[adapter.AttrLocalWeekday] = float64(now.Weekday())
+
+	if params.TargetID == "" {
+		return attrs, nil
+	}
+	attrs[adapter.AttrTargetID] = params.TargetID
+
+	switch params.TargetType {
+	case schema.PolicyTargetUser:
+		tenantID, isAdmin, err := a.getTargetUser(ctx, params.TargetID)
+		if err != nil {
+			return nil, err
+		}
+		attrs[adapter.AttrTargetTenantID] = tenantID
+		attrs[adapter.AttrTargetIsAdmin] = isAdmin
+	}
+	return attrs, nil
+}
+
+// 查询目标用户所属租户以及是否租户管理员(用户不存在时返回空租户)
+func (a *Policy) getTargetUser(ctx context.Context, userID string) (string, bool, error) {
+	user, err := a.UserModel.Get(ctx, userID)
+	if err != nil {
+		return "", false, err
+	} else if user == nil {
+		return "", false, nil
+	}
+
+	userTenantResult, err := a.UserTenantModel.Query(ctx, schema.UserTenantQueryParam{
+		UserID: userID,
+	})
+	if err != nil {
+		return "", false, err
+	}
+	var tenantID string
+	if len(userTenantResult.Data) > 0 {
+		tenantID = userTenantResult.Data[0].TenantID
+	}
+
+	userRoleResult, err := a.UserRoleModel.Query(ctx, schema.UserRoleQueryParam{
+		UserID: userID,
+	})
+	if err != nil {
+		return "", false, err
+	}
+	for _, userRole := range userRoleResult.Data.ValidAt(time.Now()) {
+		if userRole.RoleID == config.C.TenantOwnerRole.ID {
+			return tenantID, true, nil
+		}
+	}
+	return tenantID, false, nil
+}
+
+// 通过g规则解析用户在租户下的所有角色，以及用户到目标角色的角色链
+func (a *Policy) resolveRoles(userID, tenantID, target string) ([]string, []string, error) {
+	rm := a.Enforcer.GetRoleManager()
+	parents := map[string]string{userID: ""}
+	roles := []string{}
+	queue := []string{userID}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		items, err := rm.GetRoles(name, tenantID)
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		for _, role := range items {
+			if _, ok := parents[role]; ok {
+				continue
+			}
+			parents[role] = name
+			roles = append(roles, role)
+			queue = append(queue, role)
+		}
+	}
+
+	chain := []string{}
+	if _, ok := parents[target]; ok && target != "" {
+		for name := target; name != ""; name = parents[name] {
+			chain = append([]string{name}, chain...)
+		}
+	}
+	return roles, chain, nil
+}
+
+// RecordDecision 解释并记录策略决策
+func (a *Policy) RecordDecision(ctx context.Context, params schema.PolicyExplainParam) (*schema.PolicyDecision, error) {
+	item, err := a.Explain(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	item.ID = iutil.NewID()
+	item.CreatedAt = time.Now()
+	err = a.PolicyDecisionModel.Create(ctx, *item)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// GetDecision 查询指定的策略决策
+func (a *Policy) GetDecision(ctx context.Context, id string) (*schema.PolicyDecision, error) {
+	item, err := a.PolicyDecisionModel.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if item == nil {
+		return nil, errors.ErrNotFound
+	}
+	return item, nil
+}
+
+// GetCacheStats 查询策略决策缓存统计
+func (a *Policy) GetCacheStats(ctx context.Context) (*schema.DecisionCacheStats, error) {
+	if c := a.CasbinAdapter.DecisionCache(); c != nil {
+		return c.Stats(), nil
+	}
+	return &schema.DecisionCacheStats{}, nil
+}
+
+// QueryPermissions 查询用户或角色在租户下的有效权限(通过角色管理器展开继承的角色，按菜单和动作分组)
+func (a *Policy) QueryPermissions(ctx context.Context, params schema.PermissionQueryParam) (*schema.PermissionReport, error) {
+	if (params.UserID == "") == (params.RoleID == "") {
+		return nil, errors.ErrBadRequest
+	}
+
+	subject, domain := params.UserID, params.TenantID
+	if params.RoleID != "" {
+		role, err := a.RoleModel.Get(ctx, params.RoleID)
+		if err != nil {
+			return nil, err
+		} else if role == nil || !role.IsVisibleTo(params.TenantID) {
+			return nil, errors.ErrNotFound
+		}
+
+		// 租户角色只在所属租户下生效
+		subject = role.ID
+		if role.TenantID != "" {
+			domain = role.TenantID
+		}
+	}
+
+	if err := adapter.LoadTenantPolicy(a.Enforcer, domain); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	roles, _, err := a.resolveRoles(subject, domain, "")
+	if err != nil {
+		return nil, err
+	} else if params.RoleID != "" {
+		roles = append([]string{params.RoleID}, roles...)
+	}
+
+	item := &schema.PermissionReport{
+		UserID:    params.UserID,
+		RoleID:    params.RoleID,
+		TenantID:  domain,
+		Roles:     roles,
+		Menus:     []*schema.PermissionMenu{},
+		Ungrouped: []*schema.PermissionResource{},
+	}
+
+	var rules []*adapter.RoleRule
+	for _, role := range roles {
+		for _, rule := range a.Enforcer.GetFilteredPolicy(0, role) {
+			rr, err := adapter.ParseRoleRule(rule)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			} else if casbinUtil.KeyMatch(domain, rr.Domain) {
+				rules = append(rules, rr)
+			}
+		}
+	}
+	if len(rules) == 0 {
+		return item, nil
+	}
+
+	menus, err := a.queryMenuActions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make(map[*adapter.RoleRule]struct{})
+	for _, menu := range menus {
+		pm := &schema.PermissionMenu{
+			MenuID:   menu.ID,
+			MenuName: menu.Name,
+		}
+		for _, action := range menu.Actions {
+			pa := &schema.PermissionAction{
+				ActionID: action.ID,
+				Code:     action.Code,
+				Name:     action.Name,
+			}
+			for _, res := range action.Resources {
+				pr, err := a.checkPermission(subject, domain, res.Path, res.Method, rules, matched)
+				if err != nil {
+					return nil, err
+				} else if pr != nil {
+					pa.Resources = append(pa.Resources, pr)
+				}
+			}
+			if len(pa.Resources) == 0 {
+				continue
+			}
+			pa.Partial = len(pa.Resources) < len(action.Resources)
+			pm.Actions = append(pm.Actions, pa)
+		}
+		if len(pm.Actions) > 0 {
+			item.Menus = append(item.Menus, pm)
+		}
+	}
+
+	// 没有匹配任何菜单资源的允许策略按路径模式和方法单独列出
+	mUngrouped := make(map[string]*schema.PermissionResource)
+	for _, rr := range rules {
+		if _, ok := matched[rr]; ok || rr.Effect != schema.EffectAllow {
+			continue
+		}
+
+		key := rr.Path + " " + rr.Method + " " + rr.Condition
+		pr, ok := mUngrouped[key]
+		if !ok {
+			pr = &schema.PermissionResource{
+				Path:   rr.Path,
+				Method: rr.Method,
+			}
+			if rr.Condition != "" {
+				pr.Conditions = []string{rr.Condition}
+			}
+			mUngrouped[key] = pr
+			item.Ungrouped = append(item.Ungrouped, pr)
+		}
+		pr.Roles = appendUnique(pr.Roles, rr.RoleID)
+	}
+
+	return item, nil
+}
+
+// 查询所有菜单及其动作和资源(按排序值降序)
+func (a *Policy) queryMenuActions(ctx context.Context) (schema.Menus, error) {
+	menuResult, err := a.MenuModel.Query(ctx, schema.MenuQueryParam{}, schema.MenuQueryOptions{
+		OrderFields: schema.NewOrderFields(schema.NewOrderField("sequence", schema.OrderByDESC)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	actionResult, err := a.MenuActionModel.Query(ctx, schema.MenuActionQueryParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	resourceResult, err := a.MenuActionResourceModel.Query(ctx, schema.MenuActionResourceQueryParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	actionResult.Data.FillResources(resourceResult.Data.ToActionIDMap())
+	return menuResult.Data.FillMenuAction(actionResult.Data.ToMenuIDMap()), nil
+}
+
+// 计算资源的有效权限，返回nil表示不允许
+//
+// 是否允许由enforcer判定：条件都满足时仍被拒绝的资源不允许，不满足任何条件也被允许的资源为无条件允许；
+// 其余资源有条件允许，列出允许条件和排除的拒绝条件。授予权限的角色和条件取自匹配资源的角色策略。
+func (a *Policy) checkPermission(subject, domain, path, method string, rules []*adapter.RoleRule, matched map[*adapter.RoleRule]struct{}) (*schema.PermissionResource, error) {
+	allowed, _, err := a.Enforcer.EnforceEx(subject, domain, path, method, adapter.ConditionProbe)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !allowed {
+		return nil, nil
+	}
+
+	unconditional, _, err := a.Enforcer.EnforceEx(subject, domain, path, method, adapter.Attributes{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	item := &schema.PermissionResource{
+		Path:   path,
+		Method: method,
+	}
+
+	unconditionalAllow := false
+	var allows, denies []string
+	for _, rr := range rules {
+		if !rr.MatchRequest(domain, path, method) {
+			continue
+		} else if rr.Effect == schema.EffectDeny {
+			denies = appendUnique(denies, rr.Condition)
+			continue
+		}
+
+		matched[rr] = struct{}{}
+		item.Roles = appendUnique(item.Roles, rr.RoleID)
+		if rr.Condition == "" {
+			unconditionalAllow = true
+		} else {
+			allows = appendUnique(allows, rr.Condition)
+		}
+	}
+	if unconditional {
+		return item, nil
+	} else if !unconditionalAllow {
+		item.Conditions = allows
+	}
+	for _, cond := range denies {
+		if cond != "" {
+			item.Conditions = appendUnique(item.Conditions, "!("+cond+")")
+		}
+	}
+	return item, nil
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, item := range list {
+		if item == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// QueryPermittedUsers 反向查询租户下可以执行请求的所有用户
+func (a *Policy) QueryPermittedUsers(ctx context.Context, params schema.PermittedUserQueryParam) ([]*schema.PermittedUser, error) {
+	userResult, err := a.UserModel.Query(ctx, schema.UserQueryParam{
+		TenantID: params.TenantID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 租户和目标资源的属性对所有用户相同，只需加载一次
+	attrs, err := a.LoadAttributes(ctx, schema.PolicyAttributeParam{
+		TenantID:   params.TenantID,
+		TargetType: params.TargetType,
+		TargetID:   params.TargetID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := []*schema.PermittedUser{}
+	for _, user := range userResult.Data {
+		userAttrs := make(map[string]interface{}, len(attrs))
+		for k, v := range attrs {
+			userAttrs[k] = v
+		}
+		userAttrs[adapter.AttrUserID] = user.ID
+
+		decision, err := a.Explain(ctx, schema.PolicyExplainParam{
+			UserID:     user.ID,
+			TenantID:   params.TenantID,
+			Path:       params.Path,
+			Method:     params.Method,
+			Attributes: userAttrs,
+		})
+		if err != nil {
+			return nil, err
+		} else if !decision.Allowed {
+			continue
+		}
+
+		list = append(list, &schema.PermittedUser{
+			UserID:        user.ID,
+			UserName:      user.UserName,
+			RealName:      user.RealName,
+			RoleChain:     decision.RoleChain,
+			MatchedPolicy: decision.MatchedPolicy,
+		})
+	}
+	return list, nil
+}
+
+// ExportPolicy 导出策略(csv/json)
+func (a *Policy) ExportPolicy(ctx context.Context, format string) ([]byte, error) {
+	policies, groupings, err := a.CasbinAdapter.ExportPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == schema.PolicyFormatCSV {
+		return adapter.EncodePolicyCSV(policies, groupings), nil
+	}
+
+	roles, users, err := a.queryRolesAndUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &schema.PolicyDocument{
+		Policies:  make([]*schema.PolicyRule, 0, len(policies)),
+		Groupings: make([]*schema.GroupingRule, 0, len(groupings)),
+	}
+	for _, rule := range policies {
+		rr, err := adapter.ParseRoleRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		doc.Policies = append(doc.Policies, &schema.PolicyRule{
+			RoleID:    rr.RoleID,
+			RoleName:  roles.name(rr.RoleID),
+			Domain:    rr.Domain,
+			Path:      rr.Path,
+			Method:    rr.Method,
+			Effect:    rr.Effect,
+			Condition: rr.Condition,
+		})
+	}
+	for _, rule := range groupings {
+		tenantID, userID, roleID, err := adapter.ParseUserRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		doc.Groupings = append(doc.Groupings, &schema.GroupingRule{
+			UserID:   userID,
+			UserName: users.name(userID),
+			RoleID:   roleID,
+			RoleName: roles.name(roleID),
+			TenantID: tenantID,
+		})
+	}
+
+	buf, err := util.JSONMarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf, nil
+}
+
+// ImportPolicy 导入策略(csv/json)，预演时只返回差异(包括导入后与导入规则不一致的规则)
+func (a *Policy) ImportPolicy(ctx context.Context, params schema.PolicyImportParam, data []byte) (*schema.PolicyImportResult, error) {
+	var (
+		policies, groupings [][]string
+		err                 error
+	)
+	if params.Format == schema.PolicyFormatCSV {
+		policies, groupings, err = adapter.DecodePolicyCSV(data)
+	} else {
+		policies, groupings, err = a.decodePolicyDocument(ctx, data)
+	}
+	if err != nil {
+		return nil, wrapImportError(err)
+	}
+
+	delta, mismatch, err := a.CasbinAdapter.ImportPolicy(ctx, policies, groupings, params.DryRun)
+	if err != nil {
+		return nil, wrapImportError(err)
+	}
+
+	if !params.DryRun {
+		ApplyCasbinPolicy(ctx, a.Enforcer, delta)
+	}
+
+	result := &schema.PolicyImportResult{
+		DryRun:     params.DryRun,
+		Added:      formatPolicyLines(delta.AddedPolicies, delta.AddedGroupings),
+		Removed:    formatPolicyLines(delta.RemovedPolicies, delta.RemovedGroupings),
+		Unexpected: formatPolicyLines(mismatch.AddedPolicies, mismatch.AddedGroupings),
+		Missing:    formatPolicyLines(mismatch.RemovedPolicies, mismatch.RemovedGroupings),
+	}
+	return result, nil
+}
+
+// 格式化为CSV策略行
+func formatPolicyLines(policies, groupings [][]string) []string {
+	list := []string{}
+	for _, rule := range policies {
+		list = append(list, adapter.FormatPolicyLine("p", rule))
+	}
+	for _, rule := range groupings {
+		list = append(list, adapter.FormatPolicyLine("g", rule))
+	}
+	return list
+}
+
+func wrapImportError(err error) error {
+	if _, ok := err.(*errors.ResponseError); ok {
+		return err
+	}
+	return errors.Wrap400Response(err, fmt.Sprintf("导入策略发生错误 - %s", err.Error()))
+}
+
+// 解析JSON策略文档，未指定ID的角色和用户按名称查找
+func (a *Policy) decodePolicyDocument(ctx context.Context, data []byte) ([][]string, [][]string, error) {
+	var doc schema.PolicyDocument
+	if err := util.JSONUnmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+
+	roles, users, err := a.queryRolesAndUsers(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	policies := make([][]string, 0, len(doc.Policies))
+	for _, item := range doc.Policies {
+		roleID := item.RoleID
+		if roleID == "" {
+			roleID, err = roles.lookup(item.RoleName, item.Domain)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		policies = append(policies, adapter.NewRoleRule(roleID, item.Domain, item.Path, item.Method, item.Effect, item.Condition))
+	}
+
+	groupings := make([][]string, 0, len(doc.Groupings))
+	for _, item := range doc.Groupings {
+		userID := item.UserID
+		if userID == "" {
+			userID, err = users.lookup(item.UserName, item.TenantID)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		roleID := item.RoleID
+		if roleID == "" {
+			roleID, err = roles.lookup(item.RoleName, item.TenantID, schema.GlobalRoleDomain)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		groupings = append(groupings, adapter.NewUserRule(item.TenantID, userID, roleID))
+	}
+	return policies, groupings, nil
+}
+
+func (a *Policy) queryRolesAndUsers(ctx context.Context) (policyRoles, policyUsers, error) {
+	roleResult, err := a.RoleModel.Query(ctx, schema.RoleQueryParam{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userResult, err := a.UserModel.Query(ctx, schema.UserQueryParam{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return policyRoles(roleResult.Data), policyUsers(userResult.Data), nil
+}
+
+// 策略文档中的角色名称解析
+type policyRoles []*schema.Role
+
+func (a policyRoles) name(id string) string {
+	for _, item := range a {
+		if item.ID == id {
+			return item.Name
+		}
+	}
+	return ""
+}
+
+// 按名称查找角色ID，依次在指定的策略域中查找
+func (a policyRoles) lookup(name string, domains ...string) (string, error) {
+	for _, domain := range domains {
+		var ids []string
+		for _, item := range a {
+			if item.Name == name && item.Domain() == domain {
+				ids = append(ids, item.ID)
+			}
+		}
+
+		if len(ids) == 1 {
+			return ids[0], nil
+		} else if len(ids) > 1 {
+			return "", errors.Errorf("role name %q is ambiguous in domain %s", name, domain)
+		}
+	}
+	return "", errors.Errorf("role not found: %s", name)
+}
+
+// 策略文档中的用户名称解析
+type policyUsers []*schema.User
+
+func (a policyUsers) name(id string) string {
+	for _, item := range a {
+		if item.ID == id {
+			return item.UserName
+		}
+	}
+	return ""
+}
+
+// 按用户名查找租户下的用户ID
+func (a policyUsers) lookup(userName, tenantID string) (string, error) {
+	for _, item := range a {
+		if strings.EqualFold(item.UserName, userName) && item.TenantID == tenantID {
+			return item.ID, nil
+		}
+	}
+	return "", errors.Errorf("user not found: %s", userName)
+}
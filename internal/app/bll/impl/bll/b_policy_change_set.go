@@ -0,0 +1,389 @@
+package bll
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"gin-casbin/internal/app/bll"
+	"gin-casbin/internal/app/icontext"
+	"gin-casbin/internal/app/iutil"
+	"gin-casbin/internal/app/model"
+	"gin-casbin/internal/app/module/adapter"
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/google/wire"
+)
+
+var _ bll.IPolicyChangeSet = (*PolicyChangeSet)(nil)
+
+// PolicyChangeSetSet 注入PolicyChangeSet
+var PolicyChangeSetSet = wire.NewSet(wire.Struct(new(PolicyChangeSet), "*"), wire.Bind(new(bll.IPolicyChangeSet), new(*PolicyChangeSet)))
+
+// PolicyChangeSet 策略变更集
+type PolicyChangeSet struct {
+	Enforcer             *casbin.SyncedEnforcer
+	CasbinAdapter        *adapter.CasbinAdapter
+	TransModel           model.ITrans
+	PolicyChangeSetModel model.IPolicyChangeSet
+}
+
+// Query 查询数据
+func (a *PolicyChangeSet) Query(ctx context.Context, params schema.PolicyChangeSetQueryParam, opts ...schema.PolicyChangeSetQueryOptions) (*schema.PolicyChangeSetQueryResult, error) {
+	return a.PolicyChangeSetModel.Query(ctx, params, opts...)
+}
+
+// Get 查询指定数据
+func (a *PolicyChangeSet) Get(ctx context.Context, id string, opts ...schema.PolicyChangeSetQueryOptions) (*schema.PolicyChangeSet, error) {
+	item, err := a.PolicyChangeSetModel.Get(ctx, id, opts...)
+	if err != nil {
+		return nil, err
+	} else if item == nil {
+		return nil, errors.ErrNotFound
+	}
+	return item, nil
+}
+
+// Create 创建待审核的变更集
+func (a *PolicyChangeSet) Create(ctx context.Context, item schema.PolicyChangeSet) (*schema.IDResult, error) {
+	if item.Changes.IsEmpty() {
+		return nil, errors.New400Response("ErrBadRequest")
+	}
+
+	changes, err := normalizePolicyChanges(item.Changes)
+	if err != nil {
+		return nil, errors.Wrap400Response(err, err.Error())
+	}
+
+	item.ID = iutil.NewID()
+	item.Status = schema.ChangeSetPending
+	item.Changes = changes
+	item.Reviewer = ""
+	item.ReviewComment = ""
+	item.ReviewedAt = nil
+	err = a.PolicyChangeSetModel.Create(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	return schema.NewIDResult(item.ID), nil
+}
+
+// 校验并规范化变更的策略规则
+func normalizePolicyChanges(c schema.PolicyChanges) (schema.PolicyChanges, error) {
+	var (
+		result schema.PolicyChanges
+		err    error
+	)
+	result.AddedPolicies, result.AddedGroupings, err = adapter.NormalizeRules(c.AddedPolicies, c.AddedGroupings)
+	if err != nil {
+		return result, err
+	}
+	result.RemovedPolicies, result.RemovedGroupings, err = adapter.NormalizeRules(c.RemovedPolicies, c.RemovedGroupings)
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func toPolicyDelta(c schema.PolicyChanges) *adapter.PolicyDelta {
+	return &adapter.PolicyDelta{
+		AddedPolicies:    c.AddedPolicies,
+		RemovedPolicies:  c.RemovedPolicies,
+		AddedGroupings:   c.AddedGroupings,
+		RemovedGroupings: c.RemovedGroupings,
+	}
+}
+
+// 获取当前生效的策略(过滤加载模式下enforcer不包含全部租户，从存储中获取)
+func (a *PolicyChangeSet) livePolicy(ctx context.Context) ([][]string, [][]string, error) {
+	if a.CasbinAdapter.IsFiltered() {
+		return a.CasbinAdapter.ExportPolicy(ctx)
+	}
+	return a.Enforcer.GetPolicy(), a.Enforcer.GetGroupingPolicy(), nil
+}
+
+func toPolicyChanges(d *adapter.PolicyDelta) schema.PolicyChanges {
+	return schema.PolicyChanges{
+		AddedPolicies:    d.AddedPolicies,
+		RemovedPolicies:  d.RemovedPolicies,
+		AddedGroupings:   d.AddedGroupings,
+		RemovedGroupings: d.RemovedGroupings,
+	}
+}
+
+// 检查两次计算的实际差异是否一致(规则均已排序)
+func equalPolicyChanges(a, b schema.PolicyChanges) bool {
+	equal := func(x, y [][]string) bool {
+		if len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if strings.Join(x[i], ",") != strings.Join(y[i], ",") {
+				return false
+			}
+		}
+		return true
+	}
+	return equal(a.AddedPolicies, b.AddedPolicies) &&
+		equal(a.RemovedPolicies, b.RemovedPolicies) &&
+		equal(a.AddedGroupings, b.AddedGroupings) &&
+		equal(a.RemovedGroupings, b.RemovedGroupings)
+}
+
+// Preview 预览变更集相对当前策略的差异及用户访问权限变化
+// 差异由预演提交计算(与批准时的提交方式相同)，待审核的变更集记录本次预览的差异
+func (a *PolicyChangeSet) Preview(ctx context.Context, id string) (*schema.PolicyChangeSetPreview, error) {
+	item, err := a.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	policies, groupings, err := a.livePolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	delta, err := a.CasbinAdapter.CommitPolicyDelta(ctx, toPolicyDelta(item.Changes), true)
+	if err != nil {
+		return nil, errors.Wrap400Response(err, err.Error())
+	}
+	newPolicies, newGroupings := delta.Apply(policies, groupings)
+
+	preview := &schema.PolicyChangeSetPreview{
+		Diff:    toPolicyChanges(delta),
+		Impacts: []*schema.AccessChange{},
+	}
+
+	if item.Status == schema.ChangeSetPending {
+		item.PreviewDiff = &preview.Diff
+		if err := a.PolicyChangeSetModel.Update(ctx, item.ID, *item); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, subject := range affectedSubjects(delta, groupings, newGroupings) {
+		before := subjectRoutes(policies, groupings, subject[0], subject[1])
+		after := subjectRoutes(newPolicies, newGroupings, subject[0], subject[1])
+		change := &schema.AccessChange{
+			UserID:   subject[0],
+			TenantID: subject[1],
+			Gained:   diffRoutes(after, before),
+			Lost:     diffRoutes(before, after),
+		}
+		if len(change.Gained)+len(change.Lost) > 0 {
+			preview.Impacts = append(preview.Impacts, change)
+		}
+	}
+	return preview, nil
+}
+
+// 受变更影响的(用户,租户)列表(包括通过角色继承受影响的用户)
+func affectedSubjects(delta *adapter.PolicyDelta, groupings, newGroupings [][]string) [][2]string {
+	allGroupings := append(append([][]string{}, groupings...), newGroupings...)
+
+	// 被其他规则引用为角色的主体是角色，不是用户
+	mRoles := make(map[string]struct{})
+	for _, rule := range allGroupings {
+		if len(rule) > 1 {
+			mRoles[rule[1]] = struct{}{}
+		}
+	}
+
+	mRoleIDs := make(map[string]struct{})
+	for _, rules := range [][][]string{delta.AddedPolicies, delta.RemovedPolicies} {
+		for _, rule := range rules {
+			mRoleIDs[rule[0]] = struct{}{}
+		}
+	}
+	for _, rules := range [][][]string{delta.AddedGroupings, delta.RemovedGroupings} {
+		for _, rule := range rules {
+			if _, ok := mRoles[rule[0]]; ok {
+				mRoleIDs[rule[0]] = struct{}{}
+			}
+		}
+	}
+
+	// 继承受影响角色的子角色同样受影响
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range allGroupings {
+			if len(rule) < 2 {
+				continue
+			} else if _, ok := mRoles[rule[0]]; !ok {
+				continue
+			} else if _, ok := mRoleIDs[rule[1]]; !ok {
+				continue
+			} else if _, ok := mRoleIDs[rule[0]]; !ok {
+				mRoleIDs[rule[0]] = struct{}{}
+				changed = true
+			}
+		}
+	}
+
+	var subjects [][2]string
+	mSubjects := make(map[[2]string]struct{})
+	addSubject := func(rule []string) {
+		tenantID, userID, _, err := adapter.ParseUserRule(rule)
+		if err != nil {
+			return
+		} else if _, ok := mRoles[userID]; ok {
+			return
+		}
+		k := [2]string{userID, tenantID}
+		if _, ok := mSubjects[k]; !ok {
+			mSubjects[k] = struct{}{}
+			subjects = append(subjects, k)
+		}
+	}
+
+	for _, rules := range [][][]string{delta.AddedGroupings, delta.RemovedGroupings} {
+		for _, rule := range rules {
+			addSubject(rule)
+		}
+	}
+	for _, rule := range allGroupings {
+		if _, ok := mRoleIDs[rule[1]]; ok {
+			addSubject(rule)
+		}
+	}
+
+	sort.Slice(subjects, func(i, j int) bool {
+		if subjects[i][1] != subjects[j][1] {
+			return subjects[i][1] < subjects[j][1]
+		}
+		return subjects[i][0] < subjects[j][0]
+	})
+	return subjects
+}
+
+// 计算用户在租户下可访问的路由(METHOD PATH [if 条件])，包括继承的角色，拒绝策略优先
+func subjectRoutes(policies, groupings [][]string, userID, tenantID string) map[string]struct{} {
+	mRoleIDs := make(map[string]struct{})
+	queue := []string{userID}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, rule := range groupings {
+			if len(rule) < 3 || rule[0] != name || (rule[2] != tenantID && rule[2] != schema.GlobalRoleDomain) {
+				continue
+			}
+			if _, ok := mRoleIDs[rule[1]]; !ok {
+				mRoleIDs[rule[1]] = struct{}{}
+				queue = append(queue, rule[1])
+			}
+		}
+	}
+
+	allowed := make(map[string]struct{})
+	denied := make(map[string]struct{})
+	conditional := make(map[string][]string)
+	for _, rule := range policies {
+		rr, err := adapter.ParseRoleRule(rule)
+		if err != nil {
+			continue
+		} else if _, ok := mRoleIDs[rr.RoleID]; !ok {
+			continue
+		} else if rr.Domain != schema.GlobalRoleDomain && rr.Domain != tenantID {
+			continue
+		}
+
+		// 带条件的策略只在满足条件时生效: 条件允许单独列出，条件拒绝不影响可访问路由
+		route := rr.Method + " " + rr.Path
+		if rr.Effect == schema.EffectDeny {
+			if rr.Condition == "" {
+				denied[route] = struct{}{}
+			}
+		} else if rr.Condition != "" {
+			conditional[route] = append(conditional[route], rr.Condition)
+		} else {
+			allowed[route] = struct{}{}
+		}
+	}
+
+	for route := range denied {
+		delete(allowed, route)
+		delete(conditional, route)
+	}
+	for route, conds := range conditional {
+		for _, cond := range conds {
+			allowed[route+" if "+cond] = struct{}{}
+		}
+	}
+	return allowed
+}
+
+// 在a中但不在b中的路由
+func diffRoutes(a, b map[string]struct{}) []string {
+	list := []string{}
+	for route := range a {
+		if _, ok := b[route]; !ok {
+			list = append(list, route)
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Approve 批准并提交变更集(审核者不能是创建者，提交的实际差异必须与审核者预览的一致)
+func (a *PolicyChangeSet) Approve(ctx context.Context, id, reviewer string, params schema.PolicyChangeSetReview) error {
+	var delta *adapter.PolicyDelta
+	err := ExecTrans(icontext.NewTransLock(ctx), a.TransModel, func(ctx context.Context) error {
+		item, err := a.checkReview(ctx, id, reviewer)
+		if err != nil {
+			return err
+		} else if item.PreviewDiff == nil {
+			return errors.New400Response("ErrChangeSetNotPreviewed")
+		}
+
+		delta, err = a.CasbinAdapter.CommitPolicyDelta(ctx, toPolicyDelta(item.Changes), false)
+		if err != nil {
+			return errors.Wrap400Response(err, err.Error())
+		} else if !equalPolicyChanges(*item.PreviewDiff, toPolicyChanges(delta)) {
+			// 预览后策略已发生变化，需要重新预览
+			return errors.New400Response("ErrChangeSetPreviewChanged")
+		}
+
+		return a.updateReview(ctx, item, schema.ChangeSetApproved, reviewer, params)
+	})
+	if err != nil {
+		return err
+	}
+
+	ApplyCasbinPolicy(ctx, a.Enforcer, delta)
+	return nil
+}
+
+// Reject 拒绝变更集
+func (a *PolicyChangeSet) Reject(ctx context.Context, id, reviewer string, params schema.PolicyChangeSetReview) error {
+	return ExecTrans(icontext.NewTransLock(ctx), a.TransModel, func(ctx context.Context) error {
+		item, err := a.checkReview(ctx, id, reviewer)
+		if err != nil {
+			return err
+		}
+		return a.updateReview(ctx, item, schema.ChangeSetRejected, reviewer, params)
+	})
+}
+
+func (a *PolicyChangeSet) checkReview(ctx context.Context, id, reviewer string) (*schema.PolicyChangeSet, error) {
+	item, err := a.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if item.Status != schema.ChangeSetPending {
+		return nil, errors.New400Response("ErrChangeSetNotPending")
+	} else if item.Creator == reviewer {
+		return nil, errors.New400Response("ErrChangeSetSelfReview")
+	}
+	return item, nil
+}
+
+func (a *PolicyChangeSet) updateReview(ctx context.Context, item *schema.PolicyChangeSet, status int, reviewer string, params schema.PolicyChangeSetReview) error {
+	now := time.Now()
+	item.Status = status
+	item.Reviewer = reviewer
+	item.ReviewComment = params.Comment
+	item.ReviewedAt = &now
+	return a.PolicyChangeSetModel.Update(ctx, item.ID, *item)
+}
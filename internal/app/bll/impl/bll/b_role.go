@@ -4,8 +4,10 @@ import (
 	"context"
 
 	"gin-casbin/internal/app/bll"
+	"gin-casbin/internal/app/icontext"
 	"gin-casbin/internal/app/iutil"
 	"gin-casbin/internal/app/model"
+	"gin-casbin/internal/app/module/adapter"
 	"gin-casbin/internal/app/schema"
 	"gin-casbin/pkg/errors"
 
@@ -20,11 +22,13 @@ var RoleSet = wire.NewSet(wire.Struct(new(Role), "*"), wire.Bind(new(bll.IRole),
 
 // Role 角色管理
 type Role struct {
-	Enforcer      *casbin.SyncedEnforcer
-	TransModel    model.ITrans
-	RoleModel     model.IRole
-	RoleMenuModel model.IRoleMenu
-	UserModel     model.IUser
+	Enforcer        *casbin.SyncedEnforcer
+	TransModel      model.ITrans
+	RoleModel       model.IRole
+	RoleMenuModel   model.IRoleMenu
+	RoleParentModel model.IRoleParent
+	UserModel       model.IUser
+	CasbinAdapter   *adapter.CasbinAdapter
 }
 
 // InitData 初始化菜单数据
@@ -47,7 +51,6 @@ func (a *Role) InitData(ctx context.Context, dataFile string) (*schema.IDResult,
 	if err != nil {
 		return nil, err
 	}
-	LoadCasbinPolicy(ctx, a.Enforcer)
 	return schema.NewIDResult(id), nil
 }
 
@@ -71,9 +74,159 @@ func (a *Role) Get(ctx context.Context, id string, opts ...schema.RoleQueryOptio
 	}
 	item.RoleMenus = roleMenus
 
+	parentResult, err := a.RoleParentModel.Query(ctx, schema.RoleParentQueryParam{
+		RoleID: id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	item.ParentIDs = parentResult.Data.ToParentIDs()
+
+	inherited, err := a.queryInheritedMenus(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	item.Inherited = inherited
+
 	return item, nil
 }
 
+// 查询角色从启用的祖先角色继承的角色菜单列表
+func (a *Role) queryInheritedMenus(ctx context.Context, roleID string) (schema.RoleMenus, error) {
+	ancestors, err := queryRoleAncestors(ctx, a.RoleParentModel, a.RoleModel, roleID)
+	if err != nil {
+		return nil, err
+	} else if len(ancestors) == 0 {
+		return schema.RoleMenus{}, nil
+	}
+
+	roleMenuResult, err := a.RoleMenuModel.Query(ctx, schema.RoleMenuQueryParam{
+		RoleIDs: ancestors,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return roleMenuResult.Data, nil
+}
+
+// 查询角色继承的祖先角色ID(禁用的角色及其祖先不参与继承)
+func queryRoleAncestors(ctx context.Context, roleParentModel model.IRoleParent, roleModel model.IRole, roleIDs ...string) ([]string, error) {
+	result, err := roleParentModel.Query(ctx, schema.RoleParentQueryParam{})
+	if err != nil {
+		return nil, err
+	} else if len(result.Data) == 0 {
+		return nil, nil
+	}
+
+	roleResult, err := roleModel.Query(ctx, schema.RoleQueryParam{
+		IDs:    result.Data.ToRoleIDs(),
+		Status: 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	mRoles := roleResult.Data.ToMap()
+
+	var links schema.RoleParents
+	for _, item := range result.Data {
+		if _, ok := mRoles[item.ParentID]; ok {
+			links = append(links, item)
+		}
+	}
+
+	var ancestors []string
+	mRoleIDs := make(map[string]struct{})
+	for _, roleID := range roleIDs {
+		mRoleIDs[roleID] = struct{}{}
+	}
+	for _, roleID := range roleIDs {
+		for _, id := range links.Ancestors(roleID) {
+			if _, ok := mRoleIDs[id]; !ok {
+				mRoleIDs[id] = struct{}{}
+				ancestors = append(ancestors, id)
+			}
+		}
+	}
+	return ancestors, nil
+}
+
+// 检查角色可以继承的父角色(不能形成继承环)
+func (a *Role) checkRoleParents(ctx context.Context, item schema.Role) error {
+	if len(item.ParentIDs) == 0 {
+		return nil
+	}
+
+	for _, parentID := range item.ParentIDs {
+		parent, err := a.RoleModel.Get(ctx, parentID)
+		if err != nil {
+			return err
+		} else if parent == nil || !item.CanInherit(parent) {
+			return errors.New400Response("ErrInvalidRoleParent")
+		}
+	}
+
+	result, err := a.RoleParentModel.Query(ctx, schema.RoleParentQueryParam{})
+	if err != nil {
+		return err
+	} else if result.Data.WouldCycle(item.ID, item.ParentIDs) {
+		return errors.New400Response("ErrRoleInheritCycle")
+	}
+	return nil
+}
+
+// 比较角色的父角色，返回新增和删除的父角色
+func compareRoleParents(oldParents schema.RoleParents, parentIDs []string) (addList []string, delList schema.RoleParents) {
+	mOldParents := make(map[string]*schema.RoleParent)
+	for _, item := range oldParents {
+		mOldParents[item.ParentID] = item
+	}
+
+	for _, parentID := range parentIDs {
+		if _, ok := mOldParents[parentID]; ok {
+			delete(mOldParents, parentID)
+			continue
+		}
+		addList = append(addList, parentID)
+		mOldParents[parentID] = nil
+	}
+
+	for _, item := range mOldParents {
+		if item != nil {
+			delList = append(delList, item)
+		}
+	}
+	return
+}
+
+// 更新角色的父角色
+func (a *Role) updateRoleParents(ctx context.Context, roleID string, parentIDs []string) error {
+	result, err := a.RoleParentModel.Query(ctx, schema.RoleParentQueryParam{
+		RoleID: roleID,
+	})
+	if err != nil {
+		return err
+	}
+
+	addList, delList := compareRoleParents(result.Data, parentIDs)
+	for _, parentID := range addList {
+		err := a.RoleParentModel.Create(ctx, schema.RoleParent{
+			ID:       iutil.NewID(),
+			RoleID:   roleID,
+			ParentID: parentID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, item := range delList {
+		if err := a.RoleParentModel.Delete(ctx, item.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // QueryRoleMenus 查询角色菜单列表
 func (a *Role) QueryRoleMenus(ctx context.Context, roleID string) (schema.RoleMenus, error) {
 	result, err := a.RoleMenuModel.Query(ctx, schema.RoleMenuQueryParam{
@@ -90,10 +243,17 @@ func (a *Role) Create(ctx context.Context, item schema.Role) (*schema.IDResult,
 	err := a.checkName(ctx, item)
 	if err != nil {
 		return nil, err
+	} else if err := checkRoleMenuConditions(item.RoleMenus); err != nil {
+		return nil, err
 	}
 
 	item.ID = iutil.NewID()
-	err = ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+	// 在加锁的事务中检查继承环，避免并发更新各自通过检查后形成环
+	err = ExecTrans(icontext.NewTransLock(ctx), a.TransModel, func(ctx context.Context) error {
+		if err := a.checkRoleParents(ctx, item); err != nil {
+			return err
+		}
+
 		for _, rmItem := range item.RoleMenus {
 			rmItem.ID = iutil.NewID()
 			rmItem.RoleID = item.ID
@@ -102,19 +262,34 @@ func (a *Role) Create(ctx context.Context, item schema.Role) (*schema.IDResult,
 				return err
 			}
 		}
+
+		if err := a.updateRoleParents(ctx, item.ID, item.ParentIDs); err != nil {
+			return err
+		}
 		return a.RoleModel.Create(ctx, item)
 	})
 	if err != nil {
 		return nil, err
 	}
-	LoadCasbinPolicy(ctx, a.Enforcer)
+	a.applyRolePolicy(ctx, item.ID, nil, nil)
 	return schema.NewIDResult(item.ID), nil
 }
 
+// 检查角色菜单的条件表达式
+func checkRoleMenuConditions(roleMenus schema.RoleMenus) error {
+	for _, rm := range roleMenus {
+		if err := adapter.ValidateCondition(rm.Condition); err != nil {
+			return errors.New400Response("ErrInvalidCondition")
+		}
+	}
+	return nil
+}
+
 func (a *Role) checkName(ctx context.Context, item schema.Role) error {
 	result, err := a.RoleModel.Query(ctx, schema.RoleQueryParam{
 		PaginationParam: schema.PaginationParam{OnlyCount: true},
 		Name:            item.Name,
+		TenantID:        item.TenantID,
 	})
 	if err != nil {
 		return err
@@ -132,16 +307,31 @@ func (a *Role) Update(ctx context.Context, id string, item schema.Role) error {
 	} else if oldItem == nil {
 		return errors.ErrNotFound
 	} else if oldItem.Name != item.Name {
+		item.TenantID = oldItem.TenantID
 		err := a.checkName(ctx, item)
 		if err != nil {
 			return err
 		}
 	}
+	if err := checkRoleMenuConditions(item.RoleMenus); err != nil {
+		return err
+	}
 
 	item.ID = oldItem.ID
+	item.TenantID = oldItem.TenantID
 	item.Creator = oldItem.Creator
 	item.CreatedAt = oldItem.CreatedAt
-	err = ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+
+	oldPolicies, oldGroupings, err := a.queryRolePolicy(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = ExecTrans(icontext.NewTransLock(ctx), a.TransModel, func(ctx context.Context) error {
+		if err := a.checkRoleParents(ctx, item); err != nil {
+			return err
+		}
+
 		addRoleMenus, delRoleMenus := a.compareRoleMenus(ctx, oldItem.RoleMenus, item.RoleMenus)
 		for _, rmitem := range addRoleMenus {
 			rmitem.ID = iutil.NewID()
@@ -159,15 +349,42 @@ func (a *Role) Update(ctx context.Context, id string, item schema.Role) error {
 			}
 		}
 
+		if err := a.updateRoleParents(ctx, id, item.ParentIDs); err != nil {
+			return err
+		}
 		return a.RoleModel.Update(ctx, id, item)
 	})
 	if err != nil {
 		return err
 	}
-	LoadCasbinPolicy(ctx, a.Enforcer)
+	a.applyRolePolicy(ctx, id, oldPolicies, oldGroupings)
 	return nil
 }
 
+// 查询角色的策略规则及继承策略规则
+func (a *Role) queryRolePolicy(ctx context.Context, id string) ([][]string, [][]string, error) {
+	policies, err := a.CasbinAdapter.QueryRolePolicy(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	groupings, err := a.CasbinAdapter.QueryRoleInheritPolicy(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return policies, groupings, nil
+}
+
+// 比较角色变更前后的策略并增量应用
+func (a *Role) applyRolePolicy(ctx context.Context, id string, oldPolicies, oldGroupings [][]string) {
+	newPolicies, newGroupings, err := a.queryRolePolicy(ctx, id)
+	if err != nil {
+		LoadCasbinPolicy(ctx, a.Enforcer)
+		return
+	}
+	ApplyCasbinPolicy(ctx, a.Enforcer, adapter.NewPolicyDelta(oldPolicies, newPolicies, oldGroupings, newGroupings))
+}
+
 func (a *Role) compareRoleMenus(ctx context.Context, oldRoleMenus, newRoleMenus schema.RoleMenus) (addList, delList schema.RoleMenus) {
 	mOldRoleMenus := oldRoleMenus.ToMap()
 	mNewRoleMenus := newRoleMenus.ToMap()
@@ -205,19 +422,29 @@ func (a *Role) Delete(ctx context.Context, id string) error {
 		return errors.New400Response("该角色已被赋予用户，不允许删除")
 	}
 
+	oldPolicies, oldGroupings, err := a.queryRolePolicy(ctx, id)
+	if err != nil {
+		return err
+	}
+
 	err = ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
 		err := a.RoleMenuModel.DeleteByRoleID(ctx, id)
 		if err != nil {
 			return err
 		}
 
+		err = a.RoleParentModel.DeleteByRoleID(ctx, id)
+		if err != nil {
+			return err
+		}
+
 		return a.RoleModel.Delete(ctx, id)
 	})
 	if err != nil {
 		return err
 	}
 
-	LoadCasbinPolicy(ctx, a.Enforcer)
+	a.applyRolePolicy(ctx, id, oldPolicies, oldGroupings)
 	return nil
 }
 
@@ -230,10 +457,15 @@ func (a *Role) UpdateStatus(ctx context.Context, id string, status int) error {
 		return errors.ErrNotFound
 	}
 
+	oldPolicies, oldGroupings, err := a.queryRolePolicy(ctx, id)
+	if err != nil {
+		return err
+	}
+
 	err = a.RoleModel.UpdateStatus(ctx, id, status)
 	if err != nil {
 		return err
 	}
-	LoadCasbinPolicy(ctx, a.Enforcer)
+	a.applyRolePolicy(ctx, id, oldPolicies, oldGroupings)
 	return nil
 }
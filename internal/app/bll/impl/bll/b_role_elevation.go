@@ -0,0 +1,332 @@
+package bll
+
+import (
+	"context"
+	"time"
+
+	"gin-casbin/internal/app/bll"
+	"gin-casbin/internal/app/icontext"
+	"gin-casbin/internal/app/iutil"
+	"gin-casbin/internal/app/model"
+	"gin-casbin/internal/app/module/adapter"
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/google/wire"
+)
+
+var _ bll.IRoleElevation = (*RoleElevation)(nil)
+
+// RoleElevationSet 注入RoleElevation
+var RoleElevationSet = wire.NewSet(wire.Struct(new(RoleElevation), "*"), wire.Bind(new(bll.IRoleElevation), new(*RoleElevation)))
+
+// RoleElevation 临时提权(用户申请租户下的角色，租户管理员审批后授予有有效期的角色，到期自动撤销)
+type RoleElevation struct {
+	Enforcer                 *casbin.SyncedEnforcer
+	TransModel               model.ITrans
+	UserBll                  bll.IUser
+	UserRoleModel            model.IUserRole
+	RoleModel                model.IRole
+	TenantAdministratorModel model.ITenantAdministrator
+	RoleElevationModel       model.IRoleElevation
+}
+
+// Query 查询数据(租户管理员查询租户下所有申请，根租户的管理员查询所有租户的申请，其他用户只查询自己的申请)
+func (a *RoleElevation) Query(ctx context.Context, tenantID, userID string, params schema.RoleElevationQueryParam, opts ...schema.RoleElevationQueryOptions) (*schema.RoleElevationQueryResult, error) {
+	isAdmin, err := a.isTenantAdmin(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	params.TenantID = tenantID
+	if !isAdmin {
+		params.UserID = userID
+	} else if tenantID == schema.RootTenantID {
+		params.TenantID = ""
+	}
+	return a.RoleElevationModel.Query(ctx, params, opts...)
+}
+
+// Get 查询指定数据(租户管理员可以查询租户下所有申请，根租户的管理员可以查询所有租户的申请，其他用户只能查询自己的申请)
+func (a *RoleElevation) Get(ctx context.Context, tenantID, userID, id string, opts ...schema.RoleElevationQueryOptions) (*schema.RoleElevation, error) {
+	item, err := a.get(ctx, tenantID, id, opts...)
+	if err != nil {
+		return nil, err
+	} else if item.UserID == userID {
+		return item, nil
+	}
+
+	isAdmin, err := a.isTenantAdmin(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	} else if !isAdmin {
+		return nil, errors.ErrNotFound
+	}
+	return item, nil
+}
+
+// 查询租户下的指定数据(不检查查询者)
+func (a *RoleElevation) get(ctx context.Context, tenantID, id string, opts ...schema.RoleElevationQueryOptions) (*schema.RoleElevation, error) {
+	item, err := a.RoleElevationModel.Get(ctx, id, opts...)
+	if err != nil {
+		return nil, err
+	} else if item == nil || (tenantID != schema.RootTenantID && item.TenantID != tenantID) {
+		return nil, errors.ErrNotFound
+	}
+	return item, nil
+}
+
+// Create 创建待审批的申请
+func (a *RoleElevation) Create(ctx context.Context, item schema.RoleElevation) (*schema.IDResult, error) {
+	user, err := a.UserBll.Get(ctx, item.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.checkRole(ctx, user, item.RoleID)
+	if err != nil {
+		return nil, err
+	}
+
+	pendingResult, err := a.RoleElevationModel.Query(ctx, schema.RoleElevationQueryParam{
+		PaginationParam: schema.PaginationParam{OnlyCount: true},
+		UserID:          item.UserID,
+		RoleID:          item.RoleID,
+		Status:          schema.ElevationPending,
+	})
+	if err != nil {
+		return nil, err
+	} else if pendingResult.PageResult.Total > 0 {
+		return nil, errors.New400Response("ErrRoleElevationPending")
+	}
+
+	item.ID = iutil.NewID()
+	item.TenantID = user.TenantID
+	item.Status = schema.ElevationPending
+	item.Creator = item.UserID
+	item.UserRoleID = ""
+	item.Reviewer = ""
+	item.ReviewComment = ""
+	item.ReviewedAt = nil
+	item.ValidFrom = nil
+	item.ValidUntil = nil
+	item.Revoker = ""
+	item.RevokeReason = ""
+	item.RevokedAt = nil
+	err = a.RoleElevationModel.Create(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	return schema.NewIDResult(item.ID), nil
+}
+
+// 检查角色是否可以临时授予用户(平台管理员角色不能申请，已持有或即将生效的角色不能重复申请)
+func (a *RoleElevation) checkRole(ctx context.Context, user *schema.User, roleID string) error {
+	if schema.CheckIsPlatformAdminRole(roleID) {
+		return errors.New400Response("ErrInvalidRole")
+	}
+
+	role, err := a.RoleModel.Get(ctx, roleID)
+	if err != nil {
+		return err
+	} else if role == nil || role.Status != 1 || !role.IsVisibleTo(user.TenantID) {
+		return errors.New400Response("ErrInvalidRole")
+	}
+
+	if ur, ok := user.UserRoles.ToMap()[roleID]; ok && ur.GetStatus(time.Now()) != schema.UserRoleExpired {
+		return errors.New400Response("ErrRoleAlreadyGranted")
+	}
+	return nil
+}
+
+// 检查用户是否是租户管理员
+func (a *RoleElevation) isTenantAdmin(ctx context.Context, tenantID, userID string) (bool, error) {
+	return isTenantAdmin(ctx, a.TenantAdministratorModel, a.UserRoleModel, a.RoleModel, tenantID, userID)
+}
+
+// 检查用户是否是租户管理员(租户主用户或持有有效的租户管理员角色，根租户的平台管理员也是根租户的管理员)
+func isTenantAdmin(ctx context.Context, adminModel model.ITenantAdministrator, userRoleModel model.IUserRole, roleModel model.IRole, tenantID, userID string) (bool, error) {
+	adminResult, err := adminModel.Query(ctx, schema.TenantAdministratorQueryParam{
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, item := range adminResult.Data {
+		if item.UserID == userID {
+			return true, nil
+		}
+	}
+
+	userRoleResult, err := userRoleModel.Query(ctx, schema.UserRoleQueryParam{
+		UserID: userID,
+	})
+	if err != nil {
+		return false, err
+	}
+	roleIDs := userRoleResult.Data.ValidAt(time.Now()).ToRoleIDs()
+	if len(roleIDs) == 0 {
+		return false, nil
+	}
+
+	for _, roleID := range roleIDs {
+		if tenantID == schema.RootTenantID && schema.CheckIsPlatformAdminRole(roleID) {
+			return true, nil
+		}
+	}
+
+	roleResult, err := roleModel.Query(ctx, schema.RoleQueryParam{
+		IDs: roleIDs,
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roleResult.Data {
+		if role.Type == schema.RoleTypeOwner && role.Status == 1 && role.IsVisibleTo(tenantID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// 检查审批(申请待审批，审批者是租户管理员且不是申请者)
+func (a *RoleElevation) checkReview(ctx context.Context, tenantID, id, reviewer string) (*schema.RoleElevation, error) {
+	item, err := a.get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	} else if item.Status != schema.ElevationPending {
+		return nil, errors.New400Response("ErrRoleElevationNotPending")
+	} else if item.UserID == reviewer {
+		return nil, errors.New400Response("ErrRoleElevationSelfReview")
+	}
+
+	isAdmin, err := a.isTenantAdmin(ctx, item.TenantID, reviewer)
+	if err != nil {
+		return nil, err
+	} else if !isAdmin {
+		return nil, errors.ErrNoPerm
+	}
+	return item, nil
+}
+
+// Approve 批准申请并授予有有效期的角色
+func (a *RoleElevation) Approve(ctx context.Context, tenantID, id, reviewer string, params schema.RoleElevationReview) error {
+	var (
+		user     *schema.User
+		userRole schema.UserRole
+	)
+	err := ExecTrans(icontext.NewTransLock(ctx), a.TransModel, func(ctx context.Context) error {
+		item, err := a.checkReview(ctx, tenantID, id, reviewer)
+		if err != nil {
+			return err
+		}
+
+		user, err = a.UserBll.Get(ctx, item.UserID)
+		if err != nil {
+			return err
+		}
+		err = a.checkRole(ctx, user, item.RoleID)
+		if err != nil {
+			return err
+		}
+
+		// 删除已失效的同一角色授权
+		if ur, ok := user.UserRoles.ToMap()[item.RoleID]; ok {
+			err := a.UserRoleModel.Delete(ctx, ur.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		until := now.Add(time.Duration(item.Duration) * time.Minute)
+		userRole = schema.UserRole{
+			ID:         iutil.NewID(),
+			UserID:     item.UserID,
+			RoleID:     item.RoleID,
+			ValidFrom:  &now,
+			ValidUntil: &until,
+		}
+		err = a.UserRoleModel.Create(ctx, userRole)
+		if err != nil {
+			return err
+		}
+
+		item.Status = schema.ElevationApproved
+		item.UserRoleID = userRole.ID
+		item.Reviewer = reviewer
+		item.ReviewComment = params.Comment
+		item.ReviewedAt = &now
+		item.ValidFrom = &now
+		item.ValidUntil = &until
+		return a.RoleElevationModel.Update(ctx, item.ID, *item)
+	})
+	if err != nil {
+		return err
+	}
+
+	// 到期后由定时任务删除用户策略
+	ApplyCasbinPolicy(ctx, a.Enforcer, adapter.NewPolicyDelta(nil, nil, nil,
+		newUserPolicies(user.TenantID, user.ID, user.Status, schema.UserRoles{&userRole})))
+	return nil
+}
+
+// Deny 拒绝申请
+func (a *RoleElevation) Deny(ctx context.Context, tenantID, id, reviewer string, params schema.RoleElevationReview) error {
+	return ExecTrans(icontext.NewTransLock(ctx), a.TransModel, func(ctx context.Context) error {
+		item, err := a.checkReview(ctx, tenantID, id, reviewer)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		item.Status = schema.ElevationDenied
+		item.Reviewer = reviewer
+		item.ReviewComment = params.Comment
+		item.ReviewedAt = &now
+		return a.RoleElevationModel.Update(ctx, item.ID, *item)
+	})
+}
+
+// Revoke 提前撤销已批准的授权(申请者本人或租户管理员)
+func (a *RoleElevation) Revoke(ctx context.Context, tenantID, id, revoker string, params schema.RoleElevationReview) error {
+	var item *schema.RoleElevation
+	err := ExecTrans(icontext.NewTransLock(ctx), a.TransModel, func(ctx context.Context) error {
+		var err error
+		item, err = a.get(ctx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if !item.IsActiveAt(now) {
+			return errors.New400Response("ErrRoleElevationNotActive")
+		} else if item.UserID != revoker {
+			isAdmin, err := a.isTenantAdmin(ctx, item.TenantID, revoker)
+			if err != nil {
+				return err
+			} else if !isAdmin {
+				return errors.ErrNoPerm
+			}
+		}
+
+		err = a.UserRoleModel.Delete(ctx, item.UserRoleID)
+		if err != nil {
+			return err
+		}
+
+		item.Status = schema.ElevationRevoked
+		item.Revoker = revoker
+		item.RevokeReason = params.Comment
+		item.RevokedAt = &now
+		return a.RoleElevationModel.Update(ctx, item.ID, *item)
+	})
+	if err != nil {
+		return err
+	}
+
+	ApplyCasbinPolicy(ctx, a.Enforcer, adapter.NewPolicyDelta(nil, nil,
+		[][]string{adapter.NewUserRule(item.TenantID, item.UserID, item.RoleID)}, nil))
+	return nil
+}
@@ -0,0 +1,134 @@
+package bll
+
+import (
+	"context"
+	"time"
+
+	"gin-casbin/internal/app/bll"
+	"gin-casbin/internal/app/model"
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/auth"
+	"gin-casbin/pkg/errors"
+
+	"github.com/google/wire"
+)
+
+var _ bll.ISession = (*Session)(nil)
+
+// SessionSet 注入Session
+var SessionSet = wire.NewSet(wire.Struct(new(Session), "*"), wire.Bind(new(bll.ISession), new(*Session)))
+
+// Session 登录会话(会话在登录时登记，终止后会话下的令牌全部失效)
+type Session struct {
+	Auth                     auth.Auther
+	UserModel                model.IUser
+	UserRoleModel            model.IUserRole
+	RoleModel                model.IRole
+	TenantAdministratorModel model.ITenantAdministrator
+}
+
+// QueryMine 查询用户自己的登录会话
+func (a *Session) QueryMine(ctx context.Context, userID, token string) (schema.Sessions, error) {
+	items, err := a.Auth.QueryUserSessions(ctx, userID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var currentID string
+	if claims, err := a.Auth.ParseClaims(ctx, token); err == nil {
+		currentID = claims.SessionID
+	}
+
+	list := toSessions(items, currentID)
+	if user, err := a.UserModel.Get(ctx, userID); err != nil {
+		return nil, err
+	} else if user != nil {
+		for _, item := range list {
+			item.UserName = user.UserName
+		}
+	}
+	return list, nil
+}
+
+// QueryTenant 查询租户下用户的登录会话(根租户用户可指定租户)
+func (a *Session) QueryTenant(ctx context.Context, tenantID, userID string, params schema.SessionQueryParam) (schema.Sessions, error) {
+	if tenantID != schema.RootTenantID {
+		isAdmin, err := isTenantAdmin(ctx, a.TenantAdministratorModel, a.UserRoleModel, a.RoleModel, tenantID, userID)
+		if err != nil {
+			return nil, err
+		} else if !isAdmin {
+			return nil, errors.ErrNoPerm
+		}
+		params.TenantID = tenantID
+	} else if params.TenantID == "" {
+		params.TenantID = tenantID
+	}
+
+	items, err := a.Auth.QueryTenantSessions(ctx, params.TenantID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	list := toSessions(items, "")
+	if len(list) == 0 {
+		return list, nil
+	}
+
+	userResult, err := a.UserModel.Query(ctx, schema.UserQueryParam{
+		TenantID: params.TenantID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	userNames := make(map[string]string)
+	for _, user := range userResult.Data {
+		userNames[user.ID] = user.UserName
+	}
+	for _, item := range list {
+		item.UserName = userNames[item.UserID]
+	}
+	return list, nil
+}
+
+// Terminate 终止会话(其他用户的会话只能由会话所属租户的管理员或根租户用户终止)
+func (a *Session) Terminate(ctx context.Context, tenantID, userID, id string) error {
+	item, err := a.Auth.GetSession(ctx, id)
+	if err != nil {
+		return errors.WithStack(err)
+	} else if item == nil || (tenantID != schema.RootTenantID && item.TenantID != tenantID) {
+		return errors.ErrNotFound
+	}
+
+	if item.UserID != userID && tenantID != schema.RootTenantID {
+		isAdmin, err := isTenantAdmin(ctx, a.TenantAdministratorModel, a.UserRoleModel, a.RoleModel, item.TenantID, userID)
+		if err != nil {
+			return err
+		} else if !isAdmin {
+			return errors.ErrNoPerm
+		}
+	}
+
+	err = a.Auth.TerminateSession(ctx, id)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func toSessions(items []*auth.Session, currentID string) schema.Sessions {
+	list := make(schema.Sessions, len(items))
+	for i, item := range items {
+		list[i] = &schema.Session{
+			ID:         item.ID,
+			UserID:     item.UserID,
+			TenantID:   item.TenantID,
+			UserAgent:  item.UserAgent,
+			IP:         item.IP,
+			CreatedAt:  time.Unix(item.CreatedAt, 0),
+			LastSeenAt: time.Unix(item.LastSeenAt, 0),
+			ExpiresAt:  time.Unix(item.ExpiresAt, 0),
+			Current:    item.ID == currentID,
+		}
+	}
+	return list
+}
@@ -7,7 +7,9 @@ import (
 	"gin-casbin/internal/app/config"
 	"gin-casbin/internal/app/iutil"
 	"gin-casbin/internal/app/model"
+	"gin-casbin/internal/app/module/adapter"
 	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/auth"
 	"gin-casbin/pkg/errors"
 	"gin-casbin/pkg/util"
 
@@ -22,6 +24,7 @@ var TenantSet = wire.NewSet(wire.Struct(new(Tenant), "*"), wire.Bind(new(bll.ITe
 
 // Tenant 租户
 type Tenant struct {
+	Auth                     auth.Auther
 	Enforcer                 *casbin.SyncedEnforcer
 	AddressModel             model.IAddress
 	TransModel               model.ITrans
@@ -142,7 +145,8 @@ func (a *Tenant) Create(ctx context.Context, item schema.Tenant) (*schema.IDResu
 	if err != nil {
 		return nil, err
 	}
-	LoadCasbinPolicy(ctx, a.Enforcer)
+	ApplyCasbinPolicy(ctx, a.Enforcer, adapter.NewPolicyDelta(nil, nil, nil,
+		newUserPolicies(tenantID, userID, item.Administrator.Status, item.Administrator.UserRoles)))
 	return schema.NewIDResult(tenantID), nil
 }
 
@@ -170,7 +174,13 @@ func (a *Tenant) Delete(ctx context.Context, id string) error {
 		return errors.ErrNotFound
 	}
 
-	return a.TenantModel.Delete(ctx, id)
+	err = a.TenantModel.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	adapter.InvalidateTenant(a.Enforcer, id)
+	return nil
 }
 
 func (a *Tenant) checkUserName(ctx context.Context, item schema.User) error {
@@ -205,5 +215,14 @@ func (a *Tenant) UpdateStatus(ctx context.Context, id string, status int) error
 	if err != nil {
 		return err
 	}
+	adapter.InvalidateTenant(a.Enforcer, id)
+
+	// 停用时撤销租户下已签发的令牌
+	if status == 2 {
+		err = a.Auth.RevokeTenantTokens(ctx, id)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
 	return nil
 }
@@ -2,12 +2,15 @@ package bll
 
 import (
 	"context"
+	"time"
 
 	"gin-casbin/internal/app/bll"
 	"gin-casbin/internal/app/config"
 	"gin-casbin/internal/app/iutil"
 	"gin-casbin/internal/app/model"
+	"gin-casbin/internal/app/module/adapter"
 	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/auth"
 	"gin-casbin/pkg/errors"
 	"gin-casbin/pkg/util"
 
@@ -23,6 +26,7 @@ var UserSet = wire.NewSet(wire.Struct(new(User), "*"), wire.Bind(new(bll.IUser),
 
 // User 用户管理
 type User struct {
+	Auth            auth.Auther
 	Enforcer        *casbin.SyncedEnforcer
 	TransModel      model.ITrans
 	UserModel       model.IUser
@@ -117,7 +121,7 @@ func (a *User) Get(ctx context.Context, id string, opts ...schema.UserQueryOptio
 	}
 
 	// first version only use 2 roles: admin & non-admin
-	for _, userRole := range item.UserRoles {
+	for _, userRole := range item.UserRoles.ValidAt(time.Now()) {
 		if userRole.RoleID == config.C.TenantOwnerRole.ID {
 			item.IsAdmin = true
 		}
@@ -132,6 +136,11 @@ func (a *User) Create(ctx context.Context, item schema.User) (*schema.IDResult,
 		return nil, err
 	}
 
+	err = a.checkUserRoles(ctx, item.TenantID, item.UserRoles)
+	if err != nil {
+		return nil, err
+	}
+
 	item.Password = util.SHA1HashString(item.Password)
 	item.ID = iutil.NewID()
 	err = ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
@@ -160,7 +169,8 @@ func (a *User) Create(ctx context.Context, item schema.User) (*schema.IDResult,
 		return nil, err
 	}
 
-	LoadCasbinPolicy(ctx, a.Enforcer)
+	ApplyCasbinPolicy(ctx, a.Enforcer, adapter.NewPolicyDelta(nil, nil, nil,
+		newUserPolicies(item.TenantID, item.ID, item.Status, item.UserRoles)))
 	return schema.NewIDResult(item.ID), nil
 }
 
@@ -194,17 +204,27 @@ func (a *User) Update(ctx context.Context, id string, item schema.User) error {
 		}
 	}
 
+	addUserRoles, delUserRoles := a.compareUserRoles(ctx, oldItem.UserRoles, item.UserRoles)
+	err = a.checkUserRoles(ctx, oldItem.TenantID, addUserRoles)
+	if err != nil {
+		return err
+	}
+
 	if item.Password != "" {
 		item.Password = util.SHA1HashString(item.Password)
 	} else {
 		item.Password = oldItem.Password
 	}
 
+	// 未提交状态时保持原状态(零值不会更新到数据库)
+	if item.Status == 0 {
+		item.Status = oldItem.Status
+	}
+
 	item.ID = oldItem.ID
 	item.Creator = oldItem.Creator
 	item.CreatedAt = oldItem.CreatedAt
 	err = ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
-		addUserRoles, delUserRoles := a.compareUserRoles(ctx, oldItem.UserRoles, item.UserRoles)
 		for _, rmitem := range addUserRoles {
 			rmitem.ID = iutil.NewID()
 			rmitem.UserID = id
@@ -227,7 +247,9 @@ func (a *User) Update(ctx context.Context, id string, item schema.User) error {
 		return err
 	}
 
-	LoadCasbinPolicy(ctx, a.Enforcer)
+	ApplyCasbinPolicy(ctx, a.Enforcer, adapter.NewPolicyDelta(nil, nil,
+		newUserPolicies(oldItem.TenantID, id, oldItem.Status, oldItem.UserRoles),
+		newUserPolicies(oldItem.TenantID, id, item.Status, item.UserRoles)))
 	return nil
 }
 
@@ -251,6 +273,8 @@ func (a *User) UpdateProfile(ctx context.Context, id string, item schema.User) e
 		item.Password = oldItem.Password
 	}
 
+	// 个人资料不能修改用户状态和角色授权，无需更新策略
+	item.Status = oldItem.Status
 	item.ID = oldItem.ID
 	item.Creator = oldItem.Creator
 	item.CreatedAt = oldItem.CreatedAt
@@ -260,8 +284,29 @@ func (a *User) UpdateProfile(ctx context.Context, id string, item schema.User) e
 	if err != nil {
 		return err
 	}
+	return nil
+}
+
+// 检查角色是否可以授权给租户下的用户(全局角色或租户自定义角色，平台管理员角色只能授权给根租户的用户)
+func (a *User) checkUserRoles(ctx context.Context, tenantID string, userRoles schema.UserRoles) error {
+	for _, item := range userRoles {
+		if item.ValidFrom != nil && item.ValidUntil != nil && !item.ValidUntil.After(*item.ValidFrom) {
+			return errors.New400Response("ErrInvalidRoleValidity")
+		}
+	}
 
-	LoadCasbinPolicy(ctx, a.Enforcer)
+	for _, roleID := range userRoles.ToRoleIDs() {
+		if schema.CheckIsPlatformAdminRole(roleID) && tenantID != schema.RootTenantID {
+			return errors.New400Response("ErrInvalidRole")
+		}
+
+		role, err := a.RoleModel.Get(ctx, roleID)
+		if err != nil {
+			return err
+		} else if role == nil || !role.IsVisibleTo(tenantID) {
+			return errors.New400Response("ErrInvalidRole")
+		}
+	}
 	return nil
 }
 
@@ -269,8 +314,9 @@ func (a *User) compareUserRoles(ctx context.Context, oldUserRoles, newUserRoles
 	mOldUserRoles := oldUserRoles.ToMap()
 	mNewUserRoles := newUserRoles.ToMap()
 
+	// 有效期变更的授权删除后重新创建
 	for k, item := range mNewUserRoles {
-		if _, ok := mOldUserRoles[k]; ok {
+		if oldItem, ok := mOldUserRoles[k]; ok && oldItem.EqualValidity(item) {
 			delete(mOldUserRoles, k)
 			continue
 		}
@@ -285,11 +331,9 @@ func (a *User) compareUserRoles(ctx context.Context, oldUserRoles, newUserRoles
 
 // Delete 删除数据
 func (a *User) Delete(ctx context.Context, id string) error {
-	oldItem, err := a.UserModel.Get(ctx, id)
+	oldItem, err := a.Get(ctx, id)
 	if err != nil {
 		return err
-	} else if oldItem == nil {
-		return errors.ErrNotFound
 	}
 
 	err = ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
@@ -304,25 +348,98 @@ func (a *User) Delete(ctx context.Context, id string) error {
 		return err
 	}
 
-	LoadCasbinPolicy(ctx, a.Enforcer)
+	ApplyCasbinPolicy(ctx, a.Enforcer, adapter.NewPolicyDelta(nil, nil,
+		newUserPolicies(oldItem.TenantID, id, oldItem.Status, oldItem.UserRoles), nil))
 	return nil
 }
 
 // UpdateStatus 更新状态
 func (a *User) UpdateStatus(ctx context.Context, id string, status int) error {
-	oldItem, err := a.UserModel.Get(ctx, id)
+	oldItem, err := a.Get(ctx, id)
 	if err != nil {
 		return err
-	} else if oldItem == nil {
-		return errors.ErrNotFound
 	}
-	oldItem.Status = status
 
 	err = a.UserModel.UpdateStatus(ctx, id, status)
 	if err != nil {
 		return err
 	}
 
-	LoadCasbinPolicy(ctx, a.Enforcer)
+	ApplyCasbinPolicy(ctx, a.Enforcer, adapter.NewPolicyDelta(nil, nil,
+		newUserPolicies(oldItem.TenantID, id, oldItem.Status, oldItem.UserRoles),
+		newUserPolicies(oldItem.TenantID, id, status, oldItem.UserRoles)))
+
+	// 停用时撤销用户已签发的令牌
+	if status == 2 {
+		err = a.Auth.RevokeUserTokens(ctx, id)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
 	return nil
 }
+
+// QueryRoleGrants 查询有有效期的角色授权(根租户查询所有租户的授权)
+func (a *User) QueryRoleGrants(ctx context.Context, tenantID string, params schema.UserRoleGrantQueryParam) ([]*schema.UserRoleGrant, error) {
+	result, err := a.UserRoleModel.Query(ctx, schema.UserRoleQueryParam{
+		UserID:    params.UserID,
+		TimeBound: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := []*schema.UserRoleGrant{}
+	if len(result.Data) == 0 {
+		return list, nil
+	}
+
+	roleResult, err := a.RoleModel.Query(ctx, schema.RoleQueryParam{
+		IDs: result.Data.ToRoleIDs(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	mRoles := roleResult.Data.ToMap()
+
+	userParams := schema.UserQueryParam{
+		IDs: result.Data.ToUserIDs(),
+	}
+	if tenantID != schema.RootTenantID {
+		userParams.TenantID = tenantID
+	}
+	userResult, err := a.UserModel.Query(ctx, userParams)
+	if err != nil {
+		return nil, err
+	}
+	mUsers := userResult.Data.ToMap()
+
+	now := time.Now()
+	for _, ur := range result.Data {
+		status := ur.GetStatus(now)
+		if params.Status != "" && status != params.Status {
+			continue
+		}
+
+		user, ok := mUsers[ur.UserID]
+		if !ok {
+			continue
+		}
+
+		grant := &schema.UserRoleGrant{
+			ID:         ur.ID,
+			UserID:     ur.UserID,
+			UserName:   user.UserName,
+			TenantID:   user.TenantID,
+			RoleID:     ur.RoleID,
+			ValidFrom:  ur.ValidFrom,
+			ValidUntil: ur.ValidUntil,
+			Status:     status,
+		}
+		if role, ok := mRoles[ur.RoleID]; ok {
+			grant.RoleName = role.Name
+		}
+		list = append(list, grant)
+	}
+	return list, nil
+}
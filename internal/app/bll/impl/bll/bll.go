@@ -5,7 +5,13 @@ import "github.com/google/wire"
 // BllSet bll注入
 var BllSet = wire.NewSet(
 	LoginSet,
+	MenuSet,
 	RoleSet,
 	UserSet,
 	TenantSet,
+	PolicySet,
+	PolicyChangeSetSet,
+	RoleElevationSet,
+	AccessReviewSet,
+	SessionSet,
 )
@@ -115,6 +115,70 @@ type Monitor struct {
 	ConfigDir string
 }
 
+// Casbin
+type Casbin struct {
+	Enable           bool
+	Debug            bool
+	Model            string
+	AutoLoad         bool
+	AutoLoadInternal int
+	LazyLoad         bool
+	Watcher          string
+	WatcherChannel   string
+	WatcherRedisDB   int
+	DecisionLog      bool
+	DecisionCache    bool
+	DecisionCacheMax int
+	ModelCanary      string
+	ModelWatch       bool
+	ModelWatchTime   int
+	RoleSchedule     int
+}
+
+// Root 配置文件中的超级用户(紧急访问账户，默认禁用)
+type Root struct {
+	Enable   bool
+	UserName string
+	Password string
+	RealName string
+}
+
+// PlatformAdminRole 平台管理员角色(授权给根租户下的用户)
+type PlatformAdminRole struct {
+	ID   string
+	Name string
+}
+
+// Menu 菜单数据(启动时菜单表为空则从数据文件初始化)
+type Menu struct {
+	Enable bool
+	Data   string
+}
+
+// JWTAuth 用户认证
+type JWTAuth struct {
+	SigningMethod  string
+	SigningKey     string
+	Issuer         string
+	Audience       string
+	Expired        int
+	RefreshExpired int
+	Store          string
+	FilePath       string
+	RedisDB        int
+	RedisPrefix    string
+	Keys           []JWTKey
+}
+
+// JWTKey 非对称签名密钥(按生效时间轮换)
+type JWTKey struct {
+	ID        string
+	Method    string // 签名方式(为空使用JWTAuth.SigningMethod)
+	File      string // PEM文件(私钥或只用于验证的公钥)
+	NotBefore string // 开始用于签名的时间(RFC3339，为空表示立即)
+	NotAfter  string // 停止用于验证的时间(RFC3339，为空表示不限制)
+}
+
 // Captcha
 type Captcha struct {
 	Store       string
@@ -232,6 +296,12 @@ type Config struct {
 	Monitor     Monitor
 	BasicAuth   BasicAuth
 	Authorizer  Authorizer
+	Casbin      Casbin
+	Root        Root
+
+	PlatformAdminRole PlatformAdminRole
+	Menu              Menu
+	JWTAuth           JWTAuth
 
 	Log          Log
 	LogGormHook  LogGormHook
@@ -261,6 +331,8 @@ type BasicAuth struct {
 }
 
 type Redis struct {
+	Addr        string
+	Password    string
 	Host        string
 	Port        int
 	Auth        string
@@ -24,6 +24,7 @@ const (
 	ReqBodyKey       = prefix + "/req-body"
 	ResBodyKey       = prefix + "/res-body"
 	LoggerReqBodyKey = prefix + "/logger-req-body"
+	DecisionIDKey    = prefix + "/decision-id"
 )
 
 // GetToken 获取用户令牌
@@ -67,6 +68,16 @@ func SetIsAdmin(c *gin.Context, isAdmin bool) {
 	c.Set(IsAdminIDKey, isAdmin)
 }
 
+// GetDecisionID 获取策略决策ID
+func GetDecisionID(c *gin.Context) string {
+	return c.GetString(DecisionIDKey)
+}
+
+// SetDecisionID 设定策略决策ID
+func SetDecisionID(c *gin.Context, decisionID string) {
+	c.Set(DecisionIDKey, decisionID)
+}
+
 // GetBody Get request body
 func GetBody(c *gin.Context) []byte {
 	if v, ok := c.Get(ReqBodyKey); ok {
@@ -167,8 +178,9 @@ func ResError(c *gin.Context, err error, status ...int) {
 			Other: "Error",
 		})
 	eitem := schema.ErrorItem{
-		Code:    res.Code,
-		Message: text,
+		Code:       res.Code,
+		Message:    text,
+		DecisionID: GetDecisionID(c),
 	}
 	ResJSON(c, res.StatusCode, schema.ErrorResult{Error: eitem})
 }
@@ -1,6 +1,9 @@
 package injector
 
 import (
+	"errors"
+	"time"
+
 	"gin-casbin/internal/app/config"
 	"gin-casbin/pkg/auth"
 	"gin-casbin/pkg/auth/jwtauth"
@@ -16,24 +19,39 @@ func InitAuth() (auth.Auther, func(), error) {
 
 	var opts []jwtauth.Option
 	opts = append(opts, jwtauth.SetExpired(cfg.Expired))
-	opts = append(opts, jwtauth.SetSigningKey([]byte(cfg.SigningKey)))
-	opts = append(opts, jwtauth.SetKeyfunc(func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, auth.ErrInvalidToken
-		}
-		return []byte(cfg.SigningKey), nil
-	}))
+	opts = append(opts, jwtauth.SetIssuer(cfg.Issuer))
+	opts = append(opts, jwtauth.SetAudience(cfg.Audience))
+	if cfg.RefreshExpired > 0 {
+		opts = append(opts, jwtauth.SetRefreshExpired(cfg.RefreshExpired))
+	}
 
-	var method jwt.SigningMethod
 	switch cfg.SigningMethod {
-	case "HS256":
-		method = jwt.SigningMethodHS256
-	case "HS384":
-		method = jwt.SigningMethodHS384
+	case "HS256", "HS384", "HS512", "":
+		opts = append(opts, jwtauth.SetSigningKey([]byte(cfg.SigningKey)))
+		opts = append(opts, jwtauth.SetKeyfunc(func(t *jwt.Token) (interface{}, error) {
+			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, auth.ErrInvalidToken
+			}
+			return []byte(cfg.SigningKey), nil
+		}))
+
+		var method jwt.SigningMethod
+		switch cfg.SigningMethod {
+		case "HS256":
+			method = jwt.SigningMethodHS256
+		case "HS384":
+			method = jwt.SigningMethodHS384
+		default:
+			method = jwt.SigningMethodHS512
+		}
+		opts = append(opts, jwtauth.SetSigningMethod(method))
 	default:
-		method = jwt.SigningMethodHS512
+		keySet, err := loadKeySet(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, jwtauth.SetKeySet(keySet))
 	}
-	opts = append(opts, jwtauth.SetSigningMethod(method))
 
 	var store jwtauth.Storer
 	switch cfg.Store {
@@ -59,3 +77,37 @@ func InitAuth() (auth.Auther, func(), error) {
 	}
 	return auth, cleanFunc, nil
 }
+
+// 加载非对称签名密钥(按生效时间轮换签名密钥)
+func loadKeySet(cfg config.JWTAuth) (*jwtauth.KeySet, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, errors.New("no signing keys configured for " + cfg.SigningMethod)
+	}
+
+	keys := make([]*jwtauth.Key, len(cfg.Keys))
+	for i, item := range cfg.Keys {
+		method := item.Method
+		if method == "" {
+			method = cfg.SigningMethod
+		}
+
+		key, err := jwtauth.LoadKeyFile(item.ID, method, item.File)
+		if err != nil {
+			return nil, err
+		}
+		if v := item.NotBefore; v != "" {
+			key.NotBefore, err = time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if v := item.NotAfter; v != "" {
+			key.NotAfter, err = time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, err
+			}
+		}
+		keys[i] = key
+	}
+	return jwtauth.NewKeySet(keys...)
+}
@@ -1,16 +1,26 @@
 package injector
 
 import (
+	"context"
+	"os"
+	"sync"
 	"time"
 
 	"gin-casbin/internal/app/config"
+	"gin-casbin/internal/app/module/adapter"
+	"gin-casbin/internal/app/module/policytest"
+	"gin-casbin/pkg/errors"
+	"gin-casbin/pkg/logger"
+	"gin-casbin/pkg/watcher"
+	"gin-casbin/pkg/watcher/postgres"
+	"gin-casbin/pkg/watcher/redis"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/casbin/casbin/v2/persist"
 )
 
 // InitCasbin
-func InitCasbin(adapter persist.Adapter) (*casbin.SyncedEnforcer, func(), error) {
+func InitCasbin(a persist.Adapter) (*casbin.SyncedEnforcer, func(), error) {
 	cfg := config.C.Casbin
 	if cfg.Model == "" {
 		return new(casbin.SyncedEnforcer), nil, nil
@@ -22,19 +32,225 @@ func InitCasbin(adapter persist.Adapter) (*casbin.SyncedEnforcer, func(), error)
 	}
 	e.EnableLog(cfg.Debug)
 
-	err = e.InitWithModelAndAdapter(e.GetModel(), adapter)
+	// 初始化会重置enforcer的函数表和角色管理器，之后再注册自定义函数
+	err = e.InitWithModelAndAdapter(e.GetModel(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	adapter.RegisterConditionFunction(e)
+	adapter.RegisterRoleFunction(e)
+	e.SetAdapter(a)
+
+	if ca, ok := a.(*adapter.CasbinAdapter); ok && cfg.DecisionCache {
+		ca.SetDecisionCache(adapter.NewDecisionCache(cfg.DecisionCacheMax))
+	}
+
+	// 延迟加载模式下启动时不加载任何租户，租户策略在首次请求时加载
+	if cfg.LazyLoad {
+		err = e.LoadFilteredPolicy(&adapter.CasbinFilter{})
+	} else {
+		err = e.LoadPolicy()
+	}
 	if err != nil {
 		return nil, nil, err
 	}
 	e.EnableEnforce(cfg.Enable)
 
-	cleanFunc := func() {}
+	setCasbinEnforcer(e)
+	cleanFuncs := []func(){func() {
+		setCasbinEnforcer(nil)
+	}}
+	if cfg.ModelWatch {
+		stop := startWatchModel(e, cfg.Model, time.Duration(cfg.ModelWatchTime)*time.Second)
+		cleanFuncs = append(cleanFuncs, func() {
+			close(stop)
+		})
+	}
+	if cfg.RoleSchedule > 0 {
+		stop := startRoleSchedule(e, time.Duration(cfg.RoleSchedule)*time.Second)
+		cleanFuncs = append(cleanFuncs, func() {
+			close(stop)
+		})
+	}
 	if cfg.AutoLoad {
-		e.StartAutoLoadPolicy(time.Duration(cfg.AutoLoadInternal) * time.Second)
-		cleanFunc = func() {
-			e.StopAutoLoadPolicy()
+		stop := startAutoReloadPolicy(e, time.Duration(cfg.AutoLoadInternal)*time.Second)
+		cleanFuncs = append(cleanFuncs, func() {
+			close(stop)
+		})
+	}
+
+	w, err := initCasbinWatcher()
+	if err != nil {
+		return nil, nil, err
+	} else if w != nil {
+		if ca, ok := a.(*adapter.CasbinAdapter); ok {
+			if err := ca.SetWatcher(e, w); err != nil {
+				w.Close()
+				return nil, nil, err
+			}
 		}
+		cleanFuncs = append(cleanFuncs, w.Close)
 	}
 
+	cleanFunc := func() {
+		for _, fn := range cleanFuncs {
+			fn()
+		}
+	}
 	return e, cleanFunc, nil
 }
+
+// 初始化策略变更监听
+func initCasbinWatcher() (*watcher.Watcher, error) {
+	cfg := config.C.Casbin
+
+	channel := cfg.WatcherChannel
+	if channel == "" {
+		channel = "casbin"
+	}
+
+	switch cfg.Watcher {
+	case "redis":
+		rcfg := config.C.Redis
+		return redis.NewWatcher(&redis.Config{
+			Addr:     rcfg.Addr,
+			Password: rcfg.Password,
+			DB:       cfg.WatcherRedisDB,
+			Channel:  channel,
+		})
+	case "postgres":
+		return postgres.NewWatcher(&postgres.Config{
+			DSN:     config.C.Postgres.DSN(),
+			Channel: channel,
+		})
+	}
+	return nil, nil
+}
+
+// 定时重新加载策略(过滤加载模式下只重新加载已加载的租户)
+func startAutoReloadPolicy(e *casbin.SyncedEnforcer, d time.Duration) chan struct{} {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				_ = adapter.ReloadPolicy(e)
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return stop
+}
+
+// 定时同步有有效期的角色授权(只增删到达生效或失效时间的授权，不重新加载策略)
+func startRoleSchedule(e *casbin.SyncedEnforcer, d time.Duration) chan struct{} {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := adapter.SyncTimeBoundPolicy(e); err != nil {
+					logger.Errorf(context.Background(), "Sync time-bound role policy error: %s", err.Error())
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return stop
+}
+
+// 当前使用的enforcer(收到SIGHUP时重新加载模型)
+var casbinEnforcer struct {
+	sync.Mutex
+	e *casbin.SyncedEnforcer
+}
+
+func setCasbinEnforcer(e *casbin.SyncedEnforcer) {
+	casbinEnforcer.Lock()
+	defer casbinEnforcer.Unlock()
+	casbinEnforcer.e = e
+}
+
+// ReloadCasbinModel 重新加载casbin模型文件，校验失败时保留原模型
+func ReloadCasbinModel(ctx context.Context) error {
+	casbinEnforcer.Lock()
+	e := casbinEnforcer.e
+	casbinEnforcer.Unlock()
+
+	if e == nil {
+		return nil
+	}
+	return reloadCasbinModel(ctx, e, config.C.Casbin.Model)
+}
+
+func reloadCasbinModel(ctx context.Context, e *casbin.SyncedEnforcer, modelFile string) error {
+	err := adapter.ReloadModel(e, modelFile, validateCasbinModel)
+	if err != nil {
+		logger.Errorf(ctx, "Reload casbin model error, keep the current model: %s", err.Error())
+		return err
+	}
+	logger.Printf(ctx, "Reload casbin model: %s", modelFile)
+	return nil
+}
+
+// 使用探测请求校验新模型(模型中的匹配器在执行时才编译，因此至少执行一次请求)
+func validateCasbinModel(e *casbin.SyncedEnforcer) error {
+	_, err := e.Enforce("", "", "/", "GET", adapter.Attributes{})
+	if err != nil {
+		return err
+	}
+
+	file := config.C.Casbin.ModelCanary
+	if file == "" {
+		return nil
+	}
+
+	suite, err := policytest.LoadPolicySuite(file)
+	if err != nil {
+		return err
+	}
+	if failures := policytest.RunPolicySuite(e, suite); len(failures) > 0 {
+		for _, f := range failures {
+			logger.Errorf(context.Background(), "Casbin model canary failed: %s", f.String())
+		}
+		return errors.Errorf("%d of %d canary requests failed", len(failures), len(suite.Cases))
+	}
+	return nil
+}
+
+// 定时检查模型文件的修改时间，变更后重新加载模型
+func startWatchModel(e *casbin.SyncedEnforcer, modelFile string, d time.Duration) chan struct{} {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+
+		var modTime time.Time
+		if fi, err := os.Stat(modelFile); err == nil {
+			modTime = fi.ModTime()
+		}
+		for {
+			select {
+			case <-ticker.C:
+				fi, err := os.Stat(modelFile)
+				if err != nil || fi.ModTime().Equal(modTime) {
+					continue
+				}
+				// 校验失败时同样记录修改时间，避免重复加载同一个错误的文件
+				modTime = fi.ModTime()
+				_ = reloadCasbinModel(context.Background(), e, modelFile)
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return stop
+}
@@ -23,6 +23,16 @@ func InitGormDB() (*gorm.DB, func(), error) {
 		if err != nil {
 			return nil, cleanFunc, err
 		}
+
+		err = igorm.MigratePlatformAdminRole(db)
+		if err != nil {
+			return nil, cleanFunc, err
+		}
+
+		err = igorm.MigrateRoleTenant(db)
+		if err != nil {
+			return nil, cleanFunc, err
+		}
 	}
 
 	return db, cleanFunc, nil
@@ -1,6 +1,7 @@
 package injector
 
 import (
+	"gin-casbin/internal/app/bll"
 	"gin-casbin/pkg/auth"
 
 	"github.com/casbin/casbin/v2"
@@ -16,4 +17,5 @@ type Injector struct {
 	Engine         *gin.Engine
 	Auth           auth.Auther
 	CasbinEnforcer *casbin.SyncedEnforcer
+	MenuBll        bll.IMenu
 }
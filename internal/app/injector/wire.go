@@ -7,10 +7,13 @@ import (
 
 	// "gin-casbin/internal/app/api/mock"
 
+	"gin-casbin/internal/app/api"
 	"gin-casbin/internal/app/module/adapter"
+	"gin-casbin/internal/app/router"
 
 	"github.com/google/wire"
 
+	bllImpl "gin-casbin/internal/app/bll/impl/bll"
 	gormModel "gin-casbin/internal/app/model/impl/gorm/model"
 )
 
@@ -19,6 +22,9 @@ func BuildInjector() (*Injector, func(), error) {
 	wire.Build(
 		InitGormDB,
 		gormModel.ModelSet,
+		bllImpl.BllSet,
+		api.APISet,
+		router.RouterSet,
 		InitAuth,
 		InitCasbin,
 		InitGinEngine,
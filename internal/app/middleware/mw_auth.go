@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"gin-casbin/internal/app/ginplus"
+	"gin-casbin/internal/app/icontext"
+	"gin-casbin/pkg/auth"
+	"gin-casbin/pkg/errors"
+	"gin-casbin/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserAuthMiddleware 用户授权中间件(解析访问令牌，将用户、租户和管理标识写入上下文)
+func UserAuthMiddleware(a auth.Auther, skippers ...SkipperFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if SkipHandler(c, skippers...) {
+			c.Next()
+			return
+		}
+
+		token := ginplus.GetToken(c)
+		if token == "" {
+			ginplus.ResError(c, errors.ErrInvalidToken)
+			return
+		}
+
+		claims, err := a.ParseClaims(c.Request.Context(), token)
+		if err != nil {
+			if err == auth.ErrInvalidToken {
+				ginplus.ResError(c, errors.ErrInvalidToken)
+				return
+			}
+			ginplus.ResError(c, errors.WithStack(err))
+			return
+		}
+
+		ginplus.SetUserID(c, claims.UserID)
+		ginplus.SetTenantID(c, claims.TenantID)
+		ginplus.SetIsAdmin(c, claims.IsAdmin)
+
+		ctx := icontext.NewUserID(c.Request.Context(), claims.UserID, claims.TenantID)
+		ctx = logger.NewUserIDContext(ctx, claims.UserID, claims.TenantID)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -1,17 +1,26 @@
 package middleware
 
 import (
+	"context"
+
 	"gin-casbin/internal/app/config"
 	"gin-casbin/internal/app/ginplus"
+	"gin-casbin/internal/app/module/adapter"
 	"gin-casbin/pkg/errors"
+	"gin-casbin/pkg/logger"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 )
 
-// CasbinMiddleware casbin中间件
-func CasbinMiddleware(enforcer *casbin.SyncedEnforcer, skippers ...SkipperFunc) gin.HandlerFunc {
+// DecisionRecorder 记录拒绝的策略决策，返回决策ID
+type DecisionRecorder func(ctx context.Context, userID, tenantID, path, method string, attrs map[string]interface{}) (string, error)
+
+// AttributeLoader 加载策略条件使用的请求属性和资源属性(如按路由参数加载的目标资源)
+type AttributeLoader func(c *gin.Context) (map[string]interface{}, error)
+
+// CasbinMiddleware casbin中间件(recorder为空时不记录决策，loader为空时只提供用户和租户属性)
+func CasbinMiddleware(enforcer *casbin.SyncedEnforcer, recorder DecisionRecorder, loader AttributeLoader, skippers ...SkipperFunc) gin.HandlerFunc {
 	cfg := config.C.Casbin
 	if !cfg.Enable {
 		return EmptyMiddleware()
@@ -27,12 +36,30 @@ func CasbinMiddleware(enforcer *casbin.SyncedEnforcer, skippers ...SkipperFunc)
 		m := c.Request.Method
 		t := ginplus.GetTenantID(c)
 		u := ginplus.GetUserID(c)
-		logrus.Printf("p:%s, m:%s, u:%s, t:%t", p, m, u, t)
 
-		if b, err := enforcer.Enforce(u, t, p, m); err != nil {
+		// 首次访问的租户按需加载策略
+		if err := adapter.LoadTenantPolicy(enforcer, t); err != nil {
+			ginplus.ResError(c, errors.WithStack(err))
+			return
+		}
+
+		r := &casbinRequest{c: c, loader: loader, u: u, t: t, p: p, m: m}
+		if b, err := r.enforce(enforcer); err != nil {
 			ginplus.ResError(c, errors.WithStack(err))
 			return
 		} else if !b {
+			if recorder != nil {
+				ctx := c.Request.Context()
+				attrs, err := r.attributes()
+				if err == nil {
+					var id string
+					id, err = recorder(ctx, u, t, p, m, attrs)
+					ginplus.SetDecisionID(c, id)
+				}
+				if err != nil {
+					logger.Errorf(ctx, "Record policy decision error: %s", err.Error())
+				}
+			}
 			ginplus.ResError(c, errors.ErrNoPerm)
 			return
 		}
@@ -40,3 +67,75 @@ func CasbinMiddleware(enforcer *casbin.SyncedEnforcer, skippers ...SkipperFunc)
 		c.Next()
 	}
 }
+
+type casbinRequest struct {
+	c          *gin.Context
+	loader     AttributeLoader
+	u, t, p, m string
+	attrs      adapter.Attributes
+}
+
+// 加载策略条件属性(只加载一次)
+func (r *casbinRequest) attributes() (adapter.Attributes, error) {
+	if r.attrs != nil {
+		return r.attrs, nil
+	}
+
+	attrs := r.baseAttributes()
+	if r.loader != nil {
+		m, err := r.loader(r.c)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range m {
+			attrs[k] = v
+		}
+	}
+	r.attrs = attrs
+	return attrs, nil
+}
+
+// 用户和租户属性(不需要加载)
+func (r *casbinRequest) baseAttributes() adapter.Attributes {
+	return adapter.Attributes{
+		adapter.AttrUserID:   r.u,
+		adapter.AttrTenantID: r.t,
+	}
+}
+
+// 执行策略检查，启用决策缓存时按路由模板缓存不依赖条件的决策
+// 用户的角色策略都不带条件时决策不依赖请求属性，不加载属性
+func (r *casbinRequest) enforce(enforcer *casbin.SyncedEnforcer) (bool, error) {
+	cache := adapter.GetDecisionCache(enforcer)
+	tpl := r.c.FullPath()
+	if cache != nil && tpl != "" {
+		if b, ok := cache.Get(r.t, r.u, tpl, r.m); ok {
+			return b, nil
+		}
+	}
+
+	var version uint64
+	if cache != nil {
+		version = cache.Version()
+	}
+
+	cacheable := adapter.IsCacheable(enforcer, r.u, r.t)
+	attrs := r.baseAttributes()
+	if !cacheable {
+		var err error
+		attrs, err = r.attributes()
+		if err != nil {
+			return false, err
+		}
+	}
+
+	b, err := enforcer.Enforce(r.u, r.t, r.p, r.m, attrs)
+	if err != nil {
+		return false, err
+	}
+
+	if cache != nil && tpl != "" && cacheable {
+		cache.Set(r.t, r.u, tpl, r.m, b, version)
+	}
+	return b, nil
+}
@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"context"
+	"time"
+
+	"gin-casbin/internal/app/schema"
+
+	"github.com/jinzhu/gorm"
+)
+
+// GetAccessReviewDB 获取访问审查活动存储
+func GetAccessReviewDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return GetDBWithModel(ctx, defDB, new(AccessReview))
+}
+
+// SchemaAccessReview 访问审查活动对象
+type SchemaAccessReview schema.AccessReview
+
+// ToAccessReview 转换为访问审查活动实体
+func (a SchemaAccessReview) ToAccessReview() *AccessReview {
+	item := &AccessReview{
+		Name:        a.Name,
+		Description: a.Description,
+		DueAt:       a.DueAt,
+		Status:      a.Status,
+		ItemCount:   a.ItemCount,
+		KeepCount:   a.KeepCount,
+		RevokeCount: a.RevokeCount,
+		ClosedBy:    a.ClosedBy,
+		ClosedAt:    a.ClosedAt,
+	}
+	item.ID = a.ID
+	item.Creator = a.Creator
+	return item
+}
+
+// AccessReview 访问审查活动实体
+type AccessReview struct {
+	Model
+	Name        string     `gorm:"column:name;size:128;default:'';not null;"` // 活动名称
+	Description string     `gorm:"column:description;size:1024;"`             // 描述
+	DueAt       *time.Time `gorm:"column:due_at;"`                            // 截止时间
+	Status      int        `gorm:"column:status;index;default:0;not null;"`   // 状态(1:进行中 2:已关闭)
+	ItemCount   int        `gorm:"column:item_count;default:0;not null;"`     // 授权总数
+	KeepCount   int        `gorm:"column:keep_count;default:0;not null;"`     // 保留数
+	RevokeCount int        `gorm:"column:revoke_count;default:0;not null;"`   // 撤销数
+	ClosedBy    string     `gorm:"column:closed_by;size:36;default:'';"`      // 关闭者
+	ClosedAt    *time.Time `gorm:"column:closed_at;"`                         // 关闭时间
+}
+
+// TableName 表名
+func (a AccessReview) TableName() string {
+	return a.Model.TableName("access_review")
+}
+
+// ToSchemaAccessReview 转换为访问审查活动对象
+func (a AccessReview) ToSchemaAccessReview() *schema.AccessReview {
+	item := &schema.AccessReview{
+		ID:          a.ID,
+		Name:        a.Name,
+		Description: a.Description,
+		DueAt:       a.DueAt,
+		Status:      a.Status,
+		ItemCount:   a.ItemCount,
+		KeepCount:   a.KeepCount,
+		RevokeCount: a.RevokeCount,
+		ClosedBy:    a.ClosedBy,
+		ClosedAt:    a.ClosedAt,
+		Creator:     a.Creator,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+	}
+	return item
+}
+
+// AccessReviews 访问审查活动实体列表
+type AccessReviews []*AccessReview
+
+// ToSchemaAccessReviews 转换为访问审查活动对象列表
+func (a AccessReviews) ToSchemaAccessReviews() []*schema.AccessReview {
+	list := make([]*schema.AccessReview, len(a))
+	for i, item := range a {
+		list[i] = item.ToSchemaAccessReview()
+	}
+	return list
+}
@@ -0,0 +1,99 @@
+package entity
+
+import (
+	"context"
+	"time"
+
+	"gin-casbin/internal/app/schema"
+
+	"github.com/jinzhu/gorm"
+)
+
+// GetAccessReviewItemDB 获取访问审查项存储
+func GetAccessReviewItemDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return GetDBWithModel(ctx, defDB, new(AccessReviewItem))
+}
+
+// SchemaAccessReviewItem 访问审查项对象
+type SchemaAccessReviewItem schema.AccessReviewItem
+
+// ToAccessReviewItem 转换为访问审查项实体
+func (a SchemaAccessReviewItem) ToAccessReviewItem() *AccessReviewItem {
+	item := &AccessReviewItem{
+		ReviewID:   a.ReviewID,
+		TenantID:   a.TenantID,
+		UserID:     a.UserID,
+		UserName:   a.UserName,
+		RoleID:     a.RoleID,
+		RoleName:   a.RoleName,
+		UserRoleID: a.UserRoleID,
+		ValidFrom:  a.ValidFrom,
+		ValidUntil: a.ValidUntil,
+		Decision:   a.Decision,
+		Reviewer:   a.Reviewer,
+		Comment:    a.Comment,
+		ReviewedAt: a.ReviewedAt,
+		Applied:    a.Applied,
+	}
+	item.ID = a.ID
+	return item
+}
+
+// AccessReviewItem 访问审查项实体
+type AccessReviewItem struct {
+	Model
+	ReviewID   string     `gorm:"column:review_id;size:36;index;default:'';not null;"` // 访问审查ID
+	TenantID   string     `gorm:"column:tenant_id;size:36;index;default:'';not null;"` // 租户ID
+	UserID     string     `gorm:"column:user_id;size:36;index;default:'';not null;"`   // 用户ID
+	UserName   string     `gorm:"column:user_name;size:64;default:'';"`                // 用户名
+	RoleID     string     `gorm:"column:role_id;size:36;default:'';not null;"`         // 角色ID
+	RoleName   string     `gorm:"column:role_name;size:100;default:'';"`               // 角色名称
+	UserRoleID string     `gorm:"column:user_role_id;size:36;default:'';"`             // 用户角色ID
+	ValidFrom  *time.Time `gorm:"column:valid_from;"`                                  // 授权生效时间
+	ValidUntil *time.Time `gorm:"column:valid_until;"`                                 // 授权失效时间
+	Decision   int        `gorm:"column:decision;index;default:0;not null;"`           // 审查结论(0:待审查 1:保留 2:撤销)
+	Reviewer   string     `gorm:"column:reviewer;size:36;default:'';"`                 // 审查者
+	Comment    string     `gorm:"column:comment;size:1024;"`                           // 审查意见
+	ReviewedAt *time.Time `gorm:"column:reviewed_at;"`                                 // 审查时间
+	Applied    bool       `gorm:"column:applied;default:false;"`                       // 撤销是否已执行
+}
+
+// TableName 表名
+func (a AccessReviewItem) TableName() string {
+	return a.Model.TableName("access_review_item")
+}
+
+// ToSchemaAccessReviewItem 转换为访问审查项对象
+func (a AccessReviewItem) ToSchemaAccessReviewItem() *schema.AccessReviewItem {
+	item := &schema.AccessReviewItem{
+		ID:         a.ID,
+		ReviewID:   a.ReviewID,
+		TenantID:   a.TenantID,
+		UserID:     a.UserID,
+		UserName:   a.UserName,
+		RoleID:     a.RoleID,
+		RoleName:   a.RoleName,
+		UserRoleID: a.UserRoleID,
+		ValidFrom:  a.ValidFrom,
+		ValidUntil: a.ValidUntil,
+		Decision:   a.Decision,
+		Reviewer:   a.Reviewer,
+		Comment:    a.Comment,
+		ReviewedAt: a.ReviewedAt,
+		Applied:    a.Applied,
+		CreatedAt:  a.CreatedAt,
+	}
+	return item
+}
+
+// AccessReviewItems 访问审查项实体列表
+type AccessReviewItems []*AccessReviewItem
+
+// ToSchemaAccessReviewItems 转换为访问审查项对象列表
+func (a AccessReviewItems) ToSchemaAccessReviewItems() []*schema.AccessReviewItem {
+	list := make([]*schema.AccessReviewItem, len(a))
+	for i, item := range a {
+		list[i] = item.ToSchemaAccessReviewItem()
+	}
+	return list
+}
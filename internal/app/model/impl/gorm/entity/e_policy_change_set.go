@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"context"
+	"time"
+
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/util"
+
+	"github.com/jinzhu/gorm"
+)
+
+// GetPolicyChangeSetDB 获取策略变更集存储
+func GetPolicyChangeSetDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return GetDBWithModel(ctx, defDB, new(PolicyChangeSet))
+}
+
+// SchemaPolicyChangeSet 策略变更集对象
+type SchemaPolicyChangeSet schema.PolicyChangeSet
+
+// ToPolicyChangeSet 转换为策略变更集实体
+func (a SchemaPolicyChangeSet) ToPolicyChangeSet() *PolicyChangeSet {
+	item := &PolicyChangeSet{
+		Title:         a.Title,
+		Description:   a.Description,
+		Status:        a.Status,
+		Changes:       util.JSONMarshalToString(a.Changes),
+		Reviewer:      a.Reviewer,
+		ReviewComment: a.ReviewComment,
+		ReviewedAt:    a.ReviewedAt,
+	}
+	if a.PreviewDiff != nil {
+		item.PreviewDiff = util.JSONMarshalToString(a.PreviewDiff)
+	}
+	item.ID = a.ID
+	item.Creator = a.Creator
+	return item
+}
+
+// PolicyChangeSet 策略变更集实体
+type PolicyChangeSet struct {
+	Model
+	Title         string     `gorm:"column:title;size:200;default:'';not null;"` // 标题
+	Description   string     `gorm:"column:description;size:1024;"`              // 描述
+	Status        int        `gorm:"column:status;index;default:0;not null;"`    // 状态(1:待审核 2:已批准 3:已拒绝)
+	Changes       string     `gorm:"column:changes;type:text;"`                  // 变更的策略规则(JSON)
+	PreviewDiff   string     `gorm:"column:preview_diff;type:text;"`             // 最近一次预览的实际差异(JSON)
+	Reviewer      string     `gorm:"column:reviewer;size:36;default:'';"`        // 审核者
+	ReviewComment string     `gorm:"column:review_comment;size:1024;"`           // 审核意见
+	ReviewedAt    *time.Time `gorm:"column:reviewed_at;"`                        // 审核时间
+}
+
+// TableName 表名
+func (a PolicyChangeSet) TableName() string {
+	return a.Model.TableName("policy_change_set")
+}
+
+// ToSchemaPolicyChangeSet 转换为策略变更集对象
+func (a PolicyChangeSet) ToSchemaPolicyChangeSet() *schema.PolicyChangeSet {
+	item := &schema.PolicyChangeSet{
+		ID:            a.ID,
+		Title:         a.Title,
+		Description:   a.Description,
+		Status:        a.Status,
+		Creator:       a.Creator,
+		Reviewer:      a.Reviewer,
+		ReviewComment: a.ReviewComment,
+		ReviewedAt:    a.ReviewedAt,
+		CreatedAt:     a.CreatedAt,
+		UpdatedAt:     a.UpdatedAt,
+	}
+	_ = util.JSONUnmarshal([]byte(a.Changes), &item.Changes)
+	if a.PreviewDiff != "" {
+		item.PreviewDiff = new(schema.PolicyChanges)
+		_ = util.JSONUnmarshal([]byte(a.PreviewDiff), item.PreviewDiff)
+	}
+	return item
+}
+
+// PolicyChangeSets 策略变更集实体列表
+type PolicyChangeSets []*PolicyChangeSet
+
+// ToSchemaPolicyChangeSets 转换为策略变更集对象列表
+func (a PolicyChangeSets) ToSchemaPolicyChangeSets() []*schema.PolicyChangeSet {
+	list := make([]*schema.PolicyChangeSet, len(a))
+	for i, item := range a {
+		list[i] = item.ToSchemaPolicyChangeSet()
+	}
+	return list
+}
@@ -0,0 +1,82 @@
+package entity
+
+import (
+	"context"
+	"strings"
+
+	"gin-casbin/internal/app/schema"
+
+	"github.com/jinzhu/gorm"
+)
+
+// GetPolicyDecisionDB 获取策略决策存储
+func GetPolicyDecisionDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return GetDBWithModel(ctx, defDB, new(PolicyDecision))
+}
+
+// 列表字段的分隔符
+const policyDecisionSep = "\n"
+
+// SchemaPolicyDecision 策略决策对象
+type SchemaPolicyDecision schema.PolicyDecision
+
+// ToPolicyDecision 转换为策略决策实体
+func (a SchemaPolicyDecision) ToPolicyDecision() *PolicyDecision {
+	item := &PolicyDecision{
+		UserID:        a.UserID,
+		TenantID:      a.TenantID,
+		Path:          a.Path,
+		Method:        a.Method,
+		Allowed:       a.Allowed,
+		Reason:        a.Reason,
+		MatchedPolicy: strings.Join(a.MatchedPolicy, policyDecisionSep),
+		RoleChain:     strings.Join(a.RoleChain, policyDecisionSep),
+		Roles:         strings.Join(a.Roles, policyDecisionSep),
+	}
+	item.ID = a.ID
+	item.CreatedAt = a.CreatedAt
+	return item
+}
+
+// PolicyDecision 策略决策实体
+type PolicyDecision struct {
+	Model
+	UserID        string `gorm:"column:user_id;size:36;index;default:'';not null;"`   // 用户ID
+	TenantID      string `gorm:"column:tenant_id;size:36;index;default:'';not null;"` // 租户ID
+	Path          string `gorm:"column:path;size:1024;default:'';not null;"`          // 请求路径
+	Method        string `gorm:"column:method;size:32;default:'';not null;"`          // 请求方法
+	Allowed       bool   `gorm:"column:allowed;default:false;not null;"`              // 是否允许
+	Reason        string `gorm:"column:reason;size:32;default:'';not null;"`          // 决策原因
+	MatchedPolicy string `gorm:"column:matched_policy;size:2048;"`                    // 匹配的策略
+	RoleChain     string `gorm:"column:role_chain;size:2048;"`                        // 角色链
+	Roles         string `gorm:"column:roles;size:2048;"`                             // 所有角色
+}
+
+// TableName 表名
+func (a PolicyDecision) TableName() string {
+	return a.Model.TableName("policy_decision")
+}
+
+// ToSchemaPolicyDecision 转换为策略决策对象
+func (a PolicyDecision) ToSchemaPolicyDecision() *schema.PolicyDecision {
+	return &schema.PolicyDecision{
+		ID:            a.ID,
+		UserID:        a.UserID,
+		TenantID:      a.TenantID,
+		Path:          a.Path,
+		Method:        a.Method,
+		Allowed:       a.Allowed,
+		Reason:        a.Reason,
+		MatchedPolicy: splitPolicyDecisionField(a.MatchedPolicy),
+		RoleChain:     splitPolicyDecisionField(a.RoleChain),
+		Roles:         splitPolicyDecisionField(a.Roles),
+		CreatedAt:     a.CreatedAt,
+	}
+}
+
+func splitPolicyDecisionField(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, policyDecisionSep)
+}
@@ -27,11 +27,12 @@ func (a SchemaRole) ToRole() *Role {
 // Role 角色实体
 type Role struct {
 	Model
-	Name        string  `gorm:"column:name;size:100;index;default:'';not null;"` // 角色名称
-	Sequence    int     `gorm:"column:sequence;index;default:0;not null;"`       // 排序值
-	Description *string `gorm:"column:description;size:1024;"`                   // 备注
-	Status      int     `gorm:"column:status;index;default:0;not null;"`         // 状态(1:启用 2:禁用)
-	Type        int     `gorm:"column:type;index;default:0"`                     // user, owner can manage:0, only root can see, owner:9
+	Name        string  `gorm:"column:name;size:100;index;default:'';not null;"`     // 角色名称
+	Sequence    int     `gorm:"column:sequence;index;default:0;not null;"`           // 排序值
+	Description *string `gorm:"column:description;size:1024;"`                       // 备注
+	Status      int     `gorm:"column:status;index;default:0;not null;"`             // 状态(1:启用 2:禁用)
+	Type        int     `gorm:"column:type;index;default:0"`                         // user, owner can manage:0, only root can see, owner:9
+	TenantID    string  `gorm:"column:tenant_id;size:36;index;default:'';not null;"` // 所属租户(为空表示全局角色)
 }
 
 // TableName 表名
@@ -0,0 +1,105 @@
+package entity
+
+import (
+	"context"
+	"time"
+
+	"gin-casbin/internal/app/schema"
+
+	"github.com/jinzhu/gorm"
+)
+
+// GetRoleElevationDB 获取临时提权申请存储
+func GetRoleElevationDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return GetDBWithModel(ctx, defDB, new(RoleElevation))
+}
+
+// SchemaRoleElevation 临时提权申请对象
+type SchemaRoleElevation schema.RoleElevation
+
+// ToRoleElevation 转换为临时提权申请实体
+func (a SchemaRoleElevation) ToRoleElevation() *RoleElevation {
+	item := &RoleElevation{
+		TenantID:      a.TenantID,
+		UserID:        a.UserID,
+		RoleID:        a.RoleID,
+		Reason:        a.Reason,
+		Duration:      a.Duration,
+		Status:        a.Status,
+		UserRoleID:    a.UserRoleID,
+		Reviewer:      a.Reviewer,
+		ReviewComment: a.ReviewComment,
+		ReviewedAt:    a.ReviewedAt,
+		ValidFrom:     a.ValidFrom,
+		ValidUntil:    a.ValidUntil,
+		Revoker:       a.Revoker,
+		RevokeReason:  a.RevokeReason,
+		RevokedAt:     a.RevokedAt,
+	}
+	item.ID = a.ID
+	item.Creator = a.Creator
+	return item
+}
+
+// RoleElevation 临时提权申请实体
+type RoleElevation struct {
+	Model
+	TenantID      string     `gorm:"column:tenant_id;size:36;index;default:'';not null;"` // 租户ID
+	UserID        string     `gorm:"column:user_id;size:36;index;default:'';not null;"`   // 申请用户
+	RoleID        string     `gorm:"column:role_id;size:36;index;default:'';not null;"`   // 申请的角色
+	Reason        string     `gorm:"column:reason;size:1024;"`                            // 申请原因
+	Duration      int        `gorm:"column:duration;default:0;not null;"`                 // 授权时长(分钟)
+	Status        int        `gorm:"column:status;index;default:0;not null;"`             // 状态(1:待审批 2:已批准 3:已拒绝 4:已撤销 5:已到期)
+	UserRoleID    string     `gorm:"column:user_role_id;size:36;default:'';"`             // 批准后授予的用户角色
+	Reviewer      string     `gorm:"column:reviewer;size:36;default:'';"`                 // 审批者
+	ReviewComment string     `gorm:"column:review_comment;size:1024;"`                    // 审批意见
+	ReviewedAt    *time.Time `gorm:"column:reviewed_at;"`                                 // 审批时间
+	ValidFrom     *time.Time `gorm:"column:valid_from;"`                                  // 授权生效时间
+	ValidUntil    *time.Time `gorm:"column:valid_until;"`                                 // 授权失效时间
+	Revoker       string     `gorm:"column:revoker;size:36;default:'';"`                  // 撤销者
+	RevokeReason  string     `gorm:"column:revoke_reason;size:1024;"`                     // 撤销原因
+	RevokedAt     *time.Time `gorm:"column:revoked_at;"`                                  // 撤销时间
+}
+
+// TableName 表名
+func (a RoleElevation) TableName() string {
+	return a.Model.TableName("role_elevation")
+}
+
+// ToSchemaRoleElevation 转换为临时提权申请对象
+func (a RoleElevation) ToSchemaRoleElevation() *schema.RoleElevation {
+	item := &schema.RoleElevation{
+		ID:            a.ID,
+		TenantID:      a.TenantID,
+		UserID:        a.UserID,
+		RoleID:        a.RoleID,
+		Reason:        a.Reason,
+		Duration:      a.Duration,
+		Status:        a.Status,
+		UserRoleID:    a.UserRoleID,
+		Reviewer:      a.Reviewer,
+		ReviewComment: a.ReviewComment,
+		ReviewedAt:    a.ReviewedAt,
+		ValidFrom:     a.ValidFrom,
+		ValidUntil:    a.ValidUntil,
+		Revoker:       a.Revoker,
+		RevokeReason:  a.RevokeReason,
+		RevokedAt:     a.RevokedAt,
+		Creator:       a.Creator,
+		CreatedAt:     a.CreatedAt,
+		UpdatedAt:     a.UpdatedAt,
+	}
+	return item
+}
+
+// RoleElevations 临时提权申请实体列表
+type RoleElevations []*RoleElevation
+
+// ToSchemaRoleElevations 转换为临时提权申请对象列表
+func (a RoleElevations) ToSchemaRoleElevations() []*schema.RoleElevation {
+	list := make([]*schema.RoleElevation, len(a))
+	for i, item := range a {
+		list[i] = item.ToSchemaRoleElevation()
+	}
+	return list
+}
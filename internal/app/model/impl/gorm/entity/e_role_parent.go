@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/util"
+
+	"github.com/jinzhu/gorm"
+)
+
+// GetRoleParentDB 获取角色继承关系存储
+func GetRoleParentDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return GetDBWithModel(ctx, defDB, new(RoleParent))
+}
+
+// SchemaRoleParent 角色继承关系
+type SchemaRoleParent schema.RoleParent
+
+// ToRoleParent 转换为角色继承关系实体
+func (a SchemaRoleParent) ToRoleParent() *RoleParent {
+	item := new(RoleParent)
+	util.StructMapToStruct(a, item)
+	return item
+}
+
+// RoleParent 角色继承关系实体
+type RoleParent struct {
+	Model
+	RoleID   string `gorm:"column:role_id;size:36;index;default:'';not null;"`   // 角色内码
+	ParentID string `gorm:"column:parent_id;size:36;index;default:'';not null;"` // 父角色内码
+}
+
+// TableName 表名
+func (a RoleParent) TableName() string {
+	return a.Model.TableName("role_parent")
+}
+
+// ToSchemaRoleParent 转换为角色继承关系对象
+func (a RoleParent) ToSchemaRoleParent() *schema.RoleParent {
+	item := new(schema.RoleParent)
+	util.StructMapToStruct(a, item)
+	return item
+}
+
+// RoleParents 角色继承关系列表
+type RoleParents []*RoleParent
+
+// ToSchemaRoleParents 转换为角色继承关系对象列表
+func (a RoleParents) ToSchemaRoleParents() []*schema.RoleParent {
+	list := make([]*schema.RoleParent, len(a))
+	for i, item := range a {
+		list[i] = item.ToSchemaRoleParent()
+	}
+	return list
+}
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"context"
+	"time"
 
 	"gin-casbin/internal/app/schema"
 	"gin-casbin/pkg/util"
@@ -27,8 +28,10 @@ func (a SchemaUserRole) ToUserRole() *UserRole {
 // UserRole 用户角色关联实体
 type UserRole struct {
 	Model
-	UserID string `gorm:"column:user_id;size:36;index;default:'';not null;"` // 用户内码
-	RoleID string `gorm:"column:role_id;size:36;index;default:'';not null;"` // 角色内码
+	UserID     string     `gorm:"column:user_id;size:36;index;default:'';not null;"` // 用户内码
+	RoleID     string     `gorm:"column:role_id;size:36;index;default:'';not null;"` // 角色内码
+	ValidFrom  *time.Time `gorm:"column:valid_from;index;"`                          // 生效时间
+	ValidUntil *time.Time `gorm:"column:valid_until;index;"`                         // 失效时间
 }
 
 // TableName 表名
@@ -7,6 +7,7 @@ import (
 
 	"gin-casbin/internal/app/config"
 	"gin-casbin/internal/app/model/impl/gorm/entity"
+	"gin-casbin/internal/app/schema"
 	"gin-casbin/pkg/logger"
 
 	"github.com/jinzhu/gorm"
@@ -68,5 +69,81 @@ func AutoMigrate(db *gorm.DB) error {
 		new(entity.User),
 		new(entity.Tenant),
 		new(entity.UserTenant),
+		new(entity.PolicyDecision),
+		new(entity.PolicyChangeSet),
+		new(entity.RoleElevation),
+		new(entity.RoleParent),
+		new(entity.RoleMenu),
+		new(entity.Menu),
+		new(entity.MenuAction),
+		new(entity.MenuActionResource),
+		new(entity.AccessReview),
+		new(entity.AccessReviewItem),
 	).Error
 }
+
+// MigratePlatformAdminRole 创建平台管理员角色(已存在或已删除时跳过)
+func MigratePlatformAdminRole(db *gorm.DB) error {
+	cfg := config.C.PlatformAdminRole
+	if cfg.ID == "" {
+		return nil
+	}
+
+	var count int
+	err := db.Unscoped().Model(new(entity.Role)).Where("id=?", cfg.ID).Count(&count).Error
+	if err != nil {
+		return err
+	} else if count > 0 {
+		return nil
+	}
+
+	// 平台管理员是只有根租户可见的全局角色，策略由内置规则提供
+	item := entity.SchemaRole(schema.Role{
+		ID:     cfg.ID,
+		Name:   cfg.Name,
+		Status: 1,
+		Type:   1,
+	}).ToRole()
+	return db.Create(item).Error
+}
+
+// MigrateRoleTenant 为租户隔离前创建的角色设置所属租户
+// 升级前的角色都没有所属租户(全局角色)：非根租户用户创建的角色归属创建者所在的租户，
+// 根租户用户或已不存在的用户创建的角色保留为全局角色。
+// 升级后非根租户创建的角色总是带有所属租户，因此可以重复执行。
+func MigrateRoleTenant(db *gorm.DB) error {
+	var roles entity.Roles
+	err := db.Unscoped().Where("tenant_id=? AND creator<>?", "", "").Find(&roles).Error
+	if err != nil {
+		return err
+	} else if len(roles) == 0 {
+		return nil
+	}
+
+	var creators []string
+	for _, item := range roles {
+		creators = append(creators, item.Creator)
+	}
+
+	var users entity.Users
+	err = db.Unscoped().Where("id IN (?)", creators).Find(&users).Error
+	if err != nil {
+		return err
+	}
+	mTenants := make(map[string]string)
+	for _, item := range users {
+		mTenants[item.ID] = item.TenantID
+	}
+
+	for _, item := range roles {
+		tenantID := mTenants[item.Creator]
+		if tenantID == "" || tenantID == schema.RootTenantID {
+			continue
+		}
+		err := db.Unscoped().Model(new(entity.Role)).Where("id=?", item.ID).UpdateColumn("tenant_id", tenantID).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
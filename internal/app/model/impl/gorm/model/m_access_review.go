@@ -0,0 +1,89 @@
+package model
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/model"
+	"gin-casbin/internal/app/model/impl/gorm/entity"
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
+
+	"github.com/google/wire"
+	"github.com/jinzhu/gorm"
+)
+
+var _ model.IAccessReview = (*AccessReview)(nil)
+
+// AccessReviewSet 注入AccessReview
+var AccessReviewSet = wire.NewSet(wire.Struct(new(AccessReview), "*"), wire.Bind(new(model.IAccessReview), new(*AccessReview)))
+
+// AccessReview 访问审查活动存储
+type AccessReview struct {
+	DB *gorm.DB
+}
+
+func (a *AccessReview) getQueryOption(opts ...schema.AccessReviewQueryOptions) schema.AccessReviewQueryOptions {
+	var opt schema.AccessReviewQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return opt
+}
+
+// Query 查询数据
+func (a *AccessReview) Query(ctx context.Context, params schema.AccessReviewQueryParam, opts ...schema.AccessReviewQueryOptions) (*schema.AccessReviewQueryResult, error) {
+	opt := a.getQueryOption(opts...)
+
+	db := entity.GetAccessReviewDB(ctx, a.DB)
+	if v := params.Status; v > 0 {
+		db = db.Where("status=?", v)
+	}
+	opt.OrderFields = append(opt.OrderFields, schema.NewOrderField("id", schema.OrderByDESC))
+	db = db.Order(ParseOrder(opt.OrderFields))
+
+	var list entity.AccessReviews
+	pr, err := WrapPageQuery(ctx, db, params.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	qr := &schema.AccessReviewQueryResult{
+		PageResult: pr,
+		Data:       list.ToSchemaAccessReviews(),
+	}
+
+	return qr, nil
+}
+
+// Get 查询指定数据
+func (a *AccessReview) Get(ctx context.Context, id string, opts ...schema.AccessReviewQueryOptions) (*schema.AccessReview, error) {
+	db := entity.GetAccessReviewDB(ctx, a.DB).Where("id=?", id)
+	var item entity.AccessReview
+	ok, err := FindOne(ctx, db, &item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+
+	return item.ToSchemaAccessReview(), nil
+}
+
+// Create 创建数据
+func (a *AccessReview) Create(ctx context.Context, item schema.AccessReview) error {
+	eitem := entity.SchemaAccessReview(item).ToAccessReview()
+	result := entity.GetAccessReviewDB(ctx, a.DB).Create(eitem)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Update 更新数据
+func (a *AccessReview) Update(ctx context.Context, id string, item schema.AccessReview) error {
+	eitem := entity.SchemaAccessReview(item).ToAccessReview()
+	result := entity.GetAccessReviewDB(ctx, a.DB).Where("id=?", id).Updates(eitem)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
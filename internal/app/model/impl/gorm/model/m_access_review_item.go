@@ -0,0 +1,98 @@
+package model
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/model"
+	"gin-casbin/internal/app/model/impl/gorm/entity"
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
+
+	"github.com/google/wire"
+	"github.com/jinzhu/gorm"
+)
+
+var _ model.IAccessReviewItem = (*AccessReviewItem)(nil)
+
+// AccessReviewItemSet 注入AccessReviewItem
+var AccessReviewItemSet = wire.NewSet(wire.Struct(new(AccessReviewItem), "*"), wire.Bind(new(model.IAccessReviewItem), new(*AccessReviewItem)))
+
+// AccessReviewItem 访问审查项存储
+type AccessReviewItem struct {
+	DB *gorm.DB
+}
+
+func (a *AccessReviewItem) getQueryOption(opts ...schema.AccessReviewItemQueryOptions) schema.AccessReviewItemQueryOptions {
+	var opt schema.AccessReviewItemQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return opt
+}
+
+// Query 查询数据
+func (a *AccessReviewItem) Query(ctx context.Context, params schema.AccessReviewItemQueryParam, opts ...schema.AccessReviewItemQueryOptions) (*schema.AccessReviewItemQueryResult, error) {
+	opt := a.getQueryOption(opts...)
+
+	db := entity.GetAccessReviewItemDB(ctx, a.DB)
+	if v := params.ReviewID; v != "" {
+		db = db.Where("review_id=?", v)
+	}
+	if v := params.TenantID; v != "" {
+		db = db.Where("tenant_id=?", v)
+	}
+	if v := params.UserID; v != "" {
+		db = db.Where("user_id=?", v)
+	}
+	if v := params.Decision; v != nil {
+		db = db.Where("decision=?", *v)
+	}
+	opt.OrderFields = append(opt.OrderFields, schema.NewOrderField("tenant_id", schema.OrderByASC), schema.NewOrderField("user_name", schema.OrderByASC))
+	db = db.Order(ParseOrder(opt.OrderFields))
+
+	var list entity.AccessReviewItems
+	pr, err := WrapPageQuery(ctx, db, params.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	qr := &schema.AccessReviewItemQueryResult{
+		PageResult: pr,
+		Data:       list.ToSchemaAccessReviewItems(),
+	}
+
+	return qr, nil
+}
+
+// Get 查询指定数据
+func (a *AccessReviewItem) Get(ctx context.Context, id string, opts ...schema.AccessReviewItemQueryOptions) (*schema.AccessReviewItem, error) {
+	db := entity.GetAccessReviewItemDB(ctx, a.DB).Where("id=?", id)
+	var item entity.AccessReviewItem
+	ok, err := FindOne(ctx, db, &item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+
+	return item.ToSchemaAccessReviewItem(), nil
+}
+
+// Create 创建数据
+func (a *AccessReviewItem) Create(ctx context.Context, item schema.AccessReviewItem) error {
+	eitem := entity.SchemaAccessReviewItem(item).ToAccessReviewItem()
+	result := entity.GetAccessReviewItemDB(ctx, a.DB).Create(eitem)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Update 更新数据
+func (a *AccessReviewItem) Update(ctx context.Context, id string, item schema.AccessReviewItem) error {
+	eitem := entity.SchemaAccessReviewItem(item).ToAccessReviewItem()
+	result := entity.GetAccessReviewItemDB(ctx, a.DB).Where("id=?", id).Updates(eitem)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/model"
+	"gin-casbin/internal/app/model/impl/gorm/entity"
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
+
+	"github.com/google/wire"
+	"github.com/jinzhu/gorm"
+)
+
+var _ model.IPolicyChangeSet = (*PolicyChangeSet)(nil)
+
+// PolicyChangeSetSet 注入PolicyChangeSet
+var PolicyChangeSetSet = wire.NewSet(wire.Struct(new(PolicyChangeSet), "*"), wire.Bind(new(model.IPolicyChangeSet), new(*PolicyChangeSet)))
+
+// PolicyChangeSet 策略变更集存储
+type PolicyChangeSet struct {
+	DB *gorm.DB
+}
+
+func (a *PolicyChangeSet) getQueryOption(opts ...schema.PolicyChangeSetQueryOptions) schema.PolicyChangeSetQueryOptions {
+	var opt schema.PolicyChangeSetQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return opt
+}
+
+// Query 查询数据
+func (a *PolicyChangeSet) Query(ctx context.Context, params schema.PolicyChangeSetQueryParam, opts ...schema.PolicyChangeSetQueryOptions) (*schema.PolicyChangeSetQueryResult, error) {
+	opt := a.getQueryOption(opts...)
+
+	db := entity.GetPolicyChangeSetDB(ctx, a.DB)
+	if v := params.Status; v > 0 {
+		db = db.Where("status=?", v)
+	}
+	opt.OrderFields = append(opt.OrderFields, schema.NewOrderField("id", schema.OrderByDESC))
+	db = db.Order(ParseOrder(opt.OrderFields))
+
+	var list entity.PolicyChangeSets
+	pr, err := WrapPageQuery(ctx, db, params.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	qr := &schema.PolicyChangeSetQueryResult{
+		PageResult: pr,
+		Data:       list.ToSchemaPolicyChangeSets(),
+	}
+
+	return qr, nil
+}
+
+// Get 查询指定数据
+func (a *PolicyChangeSet) Get(ctx context.Context, id string, opts ...schema.PolicyChangeSetQueryOptions) (*schema.PolicyChangeSet, error) {
+	db := entity.GetPolicyChangeSetDB(ctx, a.DB).Where("id=?", id)
+	var item entity.PolicyChangeSet
+	ok, err := FindOne(ctx, db, &item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+
+	return item.ToSchemaPolicyChangeSet(), nil
+}
+
+// Create 创建数据
+func (a *PolicyChangeSet) Create(ctx context.Context, item schema.PolicyChangeSet) error {
+	eitem := entity.SchemaPolicyChangeSet(item).ToPolicyChangeSet()
+	result := entity.GetPolicyChangeSetDB(ctx, a.DB).Create(eitem)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Update 更新数据
+func (a *PolicyChangeSet) Update(ctx context.Context, id string, item schema.PolicyChangeSet) error {
+	eitem := entity.SchemaPolicyChangeSet(item).ToPolicyChangeSet()
+	result := entity.GetPolicyChangeSetDB(ctx, a.DB).Where("id=?", id).Updates(eitem)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/model"
+	"gin-casbin/internal/app/model/impl/gorm/entity"
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
+
+	"github.com/google/wire"
+	"github.com/jinzhu/gorm"
+)
+
+var _ model.IPolicyDecision = (*PolicyDecision)(nil)
+
+// PolicyDecisionSet 注入PolicyDecision
+var PolicyDecisionSet = wire.NewSet(wire.Struct(new(PolicyDecision), "*"), wire.Bind(new(model.IPolicyDecision), new(*PolicyDecision)))
+
+// PolicyDecision 策略决策存储
+type PolicyDecision struct {
+	DB *gorm.DB
+}
+
+// Get 查询指定数据
+func (a *PolicyDecision) Get(ctx context.Context, id string, opts ...schema.PolicyDecisionGetOptions) (*schema.PolicyDecision, error) {
+	db := entity.GetPolicyDecisionDB(ctx, a.DB).Where("id=?", id)
+	var item entity.PolicyDecision
+	ok, err := FindOne(ctx, db, &item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+
+	return item.ToSchemaPolicyDecision(), nil
+}
+
+// Create 创建数据
+func (a *PolicyDecision) Create(ctx context.Context, item schema.PolicyDecision) error {
+	eitem := entity.SchemaPolicyDecision(item).ToPolicyDecision()
+	result := entity.GetPolicyDecisionDB(ctx, a.DB).Create(eitem)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -42,8 +42,13 @@ func (a *Role) Query(ctx context.Context, params schema.RoleQueryParam, opts ...
 	if v := params.Name; v != "" {
 		db = db.Where("name=?", v)
 	}
-	if v := params.TenantID; v != "" && v != "wetrue" {
+	if v := params.Status; v > 0 {
+		db = db.Where("status=?", v)
+	}
+	if v := params.TenantID; v != "" && v != schema.RootTenantID {
 		db = db.Where("id <> ?", config.C.TenantOwnerRole.ID)
+		db = db.Where("id <> ?", config.C.PlatformAdminRole.ID)
+		db = db.Where("tenant_id='' OR tenant_id=?", v)
 	}
 	if v := params.UserID; v != "" {
 		subQuery := entity.GetUserRoleDB(ctx, a.DB).
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/model"
+	"gin-casbin/internal/app/model/impl/gorm/entity"
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
+
+	"github.com/google/wire"
+	"github.com/jinzhu/gorm"
+)
+
+var _ model.IRoleElevation = (*RoleElevation)(nil)
+
+// RoleElevationSet 注入RoleElevation
+var RoleElevationSet = wire.NewSet(wire.Struct(new(RoleElevation), "*"), wire.Bind(new(model.IRoleElevation), new(*RoleElevation)))
+
+// RoleElevation 临时提权申请存储
+type RoleElevation struct {
+	DB *gorm.DB
+}
+
+func (a *RoleElevation) getQueryOption(opts ...schema.RoleElevationQueryOptions) schema.RoleElevationQueryOptions {
+	var opt schema.RoleElevationQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return opt
+}
+
+// Query 查询数据
+func (a *RoleElevation) Query(ctx context.Context, params schema.RoleElevationQueryParam, opts ...schema.RoleElevationQueryOptions) (*schema.RoleElevationQueryResult, error) {
+	opt := a.getQueryOption(opts...)
+
+	db := entity.GetRoleElevationDB(ctx, a.DB)
+	if v := params.TenantID; v != "" {
+		db = db.Where("tenant_id=?", v)
+	}
+	if v := params.UserID; v != "" {
+		db = db.Where("user_id=?", v)
+	}
+	if v := params.RoleID; v != "" {
+		db = db.Where("role_id=?", v)
+	}
+	if v := params.Status; v > 0 {
+		db = db.Where("status=?", v)
+	}
+	if v := params.UserRoleIDs; len(v) > 0 {
+		db = db.Where("user_role_id IN (?)", v)
+	}
+	opt.OrderFields = append(opt.OrderFields, schema.NewOrderField("id", schema.OrderByDESC))
+	db = db.Order(ParseOrder(opt.OrderFields))
+
+	var list entity.RoleElevations
+	pr, err := WrapPageQuery(ctx, db, params.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	qr := &schema.RoleElevationQueryResult{
+		PageResult: pr,
+		Data:       list.ToSchemaRoleElevations(),
+	}
+
+	return qr, nil
+}
+
+// Get 查询指定数据
+func (a *RoleElevation) Get(ctx context.Context, id string, opts ...schema.RoleElevationQueryOptions) (*schema.RoleElevation, error) {
+	db := entity.GetRoleElevationDB(ctx, a.DB).Where("id=?", id)
+	var item entity.RoleElevation
+	ok, err := FindOne(ctx, db, &item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+
+	return item.ToSchemaRoleElevation(), nil
+}
+
+// Create 创建数据
+func (a *RoleElevation) Create(ctx context.Context, item schema.RoleElevation) error {
+	eitem := entity.SchemaRoleElevation(item).ToRoleElevation()
+	result := entity.GetRoleElevationDB(ctx, a.DB).Create(eitem)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Update 更新数据
+func (a *RoleElevation) Update(ctx context.Context, id string, item schema.RoleElevation) error {
+	eitem := entity.SchemaRoleElevation(item).ToRoleElevation()
+	result := entity.GetRoleElevationDB(ctx, a.DB).Where("id=?", id).Updates(eitem)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/model"
+	"gin-casbin/internal/app/model/impl/gorm/entity"
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
+
+	"github.com/google/wire"
+	"github.com/jinzhu/gorm"
+)
+
+var _ model.IRoleParent = (*RoleParent)(nil)
+
+// RoleParentSet 注入RoleParent
+var RoleParentSet = wire.NewSet(wire.Struct(new(RoleParent), "*"), wire.Bind(new(model.IRoleParent), new(*RoleParent)))
+
+// RoleParent 角色继承关系存储
+type RoleParent struct {
+	DB *gorm.DB
+}
+
+func (a *RoleParent) getQueryOption(opts ...schema.RoleParentQueryOptions) schema.RoleParentQueryOptions {
+	var opt schema.RoleParentQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return opt
+}
+
+// Query 查询数据
+func (a *RoleParent) Query(ctx context.Context, params schema.RoleParentQueryParam, opts ...schema.RoleParentQueryOptions) (*schema.RoleParentQueryResult, error) {
+	opt := a.getQueryOption(opts...)
+
+	db := entity.GetRoleParentDB(ctx, a.DB)
+	if v := params.RoleID; v != "" {
+		db = db.Where("role_id=?", v)
+	}
+	if v := params.RoleIDs; len(v) > 0 {
+		db = db.Where("role_id IN (?)", v)
+	}
+
+	opt.OrderFields = append(opt.OrderFields, schema.NewOrderField("id", schema.OrderByDESC))
+	db = db.Order(ParseOrder(opt.OrderFields))
+
+	var list entity.RoleParents
+	pr, err := WrapPageQuery(ctx, db, params.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	qr := &schema.RoleParentQueryResult{
+		PageResult: pr,
+		Data:       list.ToSchemaRoleParents(),
+	}
+
+	return qr, nil
+}
+
+// Create 创建数据
+func (a *RoleParent) Create(ctx context.Context, item schema.RoleParent) error {
+	eitem := entity.SchemaRoleParent(item).ToRoleParent()
+	result := entity.GetRoleParentDB(ctx, a.DB).Create(eitem)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Delete 删除数据
+func (a *RoleParent) Delete(ctx context.Context, id string) error {
+	result := entity.GetRoleParentDB(ctx, a.DB).Where("id=?", id).Delete(entity.RoleParent{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// DeleteByRoleID 删除角色作为子角色或父角色的全部继承关系
+func (a *RoleParent) DeleteByRoleID(ctx context.Context, roleID string) error {
+	result := entity.GetRoleParentDB(ctx, a.DB).Where("role_id=? OR parent_id=?", roleID, roleID).Delete(entity.RoleParent{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
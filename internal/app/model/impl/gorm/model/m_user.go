@@ -36,6 +36,9 @@ func (a *User) Query(ctx context.Context, params schema.UserQueryParam, opts ...
 	opt := a.getQueryOption(opts...)
 	db := entity.GetUserDB(ctx, a.DB)
 	db = db.Preload("Roles").Preload("Tenant")
+	if v := params.IDs; len(v) > 0 {
+		db = db.Where("id IN (?)", v)
+	}
 	if v := params.UserName; v != "" {
 		db = db.Where("lower(user_name)=?", strings.ToLower(v))
 	}
@@ -41,6 +41,12 @@ func (a *UserRole) Query(ctx context.Context, params schema.UserRoleQueryParam,
 	if v := params.UserIDs; len(v) > 0 {
 		db = db.Where("user_id IN (?)", v)
 	}
+	if params.TimeBound {
+		db = db.Where("valid_from IS NOT NULL OR valid_until IS NOT NULL")
+	}
+	if from, to := params.BoundaryFrom, params.BoundaryTo; from != nil && to != nil {
+		db = db.Where("(valid_from>? AND valid_from<=?) OR (valid_until>? AND valid_until<=?)", from, to, from, to)
+	}
 
 	opt.OrderFields = append(opt.OrderFields, schema.NewOrderField("id", schema.OrderByDESC))
 	db = db.Order(ParseOrder(opt.OrderFields))
@@ -11,4 +11,14 @@ var ModelSet = wire.NewSet(
 	TenantSet,
 	UserTenantSet,
 	TenantAdministratorSet,
+	PolicyDecisionSet,
+	PolicyChangeSetSet,
+	RoleElevationSet,
+	RoleParentSet,
+	RoleMenuSet,
+	MenuSet,
+	MenuActionSet,
+	MenuActionResourceSet,
+	AccessReviewSet,
+	AccessReviewItemSet,
 )
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/schema"
+)
+
+// IAccessReview 访问审查活动存储接口
+type IAccessReview interface {
+	// 查询数据
+	Query(ctx context.Context, params schema.AccessReviewQueryParam, opts ...schema.AccessReviewQueryOptions) (*schema.AccessReviewQueryResult, error)
+	// 查询指定数据
+	Get(ctx context.Context, id string, opts ...schema.AccessReviewQueryOptions) (*schema.AccessReview, error)
+	// 创建数据
+	Create(ctx context.Context, item schema.AccessReview) error
+	// 更新数据
+	Update(ctx context.Context, id string, item schema.AccessReview) error
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/schema"
+)
+
+// IAccessReviewItem 访问审查项存储接口
+type IAccessReviewItem interface {
+	// 查询数据
+	Query(ctx context.Context, params schema.AccessReviewItemQueryParam, opts ...schema.AccessReviewItemQueryOptions) (*schema.AccessReviewItemQueryResult, error)
+	// 查询指定数据
+	Get(ctx context.Context, id string, opts ...schema.AccessReviewItemQueryOptions) (*schema.AccessReviewItem, error)
+	// 创建数据
+	Create(ctx context.Context, item schema.AccessReviewItem) error
+	// 更新数据
+	Update(ctx context.Context, id string, item schema.AccessReviewItem) error
+}
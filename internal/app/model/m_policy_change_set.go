@@ -0,0 +1,19 @@
+package model
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/schema"
+)
+
+// IPolicyChangeSet 策略变更集存储接口
+type IPolicyChangeSet interface {
+	// 查询数据
+	Query(ctx context.Context, params schema.PolicyChangeSetQueryParam, opts ...schema.PolicyChangeSetQueryOptions) (*schema.PolicyChangeSetQueryResult, error)
+	// 查询指定数据
+	Get(ctx context.Context, id string, opts ...schema.PolicyChangeSetQueryOptions) (*schema.PolicyChangeSet, error)
+	// 创建数据
+	Create(ctx context.Context, item schema.PolicyChangeSet) error
+	// 更新数据
+	Update(ctx context.Context, id string, item schema.PolicyChangeSet) error
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/schema"
+)
+
+// IPolicyDecision 策略决策存储接口
+type IPolicyDecision interface {
+	// 查询指定数据
+	Get(ctx context.Context, id string, opts ...schema.PolicyDecisionGetOptions) (*schema.PolicyDecision, error)
+	// 创建数据
+	Create(ctx context.Context, item schema.PolicyDecision) error
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/schema"
+)
+
+// IRoleElevation 临时提权申请存储接口
+type IRoleElevation interface {
+	// 查询数据
+	Query(ctx context.Context, params schema.RoleElevationQueryParam, opts ...schema.RoleElevationQueryOptions) (*schema.RoleElevationQueryResult, error)
+	// 查询指定数据
+	Get(ctx context.Context, id string, opts ...schema.RoleElevationQueryOptions) (*schema.RoleElevation, error)
+	// 创建数据
+	Create(ctx context.Context, item schema.RoleElevation) error
+	// 更新数据
+	Update(ctx context.Context, id string, item schema.RoleElevation) error
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/schema"
+)
+
+// IRoleParent 角色继承关系存储接口
+type IRoleParent interface {
+	// 查询数据
+	Query(ctx context.Context, params schema.RoleParentQueryParam, opts ...schema.RoleParentQueryOptions) (*schema.RoleParentQueryResult, error)
+	// 创建数据
+	Create(ctx context.Context, item schema.RoleParent) error
+	// 删除数据
+	Delete(ctx context.Context, id string) error
+	// 删除角色作为子角色或父角色的全部继承关系
+	DeleteByRoleID(ctx context.Context, roleID string) error
+}
@@ -0,0 +1,271 @@
+package adapter
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+
+	"gin-casbin/internal/app/schema"
+
+	"github.com/casbin/casbin/v2"
+)
+
+// DecisionCache 策略决策缓存，按(租户,用户)索引，策略变更时只失效受影响的用户
+// 达到最大数量时淘汰最久未使用的决策
+type DecisionCache struct {
+	hits    uint64
+	misses  uint64
+	version uint64
+	maxSize int
+
+	mutex sync.Mutex
+	lru   *list.List
+	items map[decisionSubject]map[decisionRequest]*list.Element
+}
+
+type decisionSubject struct {
+	tenantID string
+	userID   string
+}
+
+type decisionRequest struct {
+	path   string
+	method string
+}
+
+type decisionEntry struct {
+	subject decisionSubject
+	request decisionRequest
+	allowed bool
+}
+
+// NewDecisionCache 创建策略决策缓存(maxSize<=0表示不限制数量)
+func NewDecisionCache(maxSize int) *DecisionCache {
+	return &DecisionCache{
+		maxSize: maxSize,
+		lru:     list.New(),
+		items:   make(map[decisionSubject]map[decisionRequest]*list.Element),
+	}
+}
+
+// Version 获取当前缓存版本，写入缓存时版本不一致则放弃写入
+func (c *DecisionCache) Version() uint64 {
+	return atomic.LoadUint64(&c.version)
+}
+
+// Get 获取缓存的决策(path为路由模板)
+func (c *DecisionCache) Get(tenantID, userID, path, method string) (allowed bool, ok bool) {
+	c.mutex.Lock()
+	el, ok := c.items[decisionSubject{tenantID, userID}][decisionRequest{path, method}]
+	if ok {
+		c.lru.MoveToFront(el)
+		allowed = el.Value.(*decisionEntry).allowed
+	}
+	c.mutex.Unlock()
+
+	if ok {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+	}
+	return
+}
+
+// Set 写入决策，决策计算期间发生过失效时放弃写入
+func (c *DecisionCache) Set(tenantID, userID, path, method string, allowed bool, version uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.Version() != version {
+		return
+	}
+
+	subject := decisionSubject{tenantID, userID}
+	request := decisionRequest{path, method}
+	m, ok := c.items[subject]
+	if !ok {
+		m = make(map[decisionRequest]*list.Element)
+		c.items[subject] = m
+	}
+	if el, ok := m[request]; ok {
+		el.Value.(*decisionEntry).allowed = allowed
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	m[request] = c.lru.PushFront(&decisionEntry{subject: subject, request: request, allowed: allowed})
+	for c.maxSize > 0 && c.lru.Len() > c.maxSize {
+		c.remove(c.lru.Back())
+	}
+}
+
+// 删除决策(调用方持有锁)
+func (c *DecisionCache) remove(el *list.Element) {
+	entry := c.lru.Remove(el).(*decisionEntry)
+	m := c.items[entry.subject]
+	delete(m, entry.request)
+	if len(m) == 0 {
+		delete(c.items, entry.subject)
+	}
+}
+
+// InvalidateUser 失效用户在租户下的决策
+func (c *DecisionCache) InvalidateUser(tenantID, userID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	atomic.AddUint64(&c.version, 1)
+	subject := decisionSubject{tenantID, userID}
+	for _, el := range c.items[subject] {
+		c.lru.Remove(el)
+	}
+	delete(c.items, subject)
+}
+
+// InvalidateTenant 失效租户下所有用户的决策
+func (c *DecisionCache) InvalidateTenant(tenantID string) {
+	c.invalidate(func(s decisionSubject) bool { return s.tenantID == tenantID })
+}
+
+// Clear 清空缓存(全量加载策略时使用)
+func (c *DecisionCache) Clear() {
+	c.invalidate(func(decisionSubject) bool { return true })
+}
+
+func (c *DecisionCache) invalidate(match func(decisionSubject) bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	atomic.AddUint64(&c.version, 1)
+	for subject, m := range c.items {
+		if !match(subject) {
+			continue
+		}
+		for _, el := range m {
+			c.lru.Remove(el)
+		}
+		delete(c.items, subject)
+	}
+}
+
+// Stats 获取缓存统计
+func (c *DecisionCache) Stats() *schema.DecisionCacheStats {
+	c.mutex.Lock()
+	size := c.lru.Len()
+	c.mutex.Unlock()
+
+	return &schema.DecisionCacheStats{
+		Enabled: true,
+		Hits:    atomic.LoadUint64(&c.hits),
+		Misses:  atomic.LoadUint64(&c.misses),
+		Size:    size,
+	}
+}
+
+// SetDecisionCache 设置策略决策缓存
+func (a *CasbinAdapter) SetDecisionCache(c *DecisionCache) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.cache = c
+}
+
+// DecisionCache 获取策略决策缓存(未启用时为nil)
+func (a *CasbinAdapter) DecisionCache() *DecisionCache {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.cache
+}
+
+// GetDecisionCache 获取enforcer的策略决策缓存(未启用时为nil)
+func GetDecisionCache(e *casbin.SyncedEnforcer) *DecisionCache {
+	if a, ok := e.GetAdapter().(*CasbinAdapter); ok {
+		return a.DecisionCache()
+	}
+	return nil
+}
+
+// InvalidateTenant 失效租户下所有用户的决策(租户停用或删除时使用)
+func InvalidateTenant(e *casbin.SyncedEnforcer, tenantID string) {
+	if c := GetDecisionCache(e); c != nil {
+		c.InvalidateTenant(tenantID)
+	}
+}
+
+// 全量加载策略后清空缓存
+func (a *CasbinAdapter) clearDecisionCache() {
+	if c := a.DecisionCache(); c != nil {
+		c.Clear()
+	}
+}
+
+// 根据策略增量失效受影响用户的决策(角色策略或继承关系变更时失效继承该角色的所有用户)
+func (a *CasbinAdapter) invalidateDecisionCache(e *casbin.SyncedEnforcer, d *PolicyDelta) {
+	c := a.DecisionCache()
+	if c == nil || d.IsEmpty() {
+		return
+	}
+
+	var roleIDs []string
+	for _, rules := range [][][]string{d.AddedGroupings, d.RemovedGroupings} {
+		for _, rule := range rules {
+			if tenantID, userID, _, err := ParseUserRule(rule); err == nil {
+				c.InvalidateUser(tenantID, userID)
+				roleIDs = append(roleIDs, userID)
+			}
+		}
+	}
+	for _, rules := range [][][]string{d.AddedPolicies, d.RemovedPolicies} {
+		for _, rule := range rules {
+			if rr, err := ParseRoleRule(rule); err == nil {
+				roleIDs = append(roleIDs, rr.RoleID)
+			}
+		}
+	}
+
+	mVisited := make(map[string]struct{})
+	for len(roleIDs) > 0 {
+		roleID := roleIDs[0]
+		roleIDs = roleIDs[1:]
+		if _, ok := mVisited[roleID]; ok {
+			continue
+		}
+		mVisited[roleID] = struct{}{}
+
+		for _, g := range e.GetFilteredGroupingPolicy(1, roleID) {
+			if tenantID, userID, _, err := ParseUserRule(g); err == nil {
+				c.InvalidateUser(tenantID, userID)
+				roleIDs = append(roleIDs, userID)
+			}
+		}
+	}
+}
+
+// IsCacheable 检查用户在租户下的决策是否可以缓存(角色策略带条件时决策依赖请求属性，不能缓存)
+func IsCacheable(e *casbin.SyncedEnforcer, userID, tenantID string) bool {
+	rm := e.GetRoleManager()
+	mRoleIDs := map[string]struct{}{userID: {}}
+	queue := []string{userID}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		roles, err := rm.GetRoles(name, tenantID)
+		if err != nil {
+			return false
+		}
+		for _, role := range roles {
+			if _, ok := mRoleIDs[role]; ok {
+				continue
+			}
+			mRoleIDs[role] = struct{}{}
+			queue = append(queue, role)
+
+			for _, rule := range e.GetFilteredPolicy(0, role) {
+				if rr, err := ParseRoleRule(rule); err != nil || rr.Condition != "" {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package adapter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecisionCacheEvict(t *testing.T) {
+	c := NewDecisionCache(2)
+	c.Set("t1", "u1", "/a", "GET", true, c.Version())
+	c.Set("t1", "u1", "/b", "GET", true, c.Version())
+
+	// 访问/a后/b成为最久未使用的决策
+	_, ok := c.Get("t1", "u1", "/a", "GET")
+	assert.True(t, ok)
+	c.Set("t1", "u2", "/c", "GET", false, c.Version())
+
+	_, ok = c.Get("t1", "u1", "/b", "GET")
+	assert.False(t, ok)
+	allowed, ok := c.Get("t1", "u1", "/a", "GET")
+	assert.True(t, ok)
+	assert.True(t, allowed)
+	allowed, ok = c.Get("t1", "u2", "/c", "GET")
+	assert.True(t, ok)
+	assert.False(t, allowed)
+	assert.Equal(t, 2, c.Stats().Size)
+}
+
+func TestDecisionCacheInvalidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		invalidate func(c *DecisionCache)
+		expected   []bool // (t1,u1) (t1,u2) (t2,u1)
+	}{
+		{"user", func(c *DecisionCache) { c.InvalidateUser("t1", "u1") }, []bool{false, true, true}},
+		{"tenant", func(c *DecisionCache) { c.InvalidateTenant("t1") }, []bool{false, false, true}},
+		{"clear", func(c *DecisionCache) { c.Clear() }, []bool{false, false, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewDecisionCache(0)
+			subjects := [][2]string{{"t1", "u1"}, {"t1", "u2"}, {"t2", "u1"}}
+			for _, s := range subjects {
+				c.Set(s[0], s[1], "/a", "GET", true, c.Version())
+			}
+
+			version := c.Version()
+			tt.invalidate(c)
+			for i, s := range subjects {
+				_, ok := c.Get(s[0], s[1], "/a", "GET")
+				assert.Equal(t, tt.expected[i], ok, "%v", s)
+			}
+
+			// 失效前开始计算的决策不再写入
+			c.Set("t1", "u1", "/b", "GET", true, version)
+			_, ok := c.Get("t1", "u1", "/b", "GET")
+			assert.False(t, ok)
+		})
+	}
+}
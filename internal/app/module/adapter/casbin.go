@@ -2,35 +2,141 @@ package adapter
 
 import (
 	"context"
-	"fmt"
+	"strings"
+	"sync"
+	"time"
 
+	"gin-casbin/internal/app/config"
+	"gin-casbin/internal/app/iutil"
 	"gin-casbin/internal/app/model"
 	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
 	"gin-casbin/pkg/logger"
+	"gin-casbin/pkg/watcher"
 
+	"github.com/casbin/casbin/v2"
 	casbinModel "github.com/casbin/casbin/v2/model"
 	"github.com/casbin/casbin/v2/persist"
+	"github.com/casbin/casbin/v2/util"
 	"github.com/google/wire"
 )
 
 var _ persist.Adapter = (*CasbinAdapter)(nil)
+var _ persist.BatchAdapter = (*CasbinAdapter)(nil)
+var _ persist.FilteredAdapter = (*CasbinAdapter)(nil)
 
 // CasbinAdapterSet 注入CasbinAdapter
 var CasbinAdapterSet = wire.NewSet(wire.Struct(new(CasbinAdapter), "*"), wire.Bind(new(persist.Adapter), new(*CasbinAdapter)))
 
 // CasbinAdapter casbin适配器
 type CasbinAdapter struct {
-	RoleModel         model.IRole
-	RoleMenuModel     model.IRoleMenu
-	MenuResourceModel model.IMenuActionResource
-	UserModel         model.IUser
-	UserRoleModel     model.IUserRole
-	UserTenantModel   model.IUserTenant
+	TransModel         model.ITrans
+	RoleModel          model.IRole
+	RoleMenuModel      model.IRoleMenu
+	MenuActionModel    model.IMenuAction
+	MenuResourceModel  model.IMenuActionResource
+	UserModel          model.IUser
+	UserRoleModel      model.IUserRole
+	UserTenantModel    model.IUserTenant
+	RoleParentModel    model.IRoleParent
+	RoleElevationModel model.IRoleElevation
+
+	mutex     sync.Mutex           `wire:"-"`
+	loading   sync.Mutex           `wire:"-"`
+	filtered  bool                 `wire:"-"`
+	tenantIDs map[string]struct{}  `wire:"-"`
+	applying  map[*string]struct{} `wire:"-"`
+	watcher   *watcher.Watcher     `wire:"-"`
+	cache     *DecisionCache       `wire:"-"`
+	pending   casbinModel.Model    `wire:"-"`
+	syncedAt  time.Time            `wire:"-"`
+}
+
+// CasbinFilter 策略过滤条件
+type CasbinFilter struct {
+	TenantIDs []string // 租户ID列表(只加载这些租户的用户策略及其引用的角色策略，为空时不加载任何策略)
+}
+
+// NewUserRule 创建用户策略规则(g,user_id,role_id,tenant_id)
+func NewUserRule(tenantID, userID, roleID string) []string {
+	return []string{userID, roleID, tenantID}
+}
+
+// ParseUserRule 解析用户策略规则
+func ParseUserRule(rule []string) (tenantID, userID, roleID string, err error) {
+	if len(rule) < 3 || rule[0] == "" || rule[1] == "" {
+		return "", "", "", errors.Errorf("invalid casbin user rule: %v", rule)
+	}
+	return rule[2], rule[0], rule[1], nil
+}
+
+// NewRoleRule 创建角色策略规则(p,role_id,domain,path,method,effect,condition)
+func NewRoleRule(roleID, domain, path, method, effect, condition string) []string {
+	if effect != schema.EffectDeny {
+		effect = schema.EffectAllow
+	}
+	return []string{roleID, domain, path, method, effect, strings.TrimSpace(condition)}
+}
+
+// RoleRule 角色策略规则
+type RoleRule struct {
+	RoleID    string // 角色ID
+	Domain    string // 策略域
+	Path      string // 请求路径
+	Method    string // 请求方法
+	Effect    string // 权限效果
+	Condition string // 条件表达式
+}
+
+// NewPlatformAdminRule 创建平台管理员角色的内置策略规则(允许访问所有资源)
+func NewPlatformAdminRule(roleID string) []string {
+	return NewRoleRule(roleID, schema.GlobalRoleDomain, "/*", ".*", schema.EffectAllow, "")
+}
+
+// 检查是否是平台管理员的内置策略规则(不存储在角色菜单中)
+func isPlatformAdminRule(rr *RoleRule) bool {
+	return schema.CheckIsPlatformAdminRole(rr.RoleID) &&
+		rr.Domain == schema.GlobalRoleDomain && rr.Path == "/*" && rr.Method == ".*" &&
+		rr.Effect == schema.EffectAllow && rr.Condition == ""
+}
+
+// ParseRoleRule 解析角色策略规则
+func ParseRoleRule(rule []string) (*RoleRule, error) {
+	if len(rule) < 4 {
+		return nil, errors.Errorf("invalid casbin role rule: %v", rule)
+	}
+
+	item := &RoleRule{
+		RoleID: rule[0],
+		Domain: rule[1],
+		Path:   rule[2],
+		Method: rule[3],
+		Effect: schema.EffectAllow,
+	}
+	if len(rule) > 4 && rule[4] != "" {
+		if rule[4] != schema.EffectAllow && rule[4] != schema.EffectDeny {
+			return nil, errors.Errorf("invalid casbin role rule effect: %s", rule[4])
+		}
+		item.Effect = rule[4]
+	}
+	if len(rule) > 5 {
+		if err := ValidateCondition(rule[5]); err != nil {
+			return nil, err
+		}
+		item.Condition = strings.TrimSpace(rule[5])
+	}
+	return item, nil
+}
+
+// MatchRequest 检查规则是否匹配请求的租户、路径和方法(同默认模型的匹配器，不计算条件)
+func (r *RoleRule) MatchRequest(domain, path, method string) bool {
+	return util.KeyMatch(domain, r.Domain) && util.KeyMatch2(path, r.Path) && util.RegexMatch(method, r.Method)
 }
 
 // LoadPolicy loads all policy rules from the storage.
 func (a *CasbinAdapter) LoadPolicy(model casbinModel.Model) error {
 	ctx := context.Background()
+	a.applyPendingModel(model)
 	err := a.loadRolePolicy(ctx, model)
 	if err != nil {
 		logger.Errorf(ctx, "Load casbin role policy error: %s", err.Error())
@@ -43,101 +149,1133 @@ func (a *CasbinAdapter) LoadPolicy(model casbinModel.Model) error {
 		return err
 	}
 
+	a.setFilter(nil)
+	a.clearDecisionCache()
+	return nil
+}
+
+// LoadFilteredPolicy loads only policy rules that match the filter.
+func (a *CasbinAdapter) LoadFilteredPolicy(model casbinModel.Model, filter interface{}) error {
+	var f *CasbinFilter
+	switch v := filter.(type) {
+	case nil:
+		return a.LoadPolicy(model)
+	case CasbinFilter:
+		f = &v
+	case *CasbinFilter:
+		f = v
+	default:
+		return errors.Errorf("invalid casbin filter type: %T", filter)
+	}
+
+	ctx := context.Background()
+	roleRules, userRules, err := a.queryFilteredPolicy(ctx, f)
+	if err != nil {
+		return err
+	}
+
+	a.applyPendingModel(model)
+	loadPolicyRules("p", roleRules, model)
+	loadPolicyRules("g", userRules, model)
+	a.setFilter(f)
+	a.clearDecisionCache()
+	return nil
+}
+
+// 查询过滤条件中租户的用户策略以及相关的角色策略(包括用户角色的祖先角色)
+func (a *CasbinAdapter) queryFilteredPolicy(ctx context.Context, f *CasbinFilter) ([][]string, [][]string, error) {
+	// 未指定租户时不加载(queryUserPolicy不指定租户时会查询全部用户)
+	if len(f.TenantIDs) == 0 {
+		return nil, nil, nil
+	}
+
+	userRules, err := a.queryUserPolicy(ctx, f.TenantIDs...)
+	if err != nil {
+		logger.Errorf(ctx, "Load casbin user policy error: %s", err.Error())
+		return nil, nil, err
+	}
+
+	var roleIDs []string
+	mRoleIDs := make(map[string]struct{})
+	for _, rule := range userRules {
+		_, _, roleID, _ := ParseUserRule(rule)
+		if _, ok := mRoleIDs[roleID]; !ok {
+			mRoleIDs[roleID] = struct{}{}
+			roleIDs = append(roleIDs, roleID)
+		}
+	}
+
+	inheritRules, err := a.queryRoleInheritPolicy(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "Load casbin role inherit policy error: %s", err.Error())
+		return nil, nil, err
+	}
+	ancestors, inheritRules := inheritedRoles(roleIDs, inheritRules)
+	roleIDs = append(roleIDs, ancestors...)
+	userRules = append(userRules, inheritRules...)
+
+	var roleRules [][]string
+	if len(roleIDs) > 0 {
+		roleRules, err = a.queryRolePolicy(ctx, roleIDs...)
+		if err != nil {
+			logger.Errorf(ctx, "Load casbin role policy error: %s", err.Error())
+			return nil, nil, err
+		}
+	}
+	return roleRules, userRules, nil
+}
+
+// IsFiltered returns true if the loaded policy has been filtered.
+func (a *CasbinAdapter) IsFiltered() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.filtered
+}
+
+func (a *CasbinAdapter) setFilter(f *CasbinFilter) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.filtered = f != nil
+	a.tenantIDs = make(map[string]struct{})
+	if f != nil {
+		for _, tenantID := range f.TenantIDs {
+			a.tenantIDs[tenantID] = struct{}{}
+		}
+	}
+}
+
+// 获取当前已加载的租户过滤条件
+func (a *CasbinAdapter) currentFilter() *CasbinFilter {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if !a.filtered {
+		return nil
+	}
+	f := &CasbinFilter{}
+	for tenantID := range a.tenantIDs {
+		f.TenantIDs = append(f.TenantIDs, tenantID)
+	}
+	return f
+}
+
+// IsTenantLoaded 检查租户策略是否已加载
+func (a *CasbinAdapter) IsTenantLoaded(tenantID string) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if !a.filtered {
+		return true
+	}
+	_, ok := a.tenantIDs[tenantID]
+	return ok
+}
+
+// 标记租户策略已加载
+func (a *CasbinAdapter) addLoadedTenant(tenantID string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.tenantIDs[tenantID] = struct{}{}
+}
+
+// LoadTenantPolicy 按需加载租户策略(仅在过滤加载模式下生效)
+//
+// 只把该租户的用户策略及其引用的角色策略增量加入enforcer，不重新加载已加载的租户；
+// 同一时间只加载一个租户，避免并发请求重复查询。
+func (a *CasbinAdapter) LoadTenantPolicy(e *casbin.SyncedEnforcer, tenantID string) error {
+	a.loading.Lock()
+	defer a.loading.Unlock()
+
+	if !a.IsFiltered() || a.IsTenantLoaded(tenantID) {
+		return nil
+	}
+
+	roleRules, userRules, err := a.queryFilteredPolicy(context.Background(), &CasbinFilter{
+		TenantIDs: []string{tenantID},
+	})
+	if err != nil {
+		return err
+	}
+
+	// 已加载的规则(如其他租户共用的角色策略)由enforcer忽略
+	for _, rule := range roleRules {
+		if err := a.applyRule(rule, e.AddPolicy); err != nil {
+			return err
+		}
+	}
+	for _, rule := range userRules {
+		if err := a.applyRule(rule, e.AddGroupingPolicy); err != nil {
+			return err
+		}
+	}
+	a.addLoadedTenant(tenantID)
+	return nil
+}
+
+// ReloadPolicy 重新加载策略(过滤加载模式下只重新加载已加载的租户)
+func (a *CasbinAdapter) ReloadPolicy(e *casbin.SyncedEnforcer) error {
+	a.loading.Lock()
+	defer a.loading.Unlock()
+
+	if f := a.currentFilter(); f != nil {
+		return e.LoadFilteredPolicy(f)
+	}
+	return e.LoadPolicy()
+}
+
+// LoadTenantPolicy 按需加载租户策略
+func LoadTenantPolicy(e *casbin.SyncedEnforcer, tenantID string) error {
+	if a, ok := e.GetAdapter().(*CasbinAdapter); ok {
+		return a.LoadTenantPolicy(e, tenantID)
+	}
 	return nil
 }
 
-// 加载角色策略(p,role_id,path,method)
+// ReloadPolicy 重新加载策略
+func ReloadPolicy(e *casbin.SyncedEnforcer) error {
+	if a, ok := e.GetAdapter().(*CasbinAdapter); ok {
+		return a.ReloadPolicy(e)
+	}
+	return e.LoadPolicy()
+}
+
+func loadPolicyRules(ptype string, rules [][]string, m casbinModel.Model) {
+	for _, rule := range rules {
+		line := strings.Join(append([]string{ptype}, rule...), ",")
+		persist.LoadPolicyLine(line, m)
+	}
+}
+
+// 加载角色策略(p,role_id,domain,path,method,effect,condition)
 func (a *CasbinAdapter) loadRolePolicy(ctx context.Context, m casbinModel.Model) error {
+	rules, err := a.queryRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	loadPolicyRules("p", rules, m)
+	return nil
+}
+
+// 查询角色策略(可指定角色ID列表)
+func (a *CasbinAdapter) queryRolePolicy(ctx context.Context, roleIDs ...string) ([][]string, error) {
 	roleResult, err := a.RoleModel.Query(ctx, schema.RoleQueryParam{
+		IDs:    roleIDs,
 		Status: 1,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	} else if len(roleResult.Data) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	roleMenuResult, err := a.RoleMenuModel.Query(ctx, schema.RoleMenuQueryParam{})
+	roleMenuResult, err := a.RoleMenuModel.Query(ctx, schema.RoleMenuQueryParam{
+		RoleIDs: roleIDs,
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	mRoleMenus := roleMenuResult.Data.ToRoleIDMap()
 
 	menuResourceResult, err := a.MenuResourceModel.Query(ctx, schema.MenuActionResourceQueryParam{})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	mMenuResources := menuResourceResult.Data.ToActionIDMap()
 
+	var rules [][]string
 	for _, item := range roleResult.Data {
 		mcache := make(map[string]struct{})
-		if rms, ok := mRoleMenus[item.ID]; ok {
-			for _, actionID := range rms.ToActionIDs() {
-				if mrs, ok := mMenuResources[actionID]; ok {
-					for _, mr := range mrs {
-						if mr.Path == "" || mr.Method == "" {
-							continue
-						} else if _, ok := mcache[mr.Path+mr.Method]; ok {
-							continue
-						}
-						mcache[mr.Path+mr.Method] = struct{}{}
-						line := fmt.Sprintf("p,%s,%s,%s", item.ID, mr.Path, mr.Method)
-						persist.LoadPolicyLine(line, m)
-					}
+		for _, rm := range mRoleMenus[item.ID] {
+			effect := rm.GetEffect()
+			for _, mr := range mMenuResources[rm.ActionID] {
+				k := mr.Path + mr.Method + effect + rm.Condition
+				if mr.Path == "" || mr.Method == "" {
+					continue
+				} else if _, ok := mcache[k]; ok {
+					continue
 				}
+				mcache[k] = struct{}{}
+				rules = append(rules, NewRoleRule(item.ID, item.Domain(), mr.Path, mr.Method, effect, rm.Condition))
 			}
 		}
+
+		if schema.CheckIsPlatformAdminRole(item.ID) {
+			rules = append(rules, NewPlatformAdminRule(item.ID))
+		}
 	}
 
-	return nil
+	return rules, nil
 }
 
-// 加载用户策略(g,user_id,role_id)
+// 加载用户策略(g,user_id,role_id,tenant_id)及角色继承策略(g,role_id,parent_id,domain)
 func (a *CasbinAdapter) loadUserPolicy(ctx context.Context, m casbinModel.Model) error {
-	userResult, err := a.UserModel.Query(ctx, schema.UserQueryParam{
-		Status: 1,
-	})
+	rules, err := a.queryGroupingPolicy(ctx)
 	if err != nil {
 		return err
-	} else if len(userResult.Data) > 0 {
-		userRoleResult, err := a.UserRoleModel.Query(ctx, schema.UserRoleQueryParam{})
+	}
+	loadPolicyRules("g", rules, m)
+	return nil
+}
+
+// 查询全部用户策略及角色继承策略
+func (a *CasbinAdapter) queryGroupingPolicy(ctx context.Context) ([][]string, error) {
+	rules, err := a.queryUserPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	inheritRules, err := a.queryRoleInheritPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(rules, inheritRules...), nil
+}
+
+// 启用紧急访问账户时，配置文件中的root用户在根租户下拥有平台管理员角色
+func rootUserPolicy(tenantIDs ...string) [][]string {
+	roleID := config.C.PlatformAdminRole.ID
+	if !config.C.Root.Enable || roleID == "" {
+		return nil
+	}
+
+	loaded := len(tenantIDs) == 0
+	for _, tenantID := range tenantIDs {
+		if tenantID == schema.RootTenantID {
+			loaded = true
+		}
+	}
+	if !loaded {
+		return nil
+	}
+	return [][]string{NewUserRule(schema.RootTenantID, schema.GetRootUser().ID, roleID)}
+}
+
+// 查询用户策略(可指定租户ID列表)
+func (a *CasbinAdapter) queryUserPolicy(ctx context.Context, tenantIDs ...string) ([][]string, error) {
+	rules := rootUserPolicy(tenantIDs...)
+
+	var users schema.Users
+	if len(tenantIDs) == 0 {
+		userResult, err := a.UserModel.Query(ctx, schema.UserQueryParam{
+			Status: 1,
+		})
 		if err != nil {
-			return err
+			return nil, err
+		}
+		users = userResult.Data
+	} else {
+		for _, tenantID := range tenantIDs {
+			if tenantID == "" {
+				continue
+			}
+			userResult, err := a.UserModel.Query(ctx, schema.UserQueryParam{
+				Status:   1,
+				TenantID: tenantID,
+			})
+			if err != nil {
+				return nil, err
+			}
+			users = append(users, userResult.Data...)
 		}
+	}
 
-		mUserRoles := userRoleResult.Data.ToUserIDMap()
-		for _, uitem := range userResult.Data {
-			if urs, ok := mUserRoles[uitem.ID]; ok {
-				for _, ur := range urs {
-					line := fmt.Sprintf("g,%s::%s,%s", uitem.TenantID, ur.UserID, ur.RoleID)
-					persist.LoadPolicyLine(line, m)
-				}
+	userRules, err := a.queryUsersPolicy(ctx, users)
+	if err != nil {
+		return nil, err
+	}
+	return append(rules, userRules...), nil
+}
+
+// 查询指定用户的用户策略
+func (a *CasbinAdapter) queryUsersPolicy(ctx context.Context, users schema.Users) ([][]string, error) {
+	if len(users) == 0 {
+		return nil, nil
+	}
+
+	userRoleResult, err := a.UserRoleModel.Query(ctx, schema.UserRoleQueryParam{
+		UserIDs: users.ToIDs(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 只加载当前有效的授权，有效期授权到期时由定时任务增量同步
+	var rules [][]string
+	mUserRoles := userRoleResult.Data.ValidAt(time.Now()).ToUserIDMap()
+	for _, uitem := range users {
+		if urs, ok := mUserRoles[uitem.ID]; ok {
+			for _, ur := range urs {
+				rules = append(rules, NewUserRule(uitem.TenantID, ur.UserID, ur.RoleID))
 			}
 		}
 	}
+	return rules, nil
+}
 
-	return nil
+// 查询存储中的策略
+func (a *CasbinAdapter) queryPolicy(ctx context.Context, ptype string) ([][]string, error) {
+	switch ptype {
+	case "p":
+		return a.queryRolePolicy(ctx)
+	case "g":
+		return a.queryGroupingPolicy(ctx)
+	}
+	return nil, errors.Errorf("unsupported casbin policy type: %s", ptype)
 }
 
 // SavePolicy saves all policy rules to the storage.
 func (a *CasbinAdapter) SavePolicy(model casbinModel.Model) error {
+	if a.IsFiltered() {
+		return errors.New("cannot save a filtered policy")
+	}
+
+	ctx := context.Background()
+	delta := new(PolicyDelta)
+	err := a.TransModel.Exec(ctx, func(ctx context.Context) error {
+		for sec, ptypes := range map[string][]string{"p": {"p"}, "g": {"g"}} {
+			for _, ptype := range ptypes {
+				var rules [][]string
+				if ast, ok := model[sec][ptype]; ok {
+					rules = ast.Policy
+				}
+
+				oldRules, err := a.queryPolicy(ctx, ptype)
+				if err != nil {
+					return err
+				}
+
+				addRules, delRules := compareRules(oldRules, rules)
+				if err := a.removeRules(ctx, ptype, delRules); err != nil {
+					return err
+				}
+				if err := a.addRules(ctx, ptype, addRules); err != nil {
+					return err
+				}
+				delta.merge(newRuleDelta(ptype, addRules, delRules))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	a.publish(delta)
 	return nil
 }
 
+func compareRules(oldRules, newRules [][]string) (addList, delList [][]string) {
+	mOldRules := make(map[string][]string)
+	for _, rule := range oldRules {
+		mOldRules[strings.Join(rule, ",")] = rule
+	}
+
+	for _, rule := range newRules {
+		k := strings.Join(rule, ",")
+		if _, ok := mOldRules[k]; ok {
+			delete(mOldRules, k)
+			continue
+		}
+		addList = append(addList, rule)
+	}
+
+	for _, rule := range mOldRules {
+		delList = append(delList, rule)
+	}
+	return
+}
+
 // AddPolicy adds a policy rule to the storage.
 // This is part of the Auto-Save feature.
 func (a *CasbinAdapter) AddPolicy(sec string, ptype string, rule []string) error {
+	return a.AddPolicies(sec, ptype, [][]string{rule})
+}
+
+// AddPolicies adds policy rules to the storage.
+// This is part of the Auto-Save feature.
+func (a *CasbinAdapter) AddPolicies(sec string, ptype string, rules [][]string) error {
+	if len(rules) == 0 || a.consumeApplying(rules) {
+		return nil
+	}
+
+	err := a.TransModel.Exec(context.Background(), func(ctx context.Context) error {
+		return a.addRules(ctx, ptype, rules)
+	})
+	if err != nil {
+		return err
+	}
+
+	a.publish(newRuleDelta(ptype, rules, nil))
 	return nil
 }
 
 // RemovePolicy removes a policy rule from the storage.
 // This is part of the Auto-Save feature.
 func (a *CasbinAdapter) RemovePolicy(sec string, ptype string, rule []string) error {
+	return a.RemovePolicies(sec, ptype, [][]string{rule})
+}
+
+// RemovePolicies removes policy rules from the storage.
+// This is part of the Auto-Save feature.
+func (a *CasbinAdapter) RemovePolicies(sec string, ptype string, rules [][]string) error {
+	if len(rules) == 0 || a.consumeApplying(rules) {
+		return nil
+	}
+
+	err := a.TransModel.Exec(context.Background(), func(ctx context.Context) error {
+		return a.removeRules(ctx, ptype, rules)
+	})
+	if err != nil {
+		return err
+	}
+
+	a.publish(newRuleDelta(ptype, nil, rules))
 	return nil
 }
 
 // RemoveFilteredPolicy removes policy rules that match the filter from the storage.
 // This is part of the Auto-Save feature.
 func (a *CasbinAdapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	var delRules [][]string
+	err := a.TransModel.Exec(context.Background(), func(ctx context.Context) error {
+		rules, err := a.queryPolicy(ctx, ptype)
+		if err != nil {
+			return err
+		}
+
+		for _, rule := range rules {
+			if matchRule(rule, fieldIndex, fieldValues...) {
+				delRules = append(delRules, rule)
+			}
+		}
+		return a.removeRules(ctx, ptype, delRules)
+	})
+	if err != nil {
+		return err
+	}
+
+	a.publish(newRuleDelta(ptype, nil, delRules))
+	return nil
+}
+
+// 检查规则是否匹配过滤条件(空值匹配任意值)
+func matchRule(rule []string, fieldIndex int, fieldValues ...string) bool {
+	for i, v := range fieldValues {
+		if v == "" {
+			continue
+		}
+		if fieldIndex+i >= len(rule) || rule[fieldIndex+i] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *CasbinAdapter) addRules(ctx context.Context, ptype string, rules [][]string) error {
+	switch ptype {
+	case "p":
+		return a.addRoleRules(ctx, rules)
+	case "g":
+		for _, rule := range rules {
+			role, err := a.getRuleRole(ctx, rule)
+			if err != nil {
+				return err
+			} else if role != nil {
+				err = a.addRoleInheritRule(ctx, role, rule)
+			} else {
+				err = a.addUserRule(ctx, rule)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return errors.Errorf("unsupported casbin policy type: %s", ptype)
+}
+
+func (a *CasbinAdapter) removeRules(ctx context.Context, ptype string, rules [][]string) error {
+	switch ptype {
+	case "p":
+		return a.removeRoleRules(ctx, rules)
+	case "g":
+		for _, rule := range rules {
+			role, err := a.getRuleRole(ctx, rule)
+			if err != nil {
+				return err
+			} else if role != nil {
+				err = a.removeRoleInheritRule(ctx, rule)
+			} else {
+				err = a.removeUserRule(ctx, rule)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return errors.Errorf("unsupported casbin policy type: %s", ptype)
+}
+
+// 查询全部菜单动作及其资源
+func (a *CasbinAdapter) queryRuleActions(ctx context.Context) (map[string]*schema.MenuAction, error) {
+	menuActionResult, err := a.MenuActionModel.Query(ctx, schema.MenuActionQueryParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	menuResourceResult, err := a.MenuResourceModel.Query(ctx, schema.MenuActionResourceQueryParam{})
+	if err != nil {
+		return nil, err
+	}
+	menuActionResult.Data.FillResources(menuResourceResult.Data.ToActionIDMap())
+
+	mActions := make(map[string]*schema.MenuAction)
+	for _, item := range menuActionResult.Data {
+		mActions[item.ID] = item
+	}
+	return mActions, nil
+}
+
+// 解析一批角色策略(忽略平台管理员的内置策略)，返回规则及以规范形式为键的规则集合
+func parseRoleRules(rules [][]string) ([]*RoleRule, map[string]struct{}, error) {
+	var list []*RoleRule
+	mRules := make(map[string]struct{})
+	for _, rule := range rules {
+		rr, err := ParseRoleRule(rule)
+		if err != nil {
+			return nil, nil, err
+		} else if isPlatformAdminRule(rr) {
+			continue
+		}
+		list = append(list, rr)
+		mRules[rr.key(rr.Path, rr.Method)] = struct{}{}
+	}
+	return list, mRules, nil
+}
+
+// 生成规则以指定资源替换后的规范形式键
+func (r *RoleRule) key(path, method string) string {
+	return strings.Join(NewRoleRule(r.RoleID, r.Domain, path, method, r.Effect, r.Condition), ",")
+}
+
+// 检查菜单动作是否包含规则的资源(contains)，以及动作的全部资源是否都在规则集合中(covered)
+// 菜单动作授权时会同时授予其下的全部资源，只有覆盖全部资源的一批规则才能完整地授予或撤销该动作
+func (r *RoleRule) matchAction(action *schema.MenuAction, mRules map[string]struct{}) (contains, covered bool) {
+	covered = true
+	for _, mr := range action.Resources {
+		if mr.Path == "" || mr.Method == "" {
+			continue
+		}
+		if mr.Path == r.Path && mr.Method == r.Method {
+			contains = true
+		}
+		if _, ok := mRules[r.key(mr.Path, mr.Method)]; !ok {
+			covered = false
+		}
+	}
+	return
+}
+
+// 批量添加角色策略：将(path,method)所属的菜单动作以指定的效果授权给角色
+// 动作下的其他资源也必须在同一批规则中，否则拒绝添加(避免授予多于请求的权限)
+func (a *CasbinAdapter) addRoleRules(ctx context.Context, rules [][]string) error {
+	rrs, mRules, err := parseRoleRules(rules)
+	if err != nil {
+		return err
+	} else if len(rrs) == 0 {
+		return nil
+	}
+
+	mActions, err := a.queryRuleActions(ctx)
+	if err != nil {
+		return err
+	}
+
+	mRoles := make(map[string]*schema.Role)
+	mRoleMenus := make(map[string]schema.RoleMenus)
+	for _, rr := range rrs {
+		role, ok := mRoles[rr.RoleID]
+		if !ok {
+			role, err = a.RoleModel.Get(ctx, rr.RoleID)
+			if err != nil {
+				return err
+			}
+
+			roleMenuResult, err := a.RoleMenuModel.Query(ctx, schema.RoleMenuQueryParam{
+				RoleID: rr.RoleID,
+			})
+			if err != nil {
+				return err
+			}
+			mRoles[rr.RoleID] = role
+			mRoleMenus[rr.RoleID] = roleMenuResult.Data
+		}
+
+		if role == nil {
+			return errors.Errorf("casbin rule role not found: %s", rr.RoleID)
+		} else if role.Domain() != rr.Domain {
+			return errors.Errorf("casbin rule role %s does not belong to domain %s", rr.RoleID, rr.Domain)
+		}
+
+		// 角色已以相同效果和条件拥有任一包含该资源的动作时无需重复授权
+		granted := false
+		for _, rm := range mRoleMenus[rr.RoleID] {
+			action, ok := mActions[rm.ActionID]
+			if !ok || rm.GetEffect() != rr.Effect || rm.Condition != rr.Condition {
+				continue
+			}
+			if contains, _ := rr.matchAction(action, mRules); contains {
+				granted = true
+				break
+			}
+		}
+		if granted {
+			continue
+		}
+
+		var target *schema.MenuAction
+		partial := false
+		for _, action := range mActions {
+			contains, covered := rr.matchAction(action, mRules)
+			if !contains {
+				continue
+			} else if !covered {
+				partial = true
+				continue
+			} else if target == nil || action.ID < target.ID {
+				target = action
+			}
+		}
+		if target == nil {
+			if partial {
+				return errors.Errorf("casbin rule %s %s of role %s is granted by menu action together with other resources, add all resources of the action",
+					rr.Method, rr.Path, rr.RoleID)
+			}
+			return errors.Errorf("no menu action resource matches %s %s", rr.Method, rr.Path)
+		}
+
+		item := schema.RoleMenu{
+			ID:        iutil.NewID(),
+			RoleID:    rr.RoleID,
+			MenuID:    target.MenuID,
+			ActionID:  target.ID,
+			Effect:    rr.Effect,
+			Condition: rr.Condition,
+		}
+		err = a.RoleMenuModel.Create(ctx, item)
+		if err != nil {
+			return err
+		}
+		mRoleMenus[rr.RoleID] = append(mRoleMenus[rr.RoleID], &item)
+	}
+	return nil
+}
+
+// 批量删除角色策略：撤销角色上以相同效果和条件包含(path,method)的菜单动作
+// 动作下的其他资源也必须在同一批规则中，否则拒绝删除(避免撤销未请求的权限)
+func (a *CasbinAdapter) removeRoleRules(ctx context.Context, rules [][]string) error {
+	rrs, mRules, err := parseRoleRules(rules)
+	if err != nil {
+		return err
+	} else if len(rrs) == 0 {
+		return nil
+	}
+
+	mActions, err := a.queryRuleActions(ctx)
+	if err != nil {
+		return err
+	}
+
+	mRoleMenus := make(map[string]schema.RoleMenus)
+	mDeleted := make(map[string]struct{})
+	for _, rr := range rrs {
+		roleMenus, ok := mRoleMenus[rr.RoleID]
+		if !ok {
+			roleMenuResult, err := a.RoleMenuModel.Query(ctx, schema.RoleMenuQueryParam{
+				RoleID: rr.RoleID,
+			})
+			if err != nil {
+				return err
+			}
+			roleMenus = roleMenuResult.Data
+			mRoleMenus[rr.RoleID] = roleMenus
+		}
+
+		for _, rm := range roleMenus {
+			action, ok := mActions[rm.ActionID]
+			if !ok || rm.GetEffect() != rr.Effect || rm.Condition != rr.Condition {
+				continue
+			}
+
+			contains, covered := rr.matchAction(action, mRules)
+			if !contains {
+				continue
+			} else if !covered {
+				return errors.Errorf("casbin rule %s %s of role %s is granted by menu action %s together with other resources, remove all resources of the action",
+					rr.Method, rr.Path, rr.RoleID, action.ID)
+			} else if _, ok := mDeleted[rm.ID]; ok {
+				continue
+			}
+
+			err := a.RoleMenuModel.Delete(ctx, rm.ID)
+			if err != nil {
+				return err
+			}
+			mDeleted[rm.ID] = struct{}{}
+		}
+	}
 	return nil
 }
+
+// 添加用户策略：为租户下的用户授权角色
+func (a *CasbinAdapter) addUserRule(ctx context.Context, rule []string) error {
+	tenantID, userID, roleID, err := ParseUserRule(rule)
+	if err != nil {
+		return err
+	}
+
+	// 紧急访问账户的角色来自配置文件，不存储在用户角色中
+	if schema.CheckIsRootUser(ctx, userID) {
+		return nil
+	}
+
+	user, err := a.UserModel.Get(ctx, userID)
+	if err != nil {
+		return err
+	} else if user == nil {
+		return errors.Errorf("casbin rule user not found: %s", userID)
+	} else if user.TenantID != tenantID {
+		return errors.Errorf("casbin rule user %s does not belong to tenant %s", userID, tenantID)
+	} else if schema.CheckIsPlatformAdminRole(roleID) && tenantID != schema.RootTenantID {
+		return errors.New("casbin rule platform admin role can only be granted in the root tenant")
+	}
+
+	role, err := a.RoleModel.Get(ctx, roleID)
+	if err != nil {
+		return err
+	} else if role == nil {
+		return errors.Errorf("casbin rule role not found: %s", roleID)
+	} else if !role.IsVisibleTo(tenantID) {
+		return errors.Errorf("casbin rule role %s does not belong to tenant %s", roleID, tenantID)
+	}
+
+	userRoleResult, err := a.UserRoleModel.Query(ctx, schema.UserRoleQueryParam{
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	} else if ur, ok := userRoleResult.Data.ToMap()[roleID]; ok {
+		if ur.IsValidAt(time.Now()) {
+			return nil
+		}
+		// 未生效或已失效的授权替换为长期有效的授权
+		if err := a.UserRoleModel.Delete(ctx, ur.ID); err != nil {
+			return err
+		}
+	}
+
+	return a.UserRoleModel.Create(ctx, schema.UserRole{
+		ID:     iutil.NewID(),
+		UserID: userID,
+		RoleID: roleID,
+	})
+}
+
+// 删除用户策略：撤销用户的角色授权
+func (a *CasbinAdapter) removeUserRule(ctx context.Context, rule []string) error {
+	_, userID, roleID, err := ParseUserRule(rule)
+	if err != nil {
+		return err
+	}
+
+	// 紧急访问账户的角色来自配置文件，不存储在用户角色中
+	if schema.CheckIsRootUser(ctx, userID) {
+		return nil
+	}
+
+	userRoleResult, err := a.UserRoleModel.Query(ctx, schema.UserRoleQueryParam{
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, ur := range userRoleResult.Data {
+		if ur.RoleID != roleID {
+			continue
+		}
+		if err := a.UserRoleModel.Delete(ctx, ur.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QueryRolePolicy 查询角色的策略规则
+func (a *CasbinAdapter) QueryRolePolicy(ctx context.Context, roleID string) ([][]string, error) {
+	return a.queryRolePolicy(ctx, roleID)
+}
+
+// PolicyDelta 策略增量
+type PolicyDelta struct {
+	AddedPolicies    [][]string // 新增的角色策略(p)
+	RemovedPolicies  [][]string // 删除的角色策略(p)
+	AddedGroupings   [][]string // 新增的用户策略(g)
+	RemovedGroupings [][]string // 删除的用户策略(g)
+}
+
+// NewPolicyDelta 比较新旧规则生成策略增量
+func NewPolicyDelta(oldPolicies, newPolicies, oldGroupings, newGroupings [][]string) *PolicyDelta {
+	d := new(PolicyDelta)
+	d.AddedPolicies, d.RemovedPolicies = compareRules(oldPolicies, newPolicies)
+	d.AddedGroupings, d.RemovedGroupings = compareRules(oldGroupings, newGroupings)
+	return d
+}
+
+// IsEmpty 检查是否没有任何变更
+func (d *PolicyDelta) IsEmpty() bool {
+	return d == nil || len(d.AddedPolicies)+len(d.RemovedPolicies)+
+		len(d.AddedGroupings)+len(d.RemovedGroupings) == 0
+}
+
+func newRuleDelta(ptype string, addRules, delRules [][]string) *PolicyDelta {
+	d := new(PolicyDelta)
+	switch ptype {
+	case "p":
+		d.AddedPolicies, d.RemovedPolicies = addRules, delRules
+	case "g":
+		d.AddedGroupings, d.RemovedGroupings = addRules, delRules
+	}
+	return d
+}
+
+func (d *PolicyDelta) merge(o *PolicyDelta) {
+	d.AddedPolicies = append(d.AddedPolicies, o.AddedPolicies...)
+	d.RemovedPolicies = append(d.RemovedPolicies, o.RemovedPolicies...)
+	d.AddedGroupings = append(d.AddedGroupings, o.AddedGroupings...)
+	d.RemovedGroupings = append(d.RemovedGroupings, o.RemovedGroupings...)
+}
+
+// 以不回写存储的方式把一条已持久化的规则应用到enforcer
+//
+// 规则按本次调用传入切片的地址登记(casbin会把同一切片原样传给适配器)，而不是按规则内容，
+// 因此其他协程通过enforcer并发写入相同内容的规则时仍会正常持久化和广播。
+// 登记项在适配器回调中消费一次即失效。
+func (a *CasbinAdapter) applyRule(rule []string, fn func(params ...interface{}) (bool, error)) error {
+	if len(rule) == 0 {
+		return nil
+	}
+	rule = append([]string(nil), rule...)
+	k := &rule[0]
+
+	a.mutex.Lock()
+	if a.applying == nil {
+		a.applying = make(map[*string]struct{})
+	}
+	a.applying[k] = struct{}{}
+	a.mutex.Unlock()
+
+	defer func() {
+		a.mutex.Lock()
+		delete(a.applying, k)
+		a.mutex.Unlock()
+	}()
+
+	_, err := fn(rule)
+	return err
+}
+
+// 检查自动保存的规则是否来自applyRule，是则消费登记项
+func (a *CasbinAdapter) consumeApplying(rules [][]string) bool {
+	if len(rules) != 1 || len(rules[0]) == 0 {
+		return false
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	k := &rules[0][0]
+	if _, ok := a.applying[k]; ok {
+		delete(a.applying, k)
+		return true
+	}
+	return false
+}
+
+// ApplyPolicyDelta 将已持久化的策略增量应用到enforcer(不再回写存储)，并通知其他节点
+func (a *CasbinAdapter) ApplyPolicyDelta(e *casbin.SyncedEnforcer, d *PolicyDelta) error {
+	if err := a.applyPolicyDelta(e, d); err != nil {
+		return err
+	}
+	a.publish(d)
+	return nil
+}
+
+func (a *CasbinAdapter) applyPolicyDelta(e *casbin.SyncedEnforcer, d *PolicyDelta) error {
+	if d.IsEmpty() {
+		return nil
+	}
+
+	var addedGroupings, removedGroupings [][]string
+	for _, rule := range d.AddedGroupings {
+		if a.isRuleLoaded(rule) {
+			addedGroupings = append(addedGroupings, rule)
+		}
+	}
+	for _, rule := range d.RemovedGroupings {
+		if a.isRuleLoaded(rule) {
+			removedGroupings = append(removedGroupings, rule)
+		}
+	}
+
+	// 过滤加载模式下，新授权的角色及其祖先角色的策略可能尚未加载
+	addedPolicies := d.AddedPolicies
+	if a.IsFiltered() {
+		var roleIDs []string
+		for _, rule := range addedGroupings {
+			_, _, roleID, _ := ParseUserRule(rule)
+			roleIDs = append(roleIDs, roleID)
+		}
+		ancestors, _ := inheritedRoles(roleIDs, append(e.GetGroupingPolicy(), addedGroupings...))
+
+		mRoleIDs := make(map[string]struct{})
+		for _, roleID := range append(roleIDs, ancestors...) {
+			if _, ok := mRoleIDs[roleID]; ok || len(e.GetFilteredPolicy(0, roleID)) > 0 {
+				continue
+			}
+			mRoleIDs[roleID] = struct{}{}
+
+			rules, err := a.QueryRolePolicy(context.Background(), roleID)
+			if err != nil {
+				return err
+			}
+			addedPolicies = append(addedPolicies, rules...)
+		}
+	}
+
+	// 变更应用后失效受影响用户的决策缓存
+	defer a.invalidateDecisionCache(e, d)
+
+	for _, rule := range d.RemovedPolicies {
+		if err := a.applyRule(rule, e.RemovePolicy); err != nil {
+			return err
+		}
+	}
+	for _, rule := range addedPolicies {
+		if err := a.applyRule(rule, e.AddPolicy); err != nil {
+			return err
+		}
+	}
+	for _, rule := range removedGroupings {
+		if err := a.applyRule(rule, e.RemoveGroupingPolicy); err != nil {
+			return err
+		}
+	}
+	for _, rule := range addedGroupings {
+		if err := a.applyRule(rule, e.AddGroupingPolicy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 检查用户策略所属租户是否已加载(全局角色的继承策略在所有租户下生效)
+func (a *CasbinAdapter) isRuleLoaded(rule []string) bool {
+	tenantID, _, _, err := ParseUserRule(rule)
+	if err != nil {
+		return false
+	} else if tenantID == schema.GlobalRoleDomain {
+		return true
+	}
+	return a.IsTenantLoaded(tenantID)
+}
+
+// ApplyPolicyDelta 将已持久化的策略增量应用到enforcer
+func ApplyPolicyDelta(e *casbin.SyncedEnforcer, d *PolicyDelta) error {
+	if a, ok := e.GetAdapter().(*CasbinAdapter); ok {
+		return a.ApplyPolicyDelta(e, d)
+	}
+	return errors.New("casbin adapter does not support incremental policy")
+}
+
+// SetWatcher 设置策略变更监听，接收其他节点的变更并应用到enforcer
+//
+// 监听没有通过enforcer.SetWatcher挂载：enforcer只会调用不带规则的Update，
+// 增量消息由适配器在规则持久化后发出。
+func (a *CasbinAdapter) SetWatcher(e *casbin.SyncedEnforcer, w *watcher.Watcher) error {
+	a.mutex.Lock()
+	a.watcher = w
+	a.mutex.Unlock()
+
+	return w.SetUpdateCallback(func(payload string) {
+		ctx := context.Background()
+		msg, err := watcher.ParseMessage(payload)
+		if err != nil {
+			logger.Errorf(ctx, "Parse casbin watcher message error: %s", err.Error())
+			return
+		}
+
+		if msg.Method == watcher.MethodUpdate {
+			err = a.applyPolicyDelta(e, &PolicyDelta{
+				AddedPolicies:    msg.AddedPolicies,
+				RemovedPolicies:  msg.RemovedPolicies,
+				AddedGroupings:   msg.AddedGroupings,
+				RemovedGroupings: msg.RemovedGroupings,
+			})
+			if err == nil {
+				return
+			}
+			logger.Errorf(ctx, "Apply casbin watcher message error: %s", err.Error())
+		}
+
+		if err := a.ReloadPolicy(e); err != nil {
+			logger.Errorf(ctx, "Reload casbin policy error: %s", err.Error())
+		}
+	})
+}
+
+func (a *CasbinAdapter) getWatcher() *watcher.Watcher {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.watcher
+}
+
+// 通知其他节点增量更新策略
+func (a *CasbinAdapter) publish(d *PolicyDelta) {
+	w := a.getWatcher()
+	if w == nil || d.IsEmpty() {
+		return
+	}
+
+	err := w.Publish(&watcher.Message{
+		Method:           watcher.MethodUpdate,
+		AddedPolicies:    d.AddedPolicies,
+		RemovedPolicies:  d.RemovedPolicies,
+		AddedGroupings:   d.AddedGroupings,
+		RemovedGroupings: d.RemovedGroupings,
+	})
+	if err != nil {
+		logger.Errorf(context.Background(), "Publish casbin watcher message error: %s", err.Error())
+	}
+}
+
+// NotifyReload 通知其他节点全量重新加载策略
+func (a *CasbinAdapter) NotifyReload() {
+	w := a.getWatcher()
+	if w == nil {
+		return
+	}
+
+	if err := w.Update(); err != nil {
+		logger.Errorf(context.Background(), "Publish casbin watcher message error: %s", err.Error())
+	}
+}
+
+// NotifyReload 通知其他节点全量重新加载策略
+func NotifyReload(e *casbin.SyncedEnforcer) {
+	if a, ok := e.GetAdapter().(*CasbinAdapter); ok {
+		a.NotifyReload()
+	}
+}
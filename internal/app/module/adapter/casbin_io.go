@@ -0,0 +1,376 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"io"
+	"sort"
+	"strings"
+
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
+)
+
+// 预演导入时用于回滚事务
+var errDryRun = errors.New("casbin policy import dry run")
+
+// ExportPolicy 导出存储中的全部策略规则
+func (a *CasbinAdapter) ExportPolicy(ctx context.Context) (policies, groupings [][]string, err error) {
+	policies, err = a.queryRolePolicy(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	groupings, err = a.queryGroupingPolicy(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sortRules(policies)
+	sortRules(groupings)
+	return policies, groupings, nil
+}
+
+// 查询导入比较的基准策略(包括停用用户的授权，避免停用用户的授权被重复创建或无法删除)
+func (a *CasbinAdapter) queryImportBaseline(ctx context.Context) (policies, groupings [][]string, err error) {
+	policies, err = a.queryRolePolicy(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userResult, err := a.UserModel.Query(ctx, schema.UserQueryParam{})
+	if err != nil {
+		return nil, nil, err
+	}
+	userRules, err := a.queryUsersPolicy(ctx, userResult.Data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inheritRules, err := a.queryRoleInheritPolicy(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	groupings = append(append(rootUserPolicy(), userRules...), inheritRules...)
+	return policies, groupings, nil
+}
+
+// ImportPolicy 以导入的规则替换存储中的全部策略，返回实际产生的策略增量(不包括停用用户的授权)，
+// 以及导入后存储与导入规则的差异(Added为存储中多出的规则，Removed为未能导入的规则)
+// dryRun为true时在事务中校验并计算差异后回滚，不修改存储(预演不能在外部事务中调用)；
+// 非预演时存在差异则回滚并返回错误
+func (a *CasbinAdapter) ImportPolicy(ctx context.Context, policies, groupings [][]string, dryRun bool) (delta, mismatch *PolicyDelta, err error) {
+	// 校验并规范化导入的规则(效果缺省为allow)
+	policies, groupings, err = NormalizeRules(policies, groupings)
+	if err != nil {
+		return nil, nil, err
+	}
+	policies, groupings = uniqueRules(policies), uniqueRules(groupings)
+
+	err = a.TransModel.Exec(ctx, func(ctx context.Context) error {
+		oldPolicies, oldGroupings, err := a.ExportPolicy(ctx)
+		if err != nil {
+			return err
+		}
+
+		basePolicies, baseGroupings, err := a.queryImportBaseline(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, ptype := range []string{"p", "g"} {
+			baseRules, rules := basePolicies, policies
+			if ptype == "g" {
+				baseRules, rules = baseGroupings, groupings
+			}
+
+			addRules, delRules := compareRules(baseRules, rules)
+			if err := a.removeRules(ctx, ptype, delRules); err != nil {
+				return err
+			}
+			if err := a.addRules(ctx, ptype, addRules); err != nil {
+				return err
+			}
+		}
+
+		// 角色策略按菜单动作授权，以存储中实际的结果计算增量
+		newPolicies, newGroupings, err := a.ExportPolicy(ctx)
+		if err != nil {
+			return err
+		}
+		delta = NewPolicyDelta(oldPolicies, newPolicies, oldGroupings, newGroupings)
+
+		basePolicies, baseGroupings, err = a.queryImportBaseline(ctx)
+		if err != nil {
+			return err
+		}
+		mismatch = NewPolicyDelta(policies, basePolicies, groupings, baseGroupings)
+
+		if dryRun {
+			return errDryRun
+		} else if !mismatch.IsEmpty() {
+			return errors.Errorf("imported policy does not match the requested rules: %d unexpected, %d missing",
+				len(mismatch.AddedPolicies)+len(mismatch.AddedGroupings),
+				len(mismatch.RemovedPolicies)+len(mismatch.RemovedGroupings))
+		}
+		return nil
+	})
+	if err != nil && errors.Cause(err) != errDryRun {
+		return nil, nil, err
+	}
+
+	delta.sort()
+	mismatch.sort()
+	return delta, mismatch, nil
+}
+
+// EncodePolicyCSV 将策略规则编码为casbin CSV格式
+func EncodePolicyCSV(policies, groupings [][]string) []byte {
+	var buf bytes.Buffer
+	for _, rule := range policies {
+		buf.WriteString(FormatPolicyLine("p", rule))
+		buf.WriteByte('\n')
+	}
+	for _, rule := range groupings {
+		buf.WriteString(FormatPolicyLine("g", rule))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// FormatPolicyLine 格式化为casbin CSV策略行(包含逗号或引号的字段加引号)
+func FormatPolicyLine(ptype string, rule []string) string {
+	fields := make([]string, len(rule))
+	for i, v := range rule {
+		if strings.ContainsAny(v, ",\"\n") {
+			v = `"` + strings.Replace(v, `"`, `""`, -1) + `"`
+		}
+		fields[i] = v
+	}
+	return ptype + ", " + strings.Join(fields, ", ")
+}
+
+// DecodePolicyCSV 解析casbin CSV格式的策略规则(忽略空行和#注释)
+func DecodePolicyCSV(data []byte) (policies, groupings [][]string, err error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+
+		for i, v := range record {
+			record[i] = strings.TrimSpace(v)
+		}
+
+		switch record[0] {
+		case "p":
+			policies = append(policies, record[1:])
+		case "g":
+			groupings = append(groupings, record[1:])
+		default:
+			return nil, nil, errors.Errorf("invalid casbin policy line: %s", strings.Join(record, ", "))
+		}
+	}
+	return policies, groupings, nil
+}
+
+// NormalizeRules 校验并规范化策略规则
+func NormalizeRules(policies, groupings [][]string) ([][]string, [][]string, error) {
+	pList := make([][]string, len(policies))
+	for i, rule := range policies {
+		rr, err := ParseRoleRule(rule)
+		if err != nil {
+			return nil, nil, err
+		}
+		pList[i] = NewRoleRule(rr.RoleID, rr.Domain, rr.Path, rr.Method, rr.Effect, rr.Condition)
+	}
+
+	gList := make([][]string, len(groupings))
+	for i, rule := range groupings {
+		tenantID, userID, roleID, err := ParseUserRule(rule)
+		if err != nil {
+			return nil, nil, err
+		}
+		gList[i] = NewUserRule(tenantID, userID, roleID)
+	}
+	return pList, gList, nil
+}
+
+// 去除重复的规则
+func uniqueRules(rules [][]string) [][]string {
+	m := make(map[string]struct{})
+	var list [][]string
+	for _, rule := range rules {
+		k := strings.Join(rule, ",")
+		if _, ok := m[k]; ok {
+			continue
+		}
+		m[k] = struct{}{}
+		list = append(list, rule)
+	}
+	return list
+}
+
+func (d *PolicyDelta) sort() {
+	sortRules(d.AddedPolicies)
+	sortRules(d.RemovedPolicies)
+	sortRules(d.AddedGroupings)
+	sortRules(d.RemovedGroupings)
+}
+
+func sortRules(rules [][]string) {
+	sort.Slice(rules, func(i, j int) bool {
+		return strings.Join(rules[i], ",") < strings.Join(rules[j], ",")
+	})
+}
+
+// CommitPolicyDelta 在存储中逐条提交策略增量(删除和新增指定的规则)，返回实际产生的策略增量
+// dryRun为true时在事务中计算实际增量后回滚，不修改存储(预演不能在外部事务中调用)
+func (a *CasbinAdapter) CommitPolicyDelta(ctx context.Context, d *PolicyDelta, dryRun bool) (*PolicyDelta, error) {
+	var delta *PolicyDelta
+	err := a.TransModel.Exec(ctx, func(ctx context.Context) error {
+		scope, err := a.newDeltaScope(ctx, d)
+		if err != nil {
+			return err
+		}
+
+		oldPolicies, oldGroupings, err := scope.query(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := a.removeRules(ctx, "p", d.RemovedPolicies); err != nil {
+			return err
+		}
+		if err := a.addRules(ctx, "p", d.AddedPolicies); err != nil {
+			return err
+		}
+		if err := a.removeRules(ctx, "g", d.RemovedGroupings); err != nil {
+			return err
+		}
+		if err := a.addRules(ctx, "g", d.AddedGroupings); err != nil {
+			return err
+		}
+
+		// 角色策略按菜单动作授权，以存储中实际的结果计算增量
+		newPolicies, newGroupings, err := scope.query(ctx)
+		if err != nil {
+			return err
+		}
+		delta = NewPolicyDelta(oldPolicies, newPolicies, oldGroupings, newGroupings)
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && errors.Cause(err) != errDryRun {
+		return nil, err
+	}
+
+	delta.sort()
+	return delta, nil
+}
+
+// 策略增量涉及的角色和用户
+type deltaScope struct {
+	a           *CasbinAdapter
+	roleIDs     []string // 角色策略的角色
+	inheritIDs  []string // 角色继承策略的子角色
+	userIDs     []string // 用户策略的用户
+	includeRoot bool     // 是否包含紧急访问账户
+}
+
+func (a *CasbinAdapter) newDeltaScope(ctx context.Context, d *PolicyDelta) (*deltaScope, error) {
+	scope := &deltaScope{a: a}
+	for _, rules := range [][][]string{d.AddedPolicies, d.RemovedPolicies} {
+		for _, rule := range rules {
+			scope.roleIDs = append(scope.roleIDs, rule[0])
+		}
+	}
+
+	for _, rules := range [][][]string{d.AddedGroupings, d.RemovedGroupings} {
+		for _, rule := range rules {
+			role, err := a.getRuleRole(ctx, rule)
+			if err != nil {
+				return nil, err
+			} else if role != nil {
+				scope.inheritIDs = append(scope.inheritIDs, rule[0])
+			} else if schema.CheckIsRootUser(ctx, rule[0]) {
+				scope.includeRoot = true
+			} else {
+				scope.userIDs = append(scope.userIDs, rule[0])
+			}
+		}
+	}
+	return scope, nil
+}
+
+// 查询涉及的角色和用户在存储中的策略规则
+func (s *deltaScope) query(ctx context.Context) (policies, groupings [][]string, err error) {
+	if len(s.roleIDs) > 0 {
+		policies, err = s.a.queryRolePolicy(ctx, s.roleIDs...)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(s.inheritIDs) > 0 {
+		rules, err := s.a.queryRoleInheritPolicy(ctx, s.inheritIDs...)
+		if err != nil {
+			return nil, nil, err
+		}
+		groupings = append(groupings, rules...)
+	}
+
+	if s.includeRoot {
+		groupings = append(groupings, rootUserPolicy(schema.RootTenantID)...)
+	}
+
+	if len(s.userIDs) > 0 {
+		userResult, err := s.a.UserModel.Query(ctx, schema.UserQueryParam{
+			IDs:    s.userIDs,
+			Status: 1,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		rules, err := s.a.queryUsersPolicy(ctx, userResult.Data)
+		if err != nil {
+			return nil, nil, err
+		}
+		groupings = append(groupings, rules...)
+	}
+	return policies, groupings, nil
+}
+
+// Apply 在规则列表上应用策略增量，返回新的规则列表
+func (d *PolicyDelta) Apply(policies, groupings [][]string) ([][]string, [][]string) {
+	return patchRules(policies, d.AddedPolicies, d.RemovedPolicies),
+		patchRules(groupings, d.AddedGroupings, d.RemovedGroupings)
+}
+
+// 在规则列表上删除和新增规则
+func patchRules(rules, addRules, delRules [][]string) [][]string {
+	mDel := make(map[string]struct{})
+	for _, rule := range delRules {
+		mDel[strings.Join(rule, ",")] = struct{}{}
+	}
+
+	var list [][]string
+	for _, rule := range rules {
+		if _, ok := mDel[strings.Join(rule, ",")]; !ok {
+			list = append(list, rule)
+		}
+	}
+	return uniqueRules(append(list, addRules...))
+}
@@ -0,0 +1,185 @@
+package adapter
+
+import (
+	"context"
+	"testing"
+
+	"gin-casbin/internal/app/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodePolicyCSV(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		policies  [][]string
+		groupings [][]string
+		err       bool
+	}{
+		{
+			name: "rules",
+			data: "# exported\np, member, t1, /api/v1/users, GET, allow, \n\ng, alice, member, t1\n",
+			policies: [][]string{
+				{"member", "t1", "/api/v1/users", "GET", "allow", ""},
+			},
+			groupings: [][]string{
+				{"alice", "member", "t1"},
+			},
+		},
+		{
+			name: "quoted condition",
+			data: `p, member, t1, /api/v1/users, GET, allow, "target_id in (""a"", ""b"")"` + "\n",
+			policies: [][]string{
+				{"member", "t1", "/api/v1/users", "GET", "allow", `target_id in ("a", "b")`},
+			},
+		},
+		{name: "empty", data: ""},
+		{name: "invalid ptype", data: "x, member, t1\n", err: true},
+		{name: "invalid quote", data: "p, member, \"t1\n", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policies, groupings, err := DecodePolicyCSV([]byte(tt.data))
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.policies, policies)
+			assert.Equal(t, tt.groupings, groupings)
+
+			// 编码后再解析得到相同的规则
+			policies, groupings, err = DecodePolicyCSV(EncodePolicyCSV(policies, groupings))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.policies, policies)
+			assert.Equal(t, tt.groupings, groupings)
+		})
+	}
+}
+
+func TestNormalizeRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		policies  [][]string
+		groupings [][]string
+		expected  [][]string
+		err       bool
+	}{
+		{
+			name:     "default effect",
+			policies: [][]string{{"member", "t1", "/api/v1/users", "GET"}},
+			expected: [][]string{{"member", "t1", "/api/v1/users", "GET", "allow", ""}},
+		},
+		{
+			name:     "trim condition",
+			policies: [][]string{{"member", "t1", "/api/v1/users", "GET", "deny", " local_hour < 18 "}},
+			expected: [][]string{{"member", "t1", "/api/v1/users", "GET", "deny", "local_hour < 18"}},
+		},
+		{name: "short policy", policies: [][]string{{"member", "t1", "/api/v1/users"}}, err: true},
+		{name: "invalid effect", policies: [][]string{{"member", "t1", "/api/v1/users", "GET", "maybe"}}, err: true},
+		{name: "invalid condition", policies: [][]string{{"member", "t1", "/api/v1/users", "GET", "allow", "local_hour <"}}, err: true},
+		{name: "short grouping", groupings: [][]string{{"alice", "member"}}, err: true},
+		{name: "empty subject", groupings: [][]string{{"", "member", "t1"}}, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policies, _, err := NormalizeRules(tt.policies, tt.groupings)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, policies)
+		})
+	}
+}
+
+func TestCasbinAdapterImportPolicy(t *testing.T) {
+	a, cleanFunc := newTestAdapter(t)
+	defer cleanFunc()
+
+	ctx := context.Background()
+	oldPolicies, oldGroupings, err := a.ExportPolicy(ctx)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	// 为member增加删除权限，撤销bob的授权，保留停用用户carol的授权
+	policies := append(oldPolicies, []string{"member", "t1", "/api/v1/users/:id", "DELETE"})
+	groupings := [][]string{
+		NewUserRule("t1", "alice", "member"),
+		NewUserRule("t2", "carol", "auditor"),
+		NewRoleInheritRule("t1", "member", "base"),
+	}
+	expected := &PolicyDelta{
+		AddedPolicies:    [][]string{NewRoleRule("member", "t1", "/api/v1/users/:id", "DELETE", "allow", "")},
+		RemovedGroupings: [][]string{NewUserRule("t2", "bob", "auditor")},
+	}
+
+	delta, mismatch, err := a.ImportPolicy(ctx, policies, groupings, true)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, delta)
+	assert.True(t, mismatch.IsEmpty())
+
+	// 预演不修改存储
+	newPolicies, newGroupings, err := a.ExportPolicy(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, oldPolicies, newPolicies)
+	assert.Equal(t, oldGroupings, newGroupings)
+
+	delta, mismatch, err = a.ImportPolicy(ctx, policies, groupings, false)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, delta)
+	assert.True(t, mismatch.IsEmpty())
+
+	newPolicies, newGroupings, err = a.ExportPolicy(ctx)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, expected.AddedPolicies, NewPolicyDelta(oldPolicies, newPolicies, nil, nil).AddedPolicies)
+	assert.NotContains(t, newGroupings, NewUserRule("t2", "bob", "auditor"))
+	userRoles, err := a.UserRoleModel.Query(ctx, schema.UserRoleQueryParam{UserID: "carol"})
+	assert.Nil(t, err)
+	assert.Len(t, userRoles.Data, 1)
+
+	// 重复导入不产生变更
+	delta, mismatch, err = a.ImportPolicy(ctx, policies, groupings, false)
+	assert.Nil(t, err)
+	assert.True(t, delta.IsEmpty())
+	assert.True(t, mismatch.IsEmpty())
+}
+
+func TestCasbinAdapterImportPolicyActionResources(t *testing.T) {
+	a, cleanFunc := newTestAdapter(t)
+	defer cleanFunc()
+
+	// 角色策略按菜单动作授权：同一动作的两个资源只导入其中一个时拒绝导入
+	ctx := context.Background()
+	assert.Nil(t, a.MenuResourceModel.Create(ctx, schema.MenuActionResource{
+		ID: "res_query_one", ActionID: "users_query", Method: "GET", Path: "/api/v1/users/:id",
+	}))
+	oldPolicies, oldGroupings, err := a.ExportPolicy(ctx)
+	if !assert.Nil(t, err) {
+		return
+	}
+	policies := append(oldPolicies, []string{"member", "t1", "/api/v1/users", "GET"})
+
+	_, _, err = a.ImportPolicy(ctx, policies, oldGroupings, true)
+	assert.NotNil(t, err)
+	_, _, err = a.ImportPolicy(ctx, policies, oldGroupings, false)
+	assert.NotNil(t, err)
+	newPolicies, _, err := a.ExportPolicy(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, oldPolicies, newPolicies)
+
+	// 同时导入动作的全部资源
+	policies = append(policies, []string{"member", "t1", "/api/v1/users/:id", "GET"})
+	delta, mismatch, err := a.ImportPolicy(ctx, policies, oldGroupings, false)
+	assert.Nil(t, err)
+	assert.True(t, mismatch.IsEmpty())
+	assert.ElementsMatch(t, [][]string{
+		NewRoleRule("member", "t1", "/api/v1/users", "GET", "allow", ""),
+		NewRoleRule("member", "t1", "/api/v1/users/:id", "GET", "allow", ""),
+	}, delta.AddedPolicies)
+}
@@ -0,0 +1,298 @@
+package adapter
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gin-casbin/internal/app/config"
+	igorm "gin-casbin/internal/app/model/impl/gorm"
+	gmodel "gin-casbin/internal/app/model/impl/gorm/model"
+	"gin-casbin/internal/app/schema"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// 基于sqlite的测试存储
+//
+// 菜单动作：users_query(GET /api/v1/users)、users_delete(DELETE /api/v1/users/:id)
+// 角色：base(全局)、member(t1，继承base)、auditor(t2)
+// 用户：alice(t1,member)、bob(t2,auditor)、carol(t2,停用,auditor)
+func newTestAdapter(t *testing.T) (*CasbinAdapter, func()) {
+	dir, err := ioutil.TempDir("", "adapter")
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+
+	config.C.Gorm.DBType = "sqlite3"
+	db, cleanFunc, err := igorm.NewDB(&igorm.Config{
+		DBType:       "sqlite3",
+		DSN:          filepath.Join(dir, "test.db"),
+		MaxOpenConns: 1,
+	})
+	if !assert.Nil(t, err) || !assert.Nil(t, igorm.AutoMigrate(db)) {
+		os.RemoveAll(dir)
+		t.FailNow()
+	}
+
+	a := &CasbinAdapter{
+		TransModel:         &gmodel.Trans{DB: db},
+		RoleModel:          &gmodel.Role{DB: db},
+		RoleMenuModel:      &gmodel.RoleMenu{DB: db},
+		MenuActionModel:    &gmodel.MenuAction{DB: db},
+		MenuResourceModel:  &gmodel.MenuActionResource{DB: db},
+		UserModel:          &gmodel.User{DB: db},
+		UserRoleModel:      &gmodel.UserRole{DB: db},
+		UserTenantModel:    &gmodel.UserTenant{DB: db},
+		RoleParentModel:    &gmodel.RoleParent{DB: db},
+		RoleElevationModel: &gmodel.RoleElevation{DB: db},
+	}
+
+	ctx := context.Background()
+	for _, err := range []error{
+		a.MenuActionModel.Create(ctx, schema.MenuAction{ID: "users_query", MenuID: "users", Code: "query", Name: "query"}),
+		a.MenuActionModel.Create(ctx, schema.MenuAction{ID: "users_delete", MenuID: "users", Code: "delete", Name: "delete"}),
+		a.MenuResourceModel.Create(ctx, schema.MenuActionResource{ID: "res_query", ActionID: "users_query", Method: "GET", Path: "/api/v1/users"}),
+		a.MenuResourceModel.Create(ctx, schema.MenuActionResource{ID: "res_delete", ActionID: "users_delete", Method: "DELETE", Path: "/api/v1/users/:id"}),
+		a.RoleModel.Create(ctx, schema.Role{ID: "base", Name: "base", Status: 1}),
+		a.RoleModel.Create(ctx, schema.Role{ID: "member", Name: "member", Status: 1, TenantID: "t1"}),
+		a.RoleModel.Create(ctx, schema.Role{ID: "auditor", Name: "auditor", Status: 1, TenantID: "t2"}),
+		a.RoleMenuModel.Create(ctx, schema.RoleMenu{ID: "rm_base", RoleID: "base", MenuID: "users", ActionID: "users_query"}),
+		a.RoleMenuModel.Create(ctx, schema.RoleMenu{ID: "rm_auditor", RoleID: "auditor", MenuID: "users", ActionID: "users_query"}),
+		a.RoleParentModel.Create(ctx, schema.RoleParent{ID: "rp_member", RoleID: "member", ParentID: "base"}),
+		a.UserModel.Create(ctx, schema.User{ID: "alice", UserName: "alice", Status: 1, TenantID: "t1"}),
+		a.UserModel.Create(ctx, schema.User{ID: "bob", UserName: "bob", Status: 1, TenantID: "t2"}),
+		a.UserModel.Create(ctx, schema.User{ID: "carol", UserName: "carol", Status: 2, TenantID: "t2"}),
+		a.UserRoleModel.Create(ctx, schema.UserRole{ID: "ur_alice", UserID: "alice", RoleID: "member"}),
+		a.UserRoleModel.Create(ctx, schema.UserRole{ID: "ur_bob", UserID: "bob", RoleID: "auditor"}),
+		a.UserRoleModel.Create(ctx, schema.UserRole{ID: "ur_carol", UserID: "carol", RoleID: "auditor"}),
+	} {
+		if !assert.Nil(t, err) {
+			t.FailNow()
+		}
+	}
+
+	return a, func() {
+		cleanFunc()
+		os.RemoveAll(dir)
+	}
+}
+
+// 创建使用适配器的enforcer(同injector.InitCasbin，不加载策略)
+func newTestEnforcer(t *testing.T, a *CasbinAdapter) *casbin.SyncedEnforcer {
+	e, err := casbin.NewSyncedEnforcer("../../../../configs/model.conf")
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	if !assert.Nil(t, e.InitWithModelAndAdapter(e.GetModel(), nil)) {
+		t.FailNow()
+	}
+	RegisterConditionFunction(e)
+	RegisterRoleFunction(e)
+	e.SetAdapter(a)
+	return e
+}
+
+func enforce(t *testing.T, e *casbin.SyncedEnforcer, userID, tenantID, path, method string) bool {
+	allowed, err := e.Enforce(userID, tenantID, path, method, Attributes{})
+	assert.Nil(t, err)
+	return allowed
+}
+
+func TestCasbinAdapterLoadPolicy(t *testing.T) {
+	a, cleanFunc := newTestAdapter(t)
+	defer cleanFunc()
+
+	e := newTestEnforcer(t, a)
+	if !assert.Nil(t, e.LoadPolicy()) {
+		return
+	}
+
+	assert.ElementsMatch(t, [][]string{
+		NewRoleRule("base", "*", "/api/v1/users", "GET", "allow", ""),
+		NewRoleRule("auditor", "t2", "/api/v1/users", "GET", "allow", ""),
+	}, e.GetPolicy())
+	// 停用用户的授权不加载
+	assert.ElementsMatch(t, [][]string{
+		NewUserRule("t1", "alice", "member"),
+		NewUserRule("t2", "bob", "auditor"),
+		NewRoleInheritRule("t1", "member", "base"),
+	}, e.GetGroupingPolicy())
+
+	assert.True(t, enforce(t, e, "alice", "t1", "/api/v1/users", "GET"))
+	assert.False(t, enforce(t, e, "alice", "t2", "/api/v1/users", "GET"))
+	assert.True(t, enforce(t, e, "bob", "t2", "/api/v1/users", "GET"))
+	assert.False(t, enforce(t, e, "carol", "t2", "/api/v1/users", "GET"))
+}
+
+func TestCasbinAdapterWriteThrough(t *testing.T) {
+	a, cleanFunc := newTestAdapter(t)
+	defer cleanFunc()
+
+	e := newTestEnforcer(t, a)
+	if !assert.Nil(t, e.LoadPolicy()) {
+		return
+	}
+
+	ctx := context.Background()
+	deleteRule := NewRoleRule("member", "t1", "/api/v1/users/:id", "DELETE", "allow", "")
+
+	ok, err := e.AddPolicy(deleteRule)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	rules, err := a.QueryRolePolicy(ctx, "member")
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{deleteRule}, rules)
+	assert.True(t, enforce(t, e, "alice", "t1", "/api/v1/users/1", "DELETE"))
+
+	ok, err = e.AddGroupingPolicy(NewUserRule("t2", "carol", "auditor"))
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	ok, err = e.RemoveGroupingPolicy(NewUserRule("t2", "bob", "auditor"))
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	userRoles, err := a.UserRoleModel.Query(ctx, schema.UserRoleQueryParam{UserIDs: []string{"bob", "carol"}})
+	assert.Nil(t, err)
+	if assert.Len(t, userRoles.Data, 1) {
+		assert.Equal(t, "carol", userRoles.Data[0].UserID)
+	}
+
+	ok, err = e.RemovePolicy(deleteRule)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	rules, err = a.QueryRolePolicy(ctx, "member")
+	assert.Nil(t, err)
+	assert.Empty(t, rules)
+
+	// 不属于任何菜单动作的规则无法持久化，enforcer不做修改
+	ok, err = e.AddPolicy(NewRoleRule("member", "t1", "/api/v1/roles", "GET", "allow", ""))
+	assert.False(t, ok)
+	assert.NotNil(t, err)
+	assert.False(t, enforce(t, e, "alice", "t1", "/api/v1/roles", "GET"))
+}
+
+func TestCasbinAdapterWriteThroughActionResources(t *testing.T) {
+	a, cleanFunc := newTestAdapter(t)
+	defer cleanFunc()
+
+	// 查询动作包含两个资源，授权时同时授予
+	ctx := context.Background()
+	assert.Nil(t, a.MenuResourceModel.Create(ctx, schema.MenuActionResource{
+		ID: "res_query_one", ActionID: "users_query", Method: "GET", Path: "/api/v1/users/:id",
+	}))
+	e := newTestEnforcer(t, a)
+	if !assert.Nil(t, e.LoadPolicy()) {
+		return
+	}
+
+	listRule := NewRoleRule("member", "t1", "/api/v1/users", "GET", "allow", "")
+	getRule := NewRoleRule("member", "t1", "/api/v1/users/:id", "GET", "allow", "")
+
+	// 只添加动作的部分资源时拒绝，不授予多于请求的权限
+	ok, err := e.AddPolicy(listRule)
+	assert.False(t, ok)
+	assert.NotNil(t, err)
+	rules, err := a.QueryRolePolicy(ctx, "member")
+	assert.Nil(t, err)
+	assert.Empty(t, rules)
+
+	ok, err = e.AddPolicies([][]string{listRule, getRule})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	rules, err = a.QueryRolePolicy(ctx, "member")
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, [][]string{listRule, getRule}, rules)
+
+	// 只删除动作的部分资源时拒绝，不撤销未请求的权限
+	ok, err = e.RemovePolicy(NewRoleRule("auditor", "t2", "/api/v1/users", "GET", "allow", ""))
+	assert.False(t, ok)
+	assert.NotNil(t, err)
+	assert.True(t, enforce(t, e, "bob", "t2", "/api/v1/users/1", "GET"))
+
+	ok, err = e.RemoveFilteredPolicy(0, "auditor")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	rules, err = a.QueryRolePolicy(ctx, "auditor")
+	assert.Nil(t, err)
+	assert.Empty(t, rules)
+
+	// 存储与enforcer中的策略一致
+	policies, _, err := a.ExportPolicy(ctx)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, e.GetPolicy(), policies)
+}
+
+func TestCasbinAdapterApplyPolicyDelta(t *testing.T) {
+	a, cleanFunc := newTestAdapter(t)
+	defer cleanFunc()
+
+	e := newTestEnforcer(t, a)
+	if !assert.Nil(t, e.LoadPolicy()) {
+		return
+	}
+
+	// 增量应用到enforcer时不回写存储
+	d := &PolicyDelta{
+		AddedPolicies:    [][]string{NewRoleRule("member", "t1", "/api/v1/users/:id", "DELETE", "allow", "")},
+		RemovedGroupings: [][]string{NewUserRule("t2", "bob", "auditor")},
+	}
+	assert.Nil(t, a.ApplyPolicyDelta(e, d))
+	assert.True(t, enforce(t, e, "alice", "t1", "/api/v1/users/1", "DELETE"))
+	assert.False(t, enforce(t, e, "bob", "t2", "/api/v1/users", "GET"))
+
+	policies, groupings, err := a.ExportPolicy(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, policies, 2)
+	assert.Contains(t, groupings, NewUserRule("t2", "bob", "auditor"))
+}
+
+func TestCasbinAdapterLoadFilteredPolicy(t *testing.T) {
+	a, cleanFunc := newTestAdapter(t)
+	defer cleanFunc()
+
+	e := newTestEnforcer(t, a)
+	if !assert.Nil(t, e.LoadFilteredPolicy(&CasbinFilter{})) {
+		return
+	}
+
+	// 未指定租户时不加载任何策略
+	assert.True(t, a.IsFiltered())
+	assert.Empty(t, e.GetPolicy())
+	assert.Empty(t, e.GetGroupingPolicy())
+	assert.False(t, a.IsTenantLoaded("t1"))
+
+	// 按需加载租户的用户策略及其角色(包括祖先角色)的策略
+	assert.Nil(t, a.LoadTenantPolicy(e, "t1"))
+	assert.True(t, a.IsTenantLoaded("t1"))
+	assert.False(t, a.IsTenantLoaded("t2"))
+	assert.ElementsMatch(t, [][]string{
+		NewRoleRule("base", "*", "/api/v1/users", "GET", "allow", ""),
+	}, e.GetPolicy())
+	assert.ElementsMatch(t, [][]string{
+		NewUserRule("t1", "alice", "member"),
+		NewRoleInheritRule("t1", "member", "base"),
+	}, e.GetGroupingPolicy())
+	assert.True(t, enforce(t, e, "alice", "t1", "/api/v1/users", "GET"))
+
+	// 增量加载其他租户时保留已加载的策略
+	assert.Nil(t, a.LoadTenantPolicy(e, "t2"))
+	assert.Len(t, e.GetPolicy(), 2)
+	assert.Len(t, e.GetGroupingPolicy(), 3)
+	assert.True(t, enforce(t, e, "bob", "t2", "/api/v1/users", "GET"))
+
+	// 未加载租户的授权变更不应用到enforcer
+	assert.Nil(t, e.LoadFilteredPolicy(&CasbinFilter{TenantIDs: []string{"t1"}}))
+	assert.Nil(t, a.ApplyPolicyDelta(e, &PolicyDelta{
+		AddedGroupings: [][]string{NewUserRule("t2", "carol", "auditor")},
+	}))
+	assert.False(t, e.HasGroupingPolicy(NewUserRule("t2", "carol", "auditor")))
+
+	// 重新加载只加载已加载的租户
+	assert.Nil(t, a.ReloadPolicy(e))
+	assert.True(t, a.IsTenantLoaded("t1"))
+	assert.False(t, a.IsTenantLoaded("t2"))
+	assert.Len(t, e.GetGroupingPolicy(), 2)
+}
@@ -0,0 +1,122 @@
+package adapter
+
+import (
+	"strings"
+	"sync"
+
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
+
+	"github.com/Knetic/govaluate"
+	"github.com/casbin/casbin/v2"
+)
+
+// 策略条件中常用的属性名
+const (
+	AttrUserID         = "user_id"          // 当前用户ID
+	AttrTenantID       = "tenant_id"        // 当前租户ID
+	AttrLocalHour      = "local_hour"       // 租户时区的当前小时(0-23)
+	AttrLocalWeekday   = "local_weekday"    // 租户时区的星期(0:周日)
+	AttrTargetID       = "target_id"        // 目标资源ID
+	AttrTargetTenantID = "target_tenant_id" // 目标资源所属租户
+	AttrTargetIsAdmin  = "target_is_admin"  // 目标用户是否租户管理员
+)
+
+// ConditionFunctionName 模型中的条件匹配函数名: condMatch(r.attrs, p.cond, p.eft)
+const ConditionFunctionName = "condMatch"
+
+// Attributes 策略条件使用的请求属性和资源属性
+type Attributes map[string]interface{}
+
+type conditionProbe struct{}
+
+// ConditionProbe 作为请求属性时，允许策略的条件视为满足，拒绝策略的条件视为不满足
+// (查询有效权限时用于判断请求在满足条件时能否被允许)
+var ConditionProbe interface{} = conditionProbe{}
+
+// Get 获取属性(实现govaluate.Parameters)
+func (a Attributes) Get(name string) (interface{}, error) {
+	if v, ok := a[name]; ok {
+		return v, nil
+	}
+	return nil, errors.Errorf("attribute not found: %s", name)
+}
+
+// 已编译的条件表达式
+var conditions sync.Map
+
+func compileCondition(cond string) (*govaluate.EvaluableExpression, error) {
+	if v, ok := conditions.Load(cond); ok {
+		return v.(*govaluate.EvaluableExpression), nil
+	}
+
+	expr, err := govaluate.NewEvaluableExpression(cond)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid policy condition: %s", cond)
+	}
+	conditions.Store(cond, expr)
+	return expr, nil
+}
+
+// ValidateCondition 校验条件表达式语法
+func ValidateCondition(cond string) error {
+	if strings.TrimSpace(cond) == "" {
+		return nil
+	}
+	_, err := compileCondition(cond)
+	return err
+}
+
+// EvalCondition 计算条件表达式(空表达式为真)
+func EvalCondition(cond string, attrs Attributes) (bool, error) {
+	if strings.TrimSpace(cond) == "" {
+		return true, nil
+	}
+
+	expr, err := compileCondition(cond)
+	if err != nil {
+		return false, err
+	}
+
+	v, err := expr.Eval(attrs)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	b, ok := v.(bool)
+	if !ok {
+		return false, errors.Errorf("policy condition is not a boolean expression: %s", cond)
+	}
+	return b, nil
+}
+
+// 条件匹配函数，条件无法计算(如缺少属性)时失败关闭：拒绝策略视为匹配，允许策略视为不匹配
+func conditionMatch(args ...interface{}) (interface{}, error) {
+	if len(args) != 3 {
+		return false, errors.Errorf("%s expects 3 arguments, got %d", ConditionFunctionName, len(args))
+	}
+
+	cond, _ := args[1].(string)
+	eft, _ := args[2].(string)
+
+	var attrs Attributes
+	switch v := args[0].(type) {
+	case conditionProbe:
+		return strings.TrimSpace(cond) == "" || eft != schema.EffectDeny, nil
+	case Attributes:
+		attrs = v
+	case map[string]interface{}:
+		attrs = v
+	}
+
+	ok, err := EvalCondition(cond, attrs)
+	if err != nil {
+		return eft == schema.EffectDeny, nil
+	}
+	return ok, nil
+}
+
+// RegisterConditionFunction 为enforcer注册条件匹配函数
+func RegisterConditionFunction(e *casbin.SyncedEnforcer) {
+	e.AddFunction(ConditionFunctionName, conditionMatch)
+}
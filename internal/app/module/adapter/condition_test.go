@@ -0,0 +1,56 @@
+package adapter
+
+import (
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionMatch(t *testing.T) {
+	policies := [][]string{
+		NewRoleRule("office", "t1", "/api/v1/users", "GET", "allow", "local_hour >= 9 && local_hour < 18"),
+		NewRoleRule("staff", "t1", "/api/v1/users", "GET", "allow", ""),
+		NewRoleRule("staff", "t1", "/api/v1/users/:id", "DELETE", "allow", ""),
+		NewRoleRule("staff", "t1", "/api/v1/users/:id", "DELETE", "deny", "target_is_admin"),
+		NewRoleRule("guest", "t1", "/api/v1/users", "GET", "deny", ""),
+	}
+	groupings := [][]string{
+		NewUserRule("t1", "u_office", "office"),
+		NewUserRule("t1", "u_staff", "staff"),
+		NewUserRule("t1", "u_guest", "staff"),
+		NewUserRule("t1", "u_guest", "guest"),
+	}
+	e, err := casbin.NewSyncedEnforcer("../../../../configs/model.conf", NewStaticAdapter(policies, groupings))
+	if !assert.Nil(t, err) {
+		return
+	}
+	RegisterConditionFunction(e)
+
+	tests := []struct {
+		name     string
+		user     string
+		path     string
+		method   string
+		attrs    interface{}
+		expected bool
+	}{
+		{"allow condition met", "u_office", "/api/v1/users", "GET", Attributes{AttrLocalHour: 10}, true},
+		{"allow condition not met", "u_office", "/api/v1/users", "GET", Attributes{AttrLocalHour: 20}, false},
+		{"allow condition missing attribute", "u_office", "/api/v1/users", "GET", Attributes{}, false},
+		{"allow condition probe", "u_office", "/api/v1/users", "GET", ConditionProbe, true},
+		{"deny condition met", "u_staff", "/api/v1/users/:id", "DELETE", Attributes{AttrTargetIsAdmin: true}, false},
+		{"deny condition not met", "u_staff", "/api/v1/users/:id", "DELETE", Attributes{AttrTargetIsAdmin: false}, true},
+		{"deny condition missing attribute", "u_staff", "/api/v1/users/:id", "DELETE", Attributes{}, false},
+		{"deny condition probe", "u_staff", "/api/v1/users/:id", "DELETE", ConditionProbe, true},
+		{"unconditional deny probe", "u_guest", "/api/v1/users", "GET", ConditionProbe, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := e.Enforce(tt.user, "t1", tt.path, tt.method, tt.attrs)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, allowed)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package adapter
+
+import (
+	"context"
+
+	"gin-casbin/internal/app/iutil"
+	"gin-casbin/internal/app/schema"
+	"gin-casbin/pkg/errors"
+
+	"github.com/casbin/casbin/v2"
+	defaultrolemanager "github.com/casbin/casbin/v2/rbac/default-role-manager"
+	"github.com/casbin/casbin/v2/util"
+)
+
+// RegisterRoleFunction 为enforcer注册角色域匹配函数(全局角色的继承关系以*为域，在所有租户下生效)
+func RegisterRoleFunction(e *casbin.SyncedEnforcer) {
+	if rm, ok := e.GetRoleManager().(*defaultrolemanager.RoleManager); ok {
+		rm.AddDomainMatchingFunc("keyMatch", util.KeyMatch)
+	}
+}
+
+// NewRoleInheritRule 创建角色继承策略规则(g,role_id,parent_id,domain)
+func NewRoleInheritRule(domain, roleID, parentID string) []string {
+	return []string{roleID, parentID, domain}
+}
+
+// 查询角色继承策略(只包含子角色和父角色都启用的继承关系，可指定涉及的角色ID)
+func (a *CasbinAdapter) queryRoleInheritPolicy(ctx context.Context, roleIDs ...string) ([][]string, error) {
+	result, err := a.RoleParentModel.Query(ctx, schema.RoleParentQueryParam{})
+	if err != nil {
+		return nil, err
+	} else if len(result.Data) == 0 {
+		return nil, nil
+	}
+
+	roleResult, err := a.RoleModel.Query(ctx, schema.RoleQueryParam{
+		IDs:    result.Data.ToRoleIDs(),
+		Status: 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	mRoles := roleResult.Data.ToMap()
+
+	mRoleIDs := make(map[string]struct{})
+	for _, roleID := range roleIDs {
+		mRoleIDs[roleID] = struct{}{}
+	}
+
+	var rules [][]string
+	for _, item := range result.Data {
+		role, ok := mRoles[item.RoleID]
+		if !ok {
+			continue
+		} else if _, ok := mRoles[item.ParentID]; !ok {
+			continue
+		}
+
+		if len(mRoleIDs) > 0 {
+			_, ok1 := mRoleIDs[item.RoleID]
+			_, ok2 := mRoleIDs[item.ParentID]
+			if !ok1 && !ok2 {
+				continue
+			}
+		}
+		rules = append(rules, NewRoleInheritRule(role.Domain(), item.RoleID, item.ParentID))
+	}
+	return rules, nil
+}
+
+// QueryRoleInheritPolicy 查询角色作为子角色或父角色的继承策略规则
+func (a *CasbinAdapter) QueryRoleInheritPolicy(ctx context.Context, roleID string) ([][]string, error) {
+	return a.queryRoleInheritPolicy(ctx, roleID)
+}
+
+// 获取角色的祖先角色ID以及相关的继承规则
+func inheritedRoles(roleIDs []string, inheritRules [][]string) ([]string, [][]string) {
+	mParents := make(map[string][][]string)
+	for _, rule := range inheritRules {
+		mParents[rule[0]] = append(mParents[rule[0]], rule)
+	}
+
+	mRoleIDs := make(map[string]struct{})
+	for _, roleID := range roleIDs {
+		mRoleIDs[roleID] = struct{}{}
+	}
+
+	var (
+		ancestors []string
+		rules     [][]string
+	)
+	queue := append([]string{}, roleIDs...)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, rule := range mParents[name] {
+			rules = append(rules, rule)
+			if _, ok := mRoleIDs[rule[1]]; ok {
+				continue
+			}
+			mRoleIDs[rule[1]] = struct{}{}
+			ancestors = append(ancestors, rule[1])
+			queue = append(queue, rule[1])
+		}
+	}
+	return ancestors, rules
+}
+
+// 查询规则主体对应的角色(主体不是角色时返回nil)
+func (a *CasbinAdapter) getRuleRole(ctx context.Context, rule []string) (*schema.Role, error) {
+	if len(rule) == 0 || rule[0] == "" {
+		return nil, nil
+	}
+	return a.RoleModel.Get(ctx, rule[0])
+}
+
+// 添加角色继承策略：角色继承父角色(拒绝形成继承环)
+func (a *CasbinAdapter) addRoleInheritRule(ctx context.Context, role *schema.Role, rule []string) error {
+	domain, roleID, parentID, err := ParseUserRule(rule)
+	if err != nil {
+		return err
+	} else if role.Domain() != domain {
+		return errors.Errorf("casbin rule role %s does not belong to domain %s", roleID, domain)
+	}
+
+	parent, err := a.RoleModel.Get(ctx, parentID)
+	if err != nil {
+		return err
+	} else if parent == nil {
+		return errors.Errorf("casbin rule role not found: %s", parentID)
+	} else if !role.CanInherit(parent) {
+		return errors.Errorf("casbin rule role %s can not inherit role %s", roleID, parentID)
+	}
+
+	result, err := a.RoleParentModel.Query(ctx, schema.RoleParentQueryParam{})
+	if err != nil {
+		return err
+	}
+
+	parentIDs := []string{parentID}
+	for _, item := range result.Data.ToRoleIDMap()[roleID] {
+		if item.ParentID == parentID {
+			return nil
+		}
+		parentIDs = append(parentIDs, item.ParentID)
+	}
+	if result.Data.WouldCycle(roleID, parentIDs) {
+		return errors.Errorf("casbin rule role %s inheriting role %s creates a cycle", roleID, parentID)
+	}
+
+	return a.RoleParentModel.Create(ctx, schema.RoleParent{
+		ID:       iutil.NewID(),
+		RoleID:   roleID,
+		ParentID: parentID,
+	})
+}
+
+// 删除角色继承策略
+func (a *CasbinAdapter) removeRoleInheritRule(ctx context.Context, rule []string) error {
+	_, roleID, parentID, err := ParseUserRule(rule)
+	if err != nil {
+		return err
+	}
+
+	result, err := a.RoleParentModel.Query(ctx, schema.RoleParentQueryParam{
+		RoleID: roleID,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range result.Data {
+		if item.ParentID != parentID {
+			continue
+		}
+		if err := a.RoleParentModel.Delete(ctx, item.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package adapter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInheritedRoles(t *testing.T) {
+	inheritRules := [][]string{
+		NewRoleInheritRule("t1", "member", "staff"),
+		NewRoleInheritRule("t1", "staff", "base"),
+		NewRoleInheritRule("t1", "auditor", "base"),
+		NewRoleInheritRule("*", "base", "viewer"),
+		NewRoleInheritRule("t1", "loop_a", "loop_b"),
+		NewRoleInheritRule("t1", "loop_b", "loop_a"),
+	}
+
+	tests := []struct {
+		name      string
+		roleIDs   []string
+		ancestors []string
+		rules     int
+	}{
+		{"chain", []string{"member"}, []string{"staff", "base", "viewer"}, 3},
+		{"shared ancestor", []string{"member", "auditor"}, []string{"staff", "base", "viewer"}, 4},
+		{"requested ancestor", []string{"member", "base"}, []string{"staff", "viewer"}, 3},
+		{"root role", []string{"viewer"}, nil, 0},
+		{"cycle", []string{"loop_a"}, []string{"loop_b"}, 2},
+		{"empty", nil, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ancestors, rules := inheritedRoles(tt.roleIDs, inheritRules)
+			assert.Equal(t, tt.ancestors, ancestors)
+			assert.Len(t, rules, tt.rules)
+		})
+	}
+}
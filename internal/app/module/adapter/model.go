@@ -0,0 +1,128 @@
+package adapter
+
+import (
+	"context"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"gin-casbin/pkg/errors"
+
+	"github.com/casbin/casbin/v2"
+	casbinModel "github.com/casbin/casbin/v2/model"
+)
+
+// ModelValidator 校验加载了新模型和当前策略的临时enforcer
+type ModelValidator func(e *casbin.SyncedEnforcer) error
+
+// ReloadModel 重新加载模型文件
+// 新模型和当前策略先加载到临时enforcer中校验，校验通过后在重新加载策略时原子替换模型，校验失败时保留原模型
+func ReloadModel(e *casbin.SyncedEnforcer, modelFile string, validate ModelValidator) error {
+	a, ok := e.GetAdapter().(*CasbinAdapter)
+	if !ok {
+		return errors.New("casbin adapter does not support model reload")
+	}
+
+	// 只读取一次模型文件，校验和替换使用同一份文本，避免校验后文件再次变更
+	b, err := ioutil.ReadFile(modelFile)
+	if err != nil {
+		return errors.Wrapf(err, "read casbin model %s", modelFile)
+	}
+	text := string(b)
+
+	m, err := casbinModel.NewModelFromString(text)
+	if err != nil {
+		return errors.Wrapf(err, "load casbin model %s", modelFile)
+	} else if err := checkRoleDefinition(e.GetModel(), m); err != nil {
+		return err
+	}
+
+	candidate, err := casbin.NewSyncedEnforcer(m)
+	if err != nil {
+		return errors.Wrapf(err, "load casbin model %s", modelFile)
+	}
+	RegisterConditionFunction(candidate)
+	RegisterRoleFunction(candidate)
+
+	if err := a.loadCurrentPolicy(candidate.GetModel()); err != nil {
+		return err
+	} else if err := candidate.BuildRoleLinks(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if validate != nil {
+		if err := validate(candidate); err != nil {
+			return errors.Wrapf(err, "validate casbin model %s", modelFile)
+		}
+	}
+
+	// 临时enforcer的模型已包含策略，替换时使用同一份文本重新构建的模型
+	pending, err := casbinModel.NewModelFromString(text)
+	if err != nil {
+		return errors.Wrapf(err, "load casbin model %s", modelFile)
+	}
+	a.setPendingModel(pending)
+	defer a.setPendingModel(nil)
+	return a.ReloadPolicy(e)
+}
+
+// 角色定义对应enforcer的角色管理器，重新加载模型时不允许变更
+func checkRoleDefinition(oldModel, newModel casbinModel.Model) error {
+	keys := func(m casbinModel.Model) string {
+		var list []string
+		for ptype, ast := range m["g"] {
+			list = append(list, ptype+"="+ast.Value)
+		}
+		sort.Strings(list)
+		return strings.Join(list, ";")
+	}
+
+	if keys(oldModel) != keys(newModel) {
+		return errors.New("casbin model role definition can not be changed without restart")
+	}
+	return nil
+}
+
+// 将当前已加载范围的策略加载到模型(不改变适配器状态)
+func (a *CasbinAdapter) loadCurrentPolicy(m casbinModel.Model) error {
+	ctx := context.Background()
+	if f := a.currentFilter(); f != nil {
+		roleRules, userRules, err := a.queryFilteredPolicy(ctx, f)
+		if err != nil {
+			return err
+		}
+		loadPolicyRules("p", roleRules, m)
+		loadPolicyRules("g", userRules, m)
+		return nil
+	}
+
+	if err := a.loadRolePolicy(ctx, m); err != nil {
+		return err
+	}
+	return a.loadUserPolicy(ctx, m)
+}
+
+func (a *CasbinAdapter) setPendingModel(m casbinModel.Model) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.pending = m
+}
+
+// 加载策略前替换待生效的模型定义(在enforcer加载策略的锁内执行，保证模型与策略同时生效)
+func (a *CasbinAdapter) applyPendingModel(model casbinModel.Model) {
+	a.mutex.Lock()
+	m := a.pending
+	a.pending = nil
+	a.mutex.Unlock()
+
+	if m == nil {
+		return
+	}
+	for _, sec := range []string{"r", "p", "g", "e", "m"} {
+		if ast, ok := m[sec]; ok {
+			model[sec] = ast
+		} else {
+			delete(model, sec)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package adapter
+
+import (
+	"context"
+	"time"
+
+	"gin-casbin/internal/app/schema"
+
+	"github.com/casbin/casbin/v2"
+)
+
+// SyncTimeBoundPolicy 同步有效期授权的用户策略：到达生效时间时添加，到达失效时间时删除(不全量加载)，
+// 并将已到期授权对应的临时提权申请标记为已到期
+// 首次运行时检查全部有效期授权，之后只检查上次运行以来到达生效或失效时间的授权
+func (a *CasbinAdapter) SyncTimeBoundPolicy(e *casbin.SyncedEnforcer) error {
+	ctx := context.Background()
+	now := time.Now()
+	params := schema.UserRoleQueryParam{
+		TimeBound: true,
+	}
+	if last := a.lastSyncedAt(); !last.IsZero() {
+		params.BoundaryFrom = &last
+		params.BoundaryTo = &now
+	}
+
+	result, err := a.UserRoleModel.Query(ctx, params)
+	if err != nil {
+		return err
+	} else if len(result.Data) == 0 {
+		a.setSyncedAt(now)
+		return nil
+	}
+
+	userResult, err := a.UserModel.Query(ctx, schema.UserQueryParam{
+		IDs: result.Data.ToUserIDs(),
+	})
+	if err != nil {
+		return err
+	}
+	mUsers := userResult.Data.ToMap()
+
+	var expiredIDs []string
+	d := new(PolicyDelta)
+	for _, ur := range result.Data {
+		if ur.GetStatus(now) == schema.UserRoleExpired {
+			expiredIDs = append(expiredIDs, ur.ID)
+		}
+
+		user, ok := mUsers[ur.UserID]
+		if !ok || !a.IsTenantLoaded(user.TenantID) {
+			continue
+		}
+
+		rule := NewUserRule(user.TenantID, ur.UserID, ur.RoleID)
+		valid := user.Status == 1 && ur.IsValidAt(now)
+		if exists := e.HasGroupingPolicy(rule); valid && !exists {
+			d.AddedGroupings = append(d.AddedGroupings, rule)
+		} else if !valid && exists {
+			d.RemovedGroupings = append(d.RemovedGroupings, rule)
+		}
+	}
+
+	// 每个节点都运行定时任务，不需要通知其他节点
+	if err := a.applyPolicyDelta(e, d); err != nil {
+		return err
+	} else if err := a.expireElevations(ctx, expiredIDs, now); err != nil {
+		return err
+	}
+	a.setSyncedAt(now)
+	return nil
+}
+
+// 将已到期的用户角色对应的已批准临时提权申请标记为已到期
+func (a *CasbinAdapter) expireElevations(ctx context.Context, userRoleIDs []string, now time.Time) error {
+	if len(userRoleIDs) == 0 {
+		return nil
+	}
+
+	result, err := a.RoleElevationModel.Query(ctx, schema.RoleElevationQueryParam{
+		Status:      schema.ElevationApproved,
+		UserRoleIDs: userRoleIDs,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range result.Data {
+		if item.IsActiveAt(now) {
+			continue
+		}
+		item.Status = schema.ElevationExpired
+		if err := a.RoleElevationModel.Update(ctx, item.ID, *item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *CasbinAdapter) lastSyncedAt() time.Time {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.syncedAt
+}
+
+func (a *CasbinAdapter) setSyncedAt(t time.Time) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.syncedAt = t
+}
+
+// SyncTimeBoundPolicy 同步有效期授权的用户策略
+func SyncTimeBoundPolicy(e *casbin.SyncedEnforcer) error {
+	if a, ok := e.GetAdapter().(*CasbinAdapter); ok {
+		return a.SyncTimeBoundPolicy(e)
+	}
+	return nil
+}
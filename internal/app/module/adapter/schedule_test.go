@@ -0,0 +1,71 @@
+package adapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gin-casbin/internal/app/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncTimeBoundPolicy(t *testing.T) {
+	a, cleanFunc := newTestAdapter(t)
+	defer cleanFunc()
+
+	ctx := context.Background()
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	assert.Nil(t, a.UserRoleModel.Create(ctx, schema.UserRole{
+		ID: "ur_alice_base", UserID: "alice", RoleID: "base", ValidFrom: &past, ValidUntil: &future,
+	}))
+	assert.Nil(t, a.RoleElevationModel.Create(ctx, schema.RoleElevation{
+		ID: "re_alice_base", TenantID: "t1", UserID: "alice", RoleID: "base", Status: schema.ElevationApproved,
+		UserRoleID: "ur_alice_base", ValidFrom: &past, ValidUntil: &future,
+	}))
+
+	e := newTestEnforcer(t, a)
+	if !assert.Nil(t, e.LoadPolicy()) {
+		return
+	}
+	rule := NewUserRule("t1", "alice", "base")
+	assert.True(t, e.HasGroupingPolicy(rule))
+
+	// 首次运行检查全部有效期授权
+	assert.Nil(t, a.SyncTimeBoundPolicy(e))
+	assert.True(t, e.HasGroupingPolicy(rule))
+	assert.False(t, a.lastSyncedAt().IsZero())
+
+	// 失效时间不在同步窗口内的授权不检查
+	assert.Nil(t, a.UserRoleModel.Update(ctx, "ur_alice_base", schema.UserRole{
+		ID: "ur_alice_base", UserID: "alice", RoleID: "base", ValidFrom: &past, ValidUntil: &past,
+	}))
+	assert.Nil(t, a.SyncTimeBoundPolicy(e))
+	assert.True(t, e.HasGroupingPolicy(rule))
+
+	// 失效时间进入同步窗口时删除
+	until := time.Now().Add(time.Millisecond)
+	assert.Nil(t, a.UserRoleModel.Update(ctx, "ur_alice_base", schema.UserRole{
+		ID: "ur_alice_base", UserID: "alice", RoleID: "base", ValidFrom: &past, ValidUntil: &until,
+	}))
+	assert.Nil(t, a.RoleElevationModel.Update(ctx, "re_alice_base", schema.RoleElevation{
+		ID: "re_alice_base", TenantID: "t1", UserID: "alice", RoleID: "base", Status: schema.ElevationApproved,
+		UserRoleID: "ur_alice_base", ValidFrom: &past, ValidUntil: &until,
+	}))
+	time.Sleep(10 * time.Millisecond)
+	assert.Nil(t, a.SyncTimeBoundPolicy(e))
+	assert.False(t, e.HasGroupingPolicy(rule))
+
+	// 对应的临时提权申请标记为已到期
+	elevation, err := a.RoleElevationModel.Get(ctx, "re_alice_base")
+	assert.Nil(t, err)
+	if assert.NotNil(t, elevation) {
+		assert.Equal(t, schema.ElevationExpired, elevation.Status)
+	}
+
+	// 同步不回写存储
+	userRoles, err := a.UserRoleModel.Query(ctx, schema.UserRoleQueryParam{UserID: "alice"})
+	assert.Nil(t, err)
+	assert.Len(t, userRoles.Data, 2)
+}
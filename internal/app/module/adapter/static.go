@@ -0,0 +1,49 @@
+package adapter
+
+import (
+	casbinModel "github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+)
+
+var _ persist.Adapter = (*StaticAdapter)(nil)
+
+// StaticAdapter 内存策略适配器，从固定的策略规则加载，变更不持久化(用于离线策略测试)
+type StaticAdapter struct {
+	Policies  [][]string
+	Groupings [][]string
+}
+
+// NewStaticAdapter 创建内存策略适配器
+func NewStaticAdapter(policies, groupings [][]string) *StaticAdapter {
+	return &StaticAdapter{
+		Policies:  policies,
+		Groupings: groupings,
+	}
+}
+
+// LoadPolicy loads all policy rules from the storage.
+func (a *StaticAdapter) LoadPolicy(model casbinModel.Model) error {
+	loadPolicyRules("p", a.Policies, model)
+	loadPolicyRules("g", a.Groupings, model)
+	return nil
+}
+
+// SavePolicy saves all policy rules to the storage.
+func (a *StaticAdapter) SavePolicy(model casbinModel.Model) error {
+	return nil
+}
+
+// AddPolicy adds a policy rule to the storage.
+func (a *StaticAdapter) AddPolicy(sec string, ptype string, rule []string) error {
+	return nil
+}
+
+// RemovePolicy removes a policy rule from the storage.
+func (a *StaticAdapter) RemovePolicy(sec string, ptype string, rule []string) error {
+	return nil
+}
+
+// RemoveFilteredPolicy removes policy rules that match the filter from the storage.
+func (a *StaticAdapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	return nil
+}
@@ -0,0 +1,231 @@
+package policytest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	igorm "gin-casbin/internal/app/model/impl/gorm"
+	gmodel "gin-casbin/internal/app/model/impl/gorm/model"
+	"gin-casbin/internal/app/module/adapter"
+	"gin-casbin/pkg/errors"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/persist"
+	"gopkg.in/yaml.v2"
+)
+
+// 策略断言的期望结果
+const (
+	ExpectAllow = "allow"
+	ExpectDeny  = "deny"
+)
+
+// PolicyCase 策略断言
+type PolicyCase struct {
+	Name   string                 `yaml:"name"`   // 断言名称
+	User   string                 `yaml:"user"`   // 用户ID
+	Tenant string                 `yaml:"tenant"` // 租户ID
+	Path   string                 `yaml:"path"`   // 请求路径
+	Method string                 `yaml:"method"` // 请求方法
+	Attrs  map[string]interface{} `yaml:"attrs"`  // 策略条件属性
+	Expect string                 `yaml:"expect"` // 期望结果(allow/deny)
+}
+
+// String 断言描述
+func (a *PolicyCase) String() string {
+	s := fmt.Sprintf("%s@%s %s %s", a.User, a.Tenant, a.Method, a.Path)
+	if a.Name != "" {
+		s = a.Name + " (" + s + ")"
+	}
+	return s
+}
+
+// PolicySuite 策略断言集合(一个YAML文件)
+type PolicySuite struct {
+	Name  string        `yaml:"name"`  // 集合名称(为空时使用文件名)
+	File  string        `yaml:"-"`     // 断言文件
+	Cases []*PolicyCase `yaml:"cases"` // 断言列表
+}
+
+// PolicyFailure 未通过的策略断言
+type PolicyFailure struct {
+	Suite  string
+	Case   *PolicyCase
+	Actual string
+	Err    error
+}
+
+// String 失败描述
+func (a *PolicyFailure) String() string {
+	if a.Err != nil {
+		return fmt.Sprintf("[%s] %s: %s", a.Suite, a.Case, a.Err.Error())
+	}
+	return fmt.Sprintf("[%s] %s: expect %s, got %s", a.Suite, a.Case, a.Case.Expect, a.Actual)
+}
+
+// LoadPolicySuites 加载策略断言文件(路径为目录时加载目录下所有yaml文件)
+func LoadPolicySuites(paths ...string) ([]*PolicySuite, error) {
+	var files []string
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		} else if !fi.IsDir() {
+			files = append(files, p)
+			continue
+		}
+
+		for _, pattern := range []string{"*.yaml", "*.yml"} {
+			matches, err := filepath.Glob(filepath.Join(p, pattern))
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			files = append(files, matches...)
+		}
+	}
+	sort.Strings(files)
+
+	suites := make([]*PolicySuite, 0, len(files))
+	for _, file := range files {
+		suite, err := LoadPolicySuite(file)
+		if err != nil {
+			return nil, err
+		}
+		suites = append(suites, suite)
+	}
+	return suites, nil
+}
+
+// LoadPolicySuite 加载策略断言文件
+func LoadPolicySuite(file string) (*PolicySuite, error) {
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	suite := &PolicySuite{File: file}
+	if err := yaml.Unmarshal(buf, suite); err != nil {
+		return nil, errors.Wrapf(err, "parse policy suite %s", file)
+	}
+	if suite.Name == "" {
+		suite.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	return suite, nil
+}
+
+// NewCSVEnforcer 从模型文件和CSV策略文件创建enforcer(策略格式同策略导出)
+func NewCSVEnforcer(modelFile, policyFile string) (*casbin.SyncedEnforcer, error) {
+	buf, err := ioutil.ReadFile(policyFile)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	policies, groupings, err := adapter.DecodePolicyCSV(buf)
+	if err != nil {
+		return nil, err
+	}
+	policies, groupings, err = adapter.NormalizeRules(policies, groupings)
+	if err != nil {
+		return nil, err
+	}
+	return newEnforcer(modelFile, adapter.NewStaticAdapter(policies, groupings))
+}
+
+// NewSQLiteEnforcer 从模型文件和已初始化数据的SQLite数据库创建enforcer
+func NewSQLiteEnforcer(modelFile, dbFile string) (*casbin.SyncedEnforcer, func(), error) {
+	if _, err := os.Stat(dbFile); err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	db, cleanFunc, err := igorm.NewDB(&igorm.Config{
+		DBType: "sqlite3",
+		DSN:    dbFile,
+	})
+	if err != nil {
+		if cleanFunc != nil {
+			cleanFunc()
+		}
+		return nil, nil, err
+	}
+
+	a := &adapter.CasbinAdapter{
+		TransModel:        &gmodel.Trans{DB: db},
+		RoleModel:         &gmodel.Role{DB: db},
+		RoleMenuModel:     &gmodel.RoleMenu{DB: db},
+		MenuActionModel:   &gmodel.MenuAction{DB: db},
+		MenuResourceModel: &gmodel.MenuActionResource{DB: db},
+		UserModel:         &gmodel.User{DB: db},
+		UserRoleModel:     &gmodel.UserRole{DB: db},
+		UserTenantModel:   &gmodel.UserTenant{DB: db},
+		RoleParentModel:   &gmodel.RoleParent{DB: db},
+	}
+	e, err := newEnforcer(modelFile, a)
+	if err != nil {
+		cleanFunc()
+		return nil, nil, err
+	}
+	return e, cleanFunc, nil
+}
+
+func newEnforcer(modelFile string, a persist.Adapter) (*casbin.SyncedEnforcer, error) {
+	e, err := casbin.NewSyncedEnforcer(modelFile, a)
+	if err != nil {
+		return nil, errors.Wrapf(err, "load model %s", modelFile)
+	}
+	adapter.RegisterConditionFunction(e)
+	adapter.RegisterRoleFunction(e)
+	return e, nil
+}
+
+// RunPolicySuite 执行策略断言，返回未通过的断言
+func RunPolicySuite(e *casbin.SyncedEnforcer, suite *PolicySuite) []*PolicyFailure {
+	var failures []*PolicyFailure
+	for _, item := range suite.Cases {
+		actual, err := runPolicyCase(e, item)
+		if err != nil {
+			failures = append(failures, &PolicyFailure{Suite: suite.Name, Case: item, Err: err})
+		} else if actual != item.Expect {
+			failures = append(failures, &PolicyFailure{Suite: suite.Name, Case: item, Actual: actual})
+		}
+	}
+	return failures
+}
+
+func runPolicyCase(e *casbin.SyncedEnforcer, item *PolicyCase) (string, error) {
+	if item.Expect != ExpectAllow && item.Expect != ExpectDeny {
+		return "", errors.Errorf("invalid expect %q, must be %s or %s", item.Expect, ExpectAllow, ExpectDeny)
+	}
+
+	attrs := adapter.Attributes{
+		adapter.AttrUserID:   item.User,
+		adapter.AttrTenantID: item.Tenant,
+	}
+	for k, v := range item.Attrs {
+		attrs[k] = normalizeAttr(v)
+	}
+
+	allowed, err := e.Enforce(item.User, item.Tenant, item.Path, item.Method, attrs)
+	if err != nil {
+		return "", err
+	} else if allowed {
+		return ExpectAllow, nil
+	}
+	return ExpectDeny, nil
+}
+
+// 条件表达式中的数字按float64比较
+func normalizeAttr(v interface{}) interface{} {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	}
+	return v
+}
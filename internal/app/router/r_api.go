@@ -1,10 +1,149 @@
 package router
 
 import (
+	"context"
+	"strings"
+
+	"gin-casbin/internal/app/config"
+	"gin-casbin/internal/app/ginplus"
+	"gin-casbin/internal/app/middleware"
+	"gin-casbin/internal/app/schema"
+
 	"github.com/gin-gonic/gin"
 )
 
 // RegisterAPI register api group router
 func (a *Router) RegisterAPI(app *gin.Engine) {
+	g := app.Group("/api")
+
+	g.Use(middleware.UserAuthMiddleware(a.Auth,
+		middleware.AllowPathPrefixSkipper("/api/v1/pub/login"),
+	))
+
+	g.Use(middleware.CasbinMiddleware(a.CasbinEnforcer, a.decisionRecorder(), a.attributeLoader(),
+		middleware.AllowPathPrefixSkipper("/api/v1/pub"),
+	))
+
+	v1 := g.Group("/v1")
+	{
+		pub := v1.Group("/pub")
+		{
+			gLogin := pub.Group("login")
+			{
+				gLogin.GET("captchaid", a.LoginAPI.GetCaptcha)
+				gLogin.GET("captcha", a.LoginAPI.ResCaptcha)
+				gLogin.POST("", a.LoginAPI.Login)
+				gLogin.POST("token", a.LoginAPI.GetAccessToken)
+				gLogin.POST("refresh-token", a.LoginAPI.RefreshToken)
+				gLogin.POST("forget-password", a.LoginAPI.ForgetPassword)
+			}
+
+			gCurrent := pub.Group("current")
+			{
+				gCurrent.POST("logout", a.LoginAPI.Logout)
+				gCurrent.PUT("password", a.LoginAPI.UpdatePassword)
+				gCurrent.GET("user", a.LoginAPI.GetUserInfo)
+				gCurrent.GET("menutree", a.LoginAPI.QueryUserMenuTree)
+			}
+		}
+
+		gMenu := v1.Group("menus")
+		{
+			gMenu.GET("", a.MenuAPI.Query)
+			gMenu.GET(":id", a.MenuAPI.Get)
+			gMenu.POST("", a.MenuAPI.Create)
+			gMenu.PUT(":id", a.MenuAPI.Update)
+			gMenu.DELETE(":id", a.MenuAPI.Delete)
+			gMenu.PATCH(":id/enable", a.MenuAPI.Enable)
+			gMenu.PATCH(":id/disable", a.MenuAPI.Disable)
+		}
+		v1.GET("menus.tree", a.MenuAPI.QueryTree)
+
+		gPolicy := v1.Group("policies")
+		{
+			gPolicy.GET("explain", a.PolicyAPI.Explain)
+			gPolicy.GET("decisions/:id", a.PolicyAPI.GetDecision)
+			gPolicy.GET("cache/stats", a.PolicyAPI.CacheStats)
+			gPolicy.GET("permissions", a.PolicyAPI.Permissions)
+			gPolicy.GET("permitted-users", a.PolicyAPI.PermittedUsers)
+			gPolicy.GET("export", a.PolicyAPI.Export)
+			gPolicy.POST("import", a.PolicyAPI.Import)
+		}
+
+		v1.GET("user-role-grants", a.UserAPI.QueryRoleGrants)
+
+		gChangeSet := v1.Group("policy-change-sets")
+		{
+			gChangeSet.GET("", a.PolicyChangeSetAPI.Query)
+			gChangeSet.GET(":id", a.PolicyChangeSetAPI.Get)
+			gChangeSet.POST("", a.PolicyChangeSetAPI.Create)
+			gChangeSet.GET(":id/preview", a.PolicyChangeSetAPI.Preview)
+			gChangeSet.PATCH(":id/approve", a.PolicyChangeSetAPI.Approve)
+			gChangeSet.PATCH(":id/reject", a.PolicyChangeSetAPI.Reject)
+		}
+
+		gRoleElevation := v1.Group("role-elevations")
+		{
+			gRoleElevation.GET("", a.RoleElevationAPI.Query)
+			gRoleElevation.GET(":id", a.RoleElevationAPI.Get)
+			gRoleElevation.POST("", a.RoleElevationAPI.Create)
+			gRoleElevation.PATCH(":id/approve", a.RoleElevationAPI.Approve)
+			gRoleElevation.PATCH(":id/deny", a.RoleElevationAPI.Deny)
+			gRoleElevation.PATCH(":id/revoke", a.RoleElevationAPI.Revoke)
+		}
+
+		gAccessReview := v1.Group("access-reviews")
+		{
+			gAccessReview.GET("", a.AccessReviewAPI.Query)
+			gAccessReview.GET(":id", a.AccessReviewAPI.Get)
+			gAccessReview.POST("", a.AccessReviewAPI.Create)
+			gAccessReview.GET(":id/items", a.AccessReviewAPI.QueryItems)
+			gAccessReview.PATCH(":id/close", a.AccessReviewAPI.Close)
+			gAccessReview.GET(":id/export", a.AccessReviewAPI.Export)
+		}
+		v1.PATCH("access-review-items/:id/review", a.AccessReviewAPI.ReviewItem)
+
+		gSession := v1.Group("sessions")
+		{
+			gSession.GET("", a.SessionAPI.Query)
+			gSession.GET("mine", a.SessionAPI.QueryMine)
+			gSession.DELETE(":id", a.SessionAPI.Terminate)
+		}
+	}
+}
+
+// 记录拒绝的策略决策(未开启时不记录)
+func (a *Router) decisionRecorder() middleware.DecisionRecorder {
+	if !config.C.Casbin.DecisionLog {
+		return nil
+	}
+
+	return func(ctx context.Context, userID, tenantID, path, method string, attrs map[string]interface{}) (string, error) {
+		item, err := a.PolicyBll.RecordDecision(ctx, schema.PolicyExplainParam{
+			UserID:     userID,
+			TenantID:   tenantID,
+			Path:       path,
+			Method:     method,
+			Attributes: attrs,
+		})
+		if err != nil {
+			return "", err
+		}
+		return item.ID, nil
+	}
+}
 
+// 加载策略条件属性，按路由识别请求的目标资源
+func (a *Router) attributeLoader() middleware.AttributeLoader {
+	return func(c *gin.Context) (map[string]interface{}, error) {
+		params := schema.PolicyAttributeParam{
+			UserID:   ginplus.GetUserID(c),
+			TenantID: ginplus.GetTenantID(c),
+		}
+		if strings.HasPrefix(c.FullPath(), "/api/v1/users/:id") {
+			params.TargetType = schema.PolicyTargetUser
+			params.TargetID = c.Param("id")
+		}
+		return a.PolicyBll.LoadAttributes(c.Request.Context(), params)
+	}
 }
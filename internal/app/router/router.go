@@ -2,6 +2,7 @@ package router
 
 import (
 	"gin-casbin/internal/app/api"
+	"gin-casbin/internal/app/bll"
 	"gin-casbin/pkg/auth"
 
 	"github.com/casbin/casbin/v2"
@@ -22,18 +23,27 @@ type IRouter interface {
 
 // Router
 type Router struct {
-	Auth           auth.Auther
-	CasbinEnforcer *casbin.SyncedEnforcer
-	LoginAPI       *api.Login
-	RoleAPI        *api.Role
-	UserAPI        *api.User
-	TenantAPI      *api.Tenant
-	ResourceAPI    *api.Resource
+	Auth               auth.Auther
+	CasbinEnforcer     *casbin.SyncedEnforcer
+	LoginAPI           *api.Login
+	MenuAPI            *api.Menu
+	RoleAPI            *api.Role
+	UserAPI            *api.User
+	TenantAPI          *api.Tenant
+	ResourceAPI        *api.Resource
+	PolicyAPI          *api.Policy
+	PolicyChangeSetAPI *api.PolicyChangeSet
+	RoleElevationAPI   *api.RoleElevation
+	AccessReviewAPI    *api.AccessReview
+	JWKSAPI            *api.JWKS
+	SessionAPI         *api.Session
+	PolicyBll          bll.IPolicy
 }
 
 // Register
 func (a *Router) Register(app *gin.Engine) error {
 	a.RegisterAPI(app)
+	app.GET("/.well-known/jwks.json", a.JWKSAPI.Get)
 	return nil
 }
 
@@ -41,5 +51,6 @@ func (a *Router) Register(app *gin.Engine) error {
 func (a *Router) Prefixes() []string {
 	return []string{
 		"/api/",
+		"/.well-known/",
 	}
 }
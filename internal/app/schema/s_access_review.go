@@ -0,0 +1,123 @@
+package schema
+
+import (
+	"time"
+
+	"gin-casbin/pkg/util"
+)
+
+// 访问审查状态
+const (
+	AccessReviewOpen   = 1 // 进行中
+	AccessReviewClosed = 2 // 已关闭
+)
+
+// 访问审查结论
+const (
+	ReviewDecisionPending = 0 // 待审查
+	ReviewDecisionKeep    = 1 // 保留
+	ReviewDecisionRevoke  = 2 // 撤销
+)
+
+// AccessReview 访问审查活动(创建时快照所有租户的用户角色授权)
+type AccessReview struct {
+	ID          string     `json:"id"`                      // 唯一标识
+	Name        string     `json:"name" binding:"required"` // 活动名称
+	Description string     `json:"description"`             // 描述
+	DueAt       *time.Time `json:"due_at"`                  // 截止时间
+	Status      int        `json:"status"`                  // 状态(1:进行中 2:已关闭)
+	ItemCount   int        `json:"item_count"`              // 授权总数
+	KeepCount   int        `json:"keep_count"`              // 保留数
+	RevokeCount int        `json:"revoke_count"`            // 撤销数
+	ClosedBy    string     `json:"closed_by"`               // 关闭者
+	ClosedAt    *time.Time `json:"closed_at"`               // 关闭时间
+	Creator     string     `json:"creator"`                 // 创建者
+	CreatedAt   time.Time  `json:"created_at"`              // 创建时间
+	UpdatedAt   time.Time  `json:"updated_at"`              // 更新时间
+}
+
+func (a *AccessReview) String() string {
+	return util.JSONMarshalToString(a)
+}
+
+// AccessReviewQueryParam 查询条件
+type AccessReviewQueryParam struct {
+	PaginationParam
+	Status int `form:"status"` // 状态(1:进行中 2:已关闭)
+}
+
+// AccessReviewQueryOptions 查询可选参数项
+type AccessReviewQueryOptions struct {
+	OrderFields []*OrderField // 排序字段
+}
+
+// AccessReviewQueryResult 查询结果
+type AccessReviewQueryResult struct {
+	Data       AccessReviews
+	PageResult *PaginationResult
+}
+
+// AccessReviews 访问审查活动列表
+type AccessReviews []*AccessReview
+
+// ----------------------------------------AccessReviewItem--------------------------------------
+
+// AccessReviewItem 访问审查项(一条用户角色授权的快照)
+type AccessReviewItem struct {
+	ID         string     `json:"id"`           // 唯一标识
+	ReviewID   string     `json:"review_id"`    // 访问审查ID
+	TenantID   string     `json:"tenant_id"`    // 租户ID
+	UserID     string     `json:"user_id"`      // 用户ID
+	UserName   string     `json:"user_name"`    // 用户名
+	RoleID     string     `json:"role_id"`      // 角色ID
+	RoleName   string     `json:"role_name"`    // 角色名称
+	UserRoleID string     `json:"user_role_id"` // 用户角色ID
+	ValidFrom  *time.Time `json:"valid_from"`   // 授权生效时间
+	ValidUntil *time.Time `json:"valid_until"`  // 授权失效时间
+	Decision   int        `json:"decision"`     // 审查结论(0:待审查 1:保留 2:撤销)
+	Reviewer   string     `json:"reviewer"`     // 审查者
+	Comment    string     `json:"comment"`      // 审查意见
+	ReviewedAt *time.Time `json:"reviewed_at"`  // 审查时间
+	Applied    bool       `json:"applied"`      // 撤销是否已执行
+	CreatedAt  time.Time  `json:"created_at"`   // 创建时间
+}
+
+// AccessReviewItemQueryParam 查询条件
+type AccessReviewItemQueryParam struct {
+	PaginationParam
+	ReviewID string `form:"-"`         // 访问审查ID
+	TenantID string `form:"tenant_id"` // 租户ID
+	UserID   string `form:"user_id"`   // 用户ID
+	Decision *int   `form:"decision"`  // 审查结论(0:待审查 1:保留 2:撤销)
+}
+
+// AccessReviewItemQueryOptions 查询可选参数项
+type AccessReviewItemQueryOptions struct {
+	OrderFields []*OrderField // 排序字段
+}
+
+// AccessReviewItemQueryResult 查询结果
+type AccessReviewItemQueryResult struct {
+	Data       AccessReviewItems
+	PageResult *PaginationResult
+}
+
+// AccessReviewItems 访问审查项列表
+type AccessReviewItems []*AccessReviewItem
+
+// CountDecision 统计指定审查结论的数量
+func (a AccessReviewItems) CountDecision(decision int) int {
+	n := 0
+	for _, item := range a {
+		if item.Decision == decision {
+			n++
+		}
+	}
+	return n
+}
+
+// AccessReviewDecision 审查参数
+type AccessReviewDecision struct {
+	Decision int    `json:"decision" binding:"required,min=1,max=2"` // 审查结论(1:保留 2:撤销)
+	Comment  string `json:"comment"`                                 // 审查意见
+}
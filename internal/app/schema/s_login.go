@@ -51,7 +51,14 @@ type LoginCaptcha struct {
 
 // LoginTokenInfo 登录令牌信息
 type LoginTokenInfo struct {
-	AccessToken string `json:"access_token"` // 访问令牌
-	TokenType   string `json:"token_type"`   // 令牌类型
-	ExpiresAt   int64  `json:"expires_at"`   // 令牌到期时间戳
+	AccessToken      string `json:"access_token"`       // 访问令牌
+	TokenType        string `json:"token_type"`         // 令牌类型
+	ExpiresAt        int64  `json:"expires_at"`         // 令牌到期时间戳
+	RefreshToken     string `json:"refresh_token"`      // 刷新令牌
+	RefreshExpiresAt int64  `json:"refresh_expires_at"` // 刷新令牌到期时间戳
+}
+
+// RefreshTokenParam 刷新令牌请求参数
+type RefreshTokenParam struct {
+	RefreshToken string `json:"refresh_token" binding:"required"` // 刷新令牌
 }
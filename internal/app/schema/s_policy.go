@@ -0,0 +1,179 @@
+package schema
+
+import (
+	"time"
+
+	"gin-casbin/pkg/util"
+)
+
+// 策略决策原因
+const (
+	DecisionReasonAllow   = "allow"    // 匹配允许策略
+	DecisionReasonDeny    = "deny"     // 匹配拒绝策略
+	DecisionReasonNoMatch = "no_match" // 没有匹配的策略
+)
+
+// PolicyExplainParam 策略解释参数
+type PolicyExplainParam struct {
+	UserID   string `form:"user_id" json:"user_id" binding:"required"` // 用户ID
+	TenantID string `form:"tenant_id" json:"tenant_id"`                // 租户ID
+	Path     string `form:"path" json:"path" binding:"required"`       // 请求路径
+	Method   string `form:"method" json:"method" binding:"required"`   // 请求方法
+	// 目标资源(用于策略条件中的target_*属性)
+	TargetType string                 `form:"target_type" json:"target_type"` // 目标资源类型(user)
+	TargetID   string                 `form:"target_id" json:"target_id"`     // 目标资源ID
+	Attributes map[string]interface{} `form:"-" json:"-"`                     // 已加载的条件属性(为空时按参数加载)
+}
+
+// PolicyTargetUser 策略条件的目标资源类型: 用户
+const PolicyTargetUser = "user"
+
+// PolicyAttributeParam 策略条件属性加载参数
+type PolicyAttributeParam struct {
+	UserID     string // 用户ID
+	TenantID   string // 租户ID
+	TargetType string // 目标资源类型
+	TargetID   string // 目标资源ID
+}
+
+// PolicyDecision 策略决策对象
+type PolicyDecision struct {
+	ID            string    `json:"id"`             // 唯一标识
+	UserID        string    `json:"user_id"`        // 用户ID
+	TenantID      string    `json:"tenant_id"`      // 使用的租户ID
+	Path          string    `json:"path"`           // 请求路径
+	Method        string    `json:"method"`         // 请求方法
+	Allowed       bool      `json:"allowed"`        // 是否允许
+	Reason        string    `json:"reason"`         // 决策原因
+	MatchedPolicy []string  `json:"matched_policy"` // 匹配的策略
+	RoleChain     []string  `json:"role_chain"`     // 用户到匹配策略的角色链
+	Roles         []string  `json:"roles"`          // 用户在租户下的所有角色(含继承)
+	CreatedAt     time.Time `json:"created_at"`     // 创建时间
+}
+
+func (a *PolicyDecision) String() string {
+	return util.JSONMarshalToString(a)
+}
+
+// PolicyDecisionGetOptions Get查询可选参数项
+type PolicyDecisionGetOptions struct {
+}
+
+// DecisionCacheStats 策略决策缓存统计
+type DecisionCacheStats struct {
+	Enabled bool   `json:"enabled"` // 是否启用
+	Hits    uint64 `json:"hits"`    // 命中次数
+	Misses  uint64 `json:"misses"`  // 未命中次数
+	Size    int    `json:"size"`    // 缓存的决策数量
+}
+
+// 策略导出导入格式
+const (
+	PolicyFormatCSV  = "csv"  // casbin CSV格式
+	PolicyFormatJSON = "json" // 结构化JSON格式
+)
+
+// PolicyExportParam 策略导出参数
+type PolicyExportParam struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv json"` // 导出格式(默认json)
+}
+
+// PolicyImportParam 策略导入参数
+type PolicyImportParam struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv json"` // 导入格式(默认json)
+	DryRun bool   `form:"dry_run"`                                   // 只校验并返回差异，不修改存储
+}
+
+// PolicyDocument 策略文档(包含角色和用户名称)
+type PolicyDocument struct {
+	Policies  []*PolicyRule   `json:"policies"`  // 角色策略(p)
+	Groupings []*GroupingRule `json:"groupings"` // 用户角色策略(g)
+}
+
+// PolicyRule 角色策略
+type PolicyRule struct {
+	RoleID    string `json:"role_id"`             // 角色ID(为空时按角色名称查找)
+	RoleName  string `json:"role_name"`           // 角色名称
+	Domain    string `json:"domain"`              // 策略域(*表示全局角色)
+	Path      string `json:"path"`                // 请求路径
+	Method    string `json:"method"`              // 请求方法
+	Effect    string `json:"effect"`              // 效果(allow/deny)
+	Condition string `json:"condition,omitempty"` // 条件表达式
+}
+
+// GroupingRule 用户角色策略
+type GroupingRule struct {
+	UserID   string `json:"user_id"`   // 用户ID(为空时按用户名查找)
+	UserName string `json:"user_name"` // 用户名
+	RoleID   string `json:"role_id"`   // 角色ID(为空时按角色名称查找)
+	RoleName string `json:"role_name"` // 角色名称
+	TenantID string `json:"tenant_id"` // 租户ID
+}
+
+// PolicyImportResult 策略导入结果
+type PolicyImportResult struct {
+	DryRun     bool     `json:"dry_run"`    // 是否预演
+	Added      []string `json:"added"`      // 新增的规则(CSV策略行)
+	Removed    []string `json:"removed"`    // 删除的规则(CSV策略行)
+	Unexpected []string `json:"unexpected"` // 导入后存储中多出的规则(如同一菜单动作的其他资源)，非空时不能导入
+	Missing    []string `json:"missing"`    // 导入后存储中缺少的规则(如停用的角色)，非空时不能导入
+}
+
+// PermissionQueryParam 有效权限查询参数(用户ID和角色ID二选一)
+type PermissionQueryParam struct {
+	UserID   string `form:"user_id"`   // 用户ID
+	RoleID   string `form:"role_id"`   // 角色ID
+	TenantID string `form:"tenant_id"` // 租户ID
+}
+
+// PermissionReport 有效权限报告
+type PermissionReport struct {
+	UserID    string                `json:"user_id,omitempty"` // 用户ID
+	RoleID    string                `json:"role_id,omitempty"` // 角色ID
+	TenantID  string                `json:"tenant_id"`         // 使用的租户ID
+	Roles     []string              `json:"roles"`             // 展开后的所有角色(含继承)
+	Menus     []*PermissionMenu     `json:"menus"`             // 按菜单和动作分组的允许资源
+	Ungrouped []*PermissionResource `json:"ungrouped"`         // 没有匹配任何菜单资源的允许策略(如通配策略)
+}
+
+// PermissionMenu 有效权限的菜单
+type PermissionMenu struct {
+	MenuID   string              `json:"menu_id"`   // 菜单ID
+	MenuName string              `json:"menu_name"` // 菜单名称
+	Actions  []*PermissionAction `json:"actions"`   // 允许的动作
+}
+
+// PermissionAction 有效权限的菜单动作
+type PermissionAction struct {
+	ActionID  string                `json:"action_id"` // 动作ID
+	Code      string                `json:"code"`      // 动作编号
+	Name      string                `json:"name"`      // 动作名称
+	Partial   bool                  `json:"partial"`   // 是否只允许部分资源
+	Resources []*PermissionResource `json:"resources"` // 允许的资源
+}
+
+// PermissionResource 有效权限的资源(路径模式和方法)
+type PermissionResource struct {
+	Path       string   `json:"path"`                 // 请求路径(模式)
+	Method     string   `json:"method"`               // 请求方法
+	Roles      []string `json:"roles"`                // 授予权限的角色
+	Conditions []string `json:"conditions,omitempty"` // 生效需满足的条件(允许条件或排除的拒绝条件，为空表示无条件)
+}
+
+// PermittedUserQueryParam 反向查询可以执行请求的用户参数
+type PermittedUserQueryParam struct {
+	TenantID   string `form:"tenant_id"`                 // 租户ID
+	Path       string `form:"path" binding:"required"`   // 请求路径
+	Method     string `form:"method" binding:"required"` // 请求方法
+	TargetType string `form:"target_type"`               // 目标资源类型(user)
+	TargetID   string `form:"target_id"`                 // 目标资源ID
+}
+
+// PermittedUser 可以执行请求的用户
+type PermittedUser struct {
+	UserID        string   `json:"user_id"`        // 用户ID
+	UserName      string   `json:"user_name"`      // 用户名
+	RealName      string   `json:"real_name"`      // 真实姓名
+	RoleChain     []string `json:"role_chain"`     // 用户到匹配策略的角色链
+	MatchedPolicy []string `json:"matched_policy"` // 匹配的策略
+}
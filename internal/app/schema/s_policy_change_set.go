@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"time"
+
+	"gin-casbin/pkg/util"
+)
+
+// 变更集状态
+const (
+	ChangeSetPending  = 1 // 待审核
+	ChangeSetApproved = 2 // 已批准并提交
+	ChangeSetRejected = 3 // 已拒绝
+)
+
+// PolicyChangeSet 策略变更集
+type PolicyChangeSet struct {
+	ID            string         `json:"id"`                       // 唯一标识
+	Title         string         `json:"title" binding:"required"` // 标题
+	Description   string         `json:"description"`              // 描述
+	Status        int            `json:"status"`                   // 状态(1:待审核 2:已批准 3:已拒绝)
+	Changes       PolicyChanges  `json:"changes"`                  // 变更的策略规则
+	PreviewDiff   *PolicyChanges `json:"preview_diff"`             // 最近一次预览的实际差异(批准时必须与提交结果一致)
+	Creator       string         `json:"creator"`                  // 创建者
+	Reviewer      string         `json:"reviewer"`                 // 审核者
+	ReviewComment string         `json:"review_comment"`           // 审核意见
+	ReviewedAt    *time.Time     `json:"reviewed_at"`              // 审核时间
+	CreatedAt     time.Time      `json:"created_at"`               // 创建时间
+	UpdatedAt     time.Time      `json:"updated_at"`               // 更新时间
+}
+
+func (a *PolicyChangeSet) String() string {
+	return util.JSONMarshalToString(a)
+}
+
+// PolicyChanges 变更的策略规则
+type PolicyChanges struct {
+	AddedPolicies    [][]string `json:"added_policies"`    // 新增的角色策略(p)
+	RemovedPolicies  [][]string `json:"removed_policies"`  // 删除的角色策略(p)
+	AddedGroupings   [][]string `json:"added_groupings"`   // 新增的用户策略(g)
+	RemovedGroupings [][]string `json:"removed_groupings"` // 删除的用户策略(g)
+}
+
+// IsEmpty 检查是否没有任何变更
+func (a PolicyChanges) IsEmpty() bool {
+	return len(a.AddedPolicies)+len(a.RemovedPolicies)+
+		len(a.AddedGroupings)+len(a.RemovedGroupings) == 0
+}
+
+// PolicyChangeSetQueryParam 查询条件
+type PolicyChangeSetQueryParam struct {
+	PaginationParam
+	Status int `form:"status"` // 状态(1:待审核 2:已批准 3:已拒绝)
+}
+
+// PolicyChangeSetQueryOptions 查询可选参数项
+type PolicyChangeSetQueryOptions struct {
+	OrderFields []*OrderField // 排序字段
+}
+
+// PolicyChangeSetQueryResult 查询结果
+type PolicyChangeSetQueryResult struct {
+	Data       PolicyChangeSets
+	PageResult *PaginationResult
+}
+
+// PolicyChangeSets 策略变更集列表
+type PolicyChangeSets []*PolicyChangeSet
+
+// PolicyChangeSetReview 变更集审核参数
+type PolicyChangeSetReview struct {
+	Comment string `json:"comment"` // 审核意见
+}
+
+// PolicyChangeSetPreview 变更集预览
+type PolicyChangeSetPreview struct {
+	Diff    PolicyChanges   `json:"diff"`    // 相对当前策略的实际差异
+	Impacts []*AccessChange `json:"impacts"` // 用户访问权限变化
+}
+
+// AccessChange 用户在租户下的访问权限变化
+type AccessChange struct {
+	UserID   string   `json:"user_id"`   // 用户ID
+	TenantID string   `json:"tenant_id"` // 租户ID
+	Gained   []string `json:"gained"`    // 新增可访问的路由(METHOD PATH)
+	Lost     []string `json:"lost"`      // 失去访问的路由(METHOD PATH)
+}
@@ -1,6 +1,10 @@
 package schema
 
-import "time"
+import (
+	"time"
+
+	"gin-casbin/internal/app/config"
+)
 
 // Role 角色对象
 type Role struct {
@@ -10,10 +14,49 @@ type Role struct {
 	Description string    `json:"description"`                           // 备注
 	Status      int       `json:"status" binding:"required,max=2,min=1"` // 状态(1:启用 2:禁用)
 	Type        int       `json:"type"`                                  // 角色类型
+	TenantID    string    `json:"tenant_id"`                             // 所属租户(为空表示全局角色)
 	Creator     string    `json:"creator"`                               // 创建者
 	CreatedAt   time.Time `json:"created_at"`                            // 创建时间
 	UpdatedAt   time.Time `json:"updated_at"`                            // 更新时间
 	RoleMenus   RoleMenus `json:"role_menus" binding:"required,gt=0"`    // 角色菜单列表
+	ParentIDs   []string  `json:"parent_ids"`                            // 继承的父角色ID列表
+	Inherited   RoleMenus `json:"inherited_menus"`                       // 从祖先角色继承的角色菜单列表(只读)
+}
+
+// 角色类型
+const (
+	RoleTypeUser  = 0 // 普通角色(租户管理员可以管理)
+	RoleTypeOwner = 9 // 租户管理员角色
+)
+
+// GlobalRoleDomain 全局角色的策略域
+const GlobalRoleDomain = "*"
+
+// Domain 获取角色的策略域
+func (a *Role) Domain() string {
+	if a.TenantID == "" {
+		return GlobalRoleDomain
+	}
+	return a.TenantID
+}
+
+// IsVisibleTo 检查角色是否对租户可见(全局角色或租户自定义角色)
+func (a *Role) IsVisibleTo(tenantID string) bool {
+	return a.TenantID == "" || a.TenantID == tenantID || tenantID == RootTenantID
+}
+
+// CheckIsPlatformAdminRole 检查是否是平台管理员角色
+func CheckIsPlatformAdminRole(roleID string) bool {
+	id := config.C.PlatformAdminRole.ID
+	return id != "" && roleID == id
+}
+
+// CanInherit 检查角色是否可以继承父角色(全局角色只能继承全局角色，租户角色可以继承全局角色或同租户角色，平台管理员角色不参与继承)
+func (a *Role) CanInherit(parent *Role) bool {
+	if parent.ID == a.ID || CheckIsPlatformAdminRole(a.ID) || CheckIsPlatformAdminRole(parent.ID) {
+		return false
+	}
+	return parent.TenantID == "" || parent.TenantID == a.TenantID
 }
 
 // RoleQueryParam 查询条件
@@ -61,12 +104,28 @@ func (a Roles) ToMap() map[string]*Role {
 
 // ----------------------------------------RoleMenu--------------------------------------
 
+// 定义权限效果
+const (
+	EffectAllow = "allow" // 允许
+	EffectDeny  = "deny"  // 拒绝(优先于允许)
+)
+
 // RoleMenu 角色菜单对象
 type RoleMenu struct {
-	ID       string `json:"id"`                           // 唯一标识
-	RoleID   string `json:"role_id" binding:"required"`   // 角色ID
-	MenuID   string `json:"menu_id" binding:"required"`   // 菜单ID
-	ActionID string `json:"action_id" binding:"required"` // 动作ID
+	ID        string `json:"id"`                                          // 唯一标识
+	RoleID    string `json:"role_id" binding:"required"`                  // 角色ID
+	MenuID    string `json:"menu_id" binding:"required"`                  // 菜单ID
+	ActionID  string `json:"action_id" binding:"required"`                // 动作ID
+	Effect    string `json:"effect" binding:"omitempty,oneof=allow deny"` // 权限效果(allow:允许 deny:拒绝)，默认为允许
+	Condition string `json:"condition"`                                   // 条件表达式(基于请求和资源属性，为空表示无条件)
+}
+
+// GetEffect 获取权限效果
+func (a *RoleMenu) GetEffect() string {
+	if a.Effect == EffectDeny {
+		return EffectDeny
+	}
+	return EffectAllow
 }
 
 // RoleMenuQueryParam 查询条件
@@ -74,6 +133,7 @@ type RoleMenuQueryParam struct {
 	PaginationParam
 	RoleID  string   // 角色ID
 	RoleIDs []string // 角色ID列表
+	MenuID  string   // 菜单ID
 }
 
 // RoleMenuQueryOptions 查询可选参数项
@@ -94,11 +154,30 @@ type RoleMenus []*RoleMenu
 func (a RoleMenus) ToMap() map[string]*RoleMenu {
 	m := make(map[string]*RoleMenu)
 	for _, item := range a {
-		m[item.MenuID+"-"+item.ActionID] = item
+		m[item.MenuID+"-"+item.ActionID+"-"+item.GetEffect()+"-"+item.Condition] = item
 	}
 	return m
 }
 
+// FilterDenied 过滤掉被无条件拒绝的动作(拒绝优先于允许)
+func (a RoleMenus) FilterDenied() RoleMenus {
+	mDenied := make(map[string]struct{})
+	for _, item := range a {
+		if item.GetEffect() == EffectDeny && item.Condition == "" {
+			mDenied[item.ActionID] = struct{}{}
+		}
+	}
+
+	var list RoleMenus
+	for _, item := range a {
+		if _, ok := mDenied[item.ActionID]; ok {
+			continue
+		}
+		list = append(list, item)
+	}
+	return list
+}
+
 // ToRoleIDMap 转换为角色ID映射
 func (a RoleMenus) ToRoleIDMap() map[string]RoleMenus {
 	m := make(map[string]RoleMenus)
@@ -137,3 +216,108 @@ func (a RoleMenus) ToActionIDs() []string {
 	}
 	return idList
 }
+
+// ----------------------------------------RoleParent--------------------------------------
+
+// RoleParent 角色继承关系(角色继承父角色的全部权限)
+type RoleParent struct {
+	ID       string `json:"id"`        // 唯一标识
+	RoleID   string `json:"role_id"`   // 角色ID
+	ParentID string `json:"parent_id"` // 父角色ID
+}
+
+// RoleParentQueryParam 查询条件
+type RoleParentQueryParam struct {
+	PaginationParam
+	RoleID  string   // 角色ID
+	RoleIDs []string // 角色ID列表
+}
+
+// RoleParentQueryOptions 查询可选参数项
+type RoleParentQueryOptions struct {
+	OrderFields []*OrderField // 排序字段
+}
+
+// RoleParentQueryResult 查询结果
+type RoleParentQueryResult struct {
+	Data       RoleParents
+	PageResult *PaginationResult
+}
+
+// RoleParents 角色继承关系列表
+type RoleParents []*RoleParent
+
+// ToParentIDs 转换为父角色ID列表
+func (a RoleParents) ToParentIDs() []string {
+	list := make([]string, len(a))
+	for i, item := range a {
+		list[i] = item.ParentID
+	}
+	return list
+}
+
+// ToRoleIDs 转换为关系中涉及的角色ID列表(包括父角色)
+func (a RoleParents) ToRoleIDs() []string {
+	var list []string
+	m := make(map[string]struct{})
+	for _, item := range a {
+		for _, id := range []string{item.RoleID, item.ParentID} {
+			if _, ok := m[id]; !ok {
+				m[id] = struct{}{}
+				list = append(list, id)
+			}
+		}
+	}
+	return list
+}
+
+// ToRoleIDMap 转换为角色ID映射
+func (a RoleParents) ToRoleIDMap() map[string]RoleParents {
+	m := make(map[string]RoleParents)
+	for _, item := range a {
+		m[item.RoleID] = append(m[item.RoleID], item)
+	}
+	return m
+}
+
+// Ancestors 获取角色继承的所有祖先角色ID(按层级由近到远)
+func (a RoleParents) Ancestors(roleID string) []string {
+	mParents := a.ToRoleIDMap()
+	mVisited := map[string]struct{}{roleID: {}}
+	var list []string
+	queue := []string{roleID}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, item := range mParents[name] {
+			if _, ok := mVisited[item.ParentID]; ok {
+				continue
+			}
+			mVisited[item.ParentID] = struct{}{}
+			list = append(list, item.ParentID)
+			queue = append(queue, item.ParentID)
+		}
+	}
+	return list
+}
+
+// WouldCycle 检查将角色的父角色设置为parentIDs后是否形成继承环
+func (a RoleParents) WouldCycle(roleID string, parentIDs []string) bool {
+	var links RoleParents
+	for _, item := range a {
+		if item.RoleID != roleID {
+			links = append(links, item)
+		}
+	}
+	for _, parentID := range parentIDs {
+		if parentID == roleID {
+			return true
+		}
+		for _, id := range links.Ancestors(parentID) {
+			if id == roleID {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"time"
+
+	"gin-casbin/pkg/util"
+)
+
+// 临时提权申请状态
+const (
+	ElevationPending  = 1 // 待审批
+	ElevationApproved = 2 // 已批准
+	ElevationDenied   = 3 // 已拒绝
+	ElevationRevoked  = 4 // 已撤销
+	ElevationExpired  = 5 // 已到期
+)
+
+// RoleElevation 临时提权申请(批准后授予有有效期的角色)
+type RoleElevation struct {
+	ID            string     `json:"id"`                                          // 唯一标识
+	TenantID      string     `json:"tenant_id"`                                   // 租户ID
+	UserID        string     `json:"user_id"`                                     // 申请用户
+	RoleID        string     `json:"role_id" binding:"required"`                  // 申请的角色
+	Reason        string     `json:"reason" binding:"required"`                   // 申请原因
+	Duration      int        `json:"duration" binding:"required,min=1,max=10080"` // 授权时长(分钟)
+	Status        int        `json:"status"`                                      // 状态(1:待审批 2:已批准 3:已拒绝 4:已撤销 5:已到期)
+	UserRoleID    string     `json:"user_role_id"`                                // 批准后授予的用户角色
+	Reviewer      string     `json:"reviewer"`                                    // 审批者
+	ReviewComment string     `json:"review_comment"`                              // 审批意见
+	ReviewedAt    *time.Time `json:"reviewed_at"`                                 // 审批时间
+	ValidFrom     *time.Time `json:"valid_from"`                                  // 授权生效时间
+	ValidUntil    *time.Time `json:"valid_until"`                                 // 授权失效时间(到期自动撤销)
+	Revoker       string     `json:"revoker"`                                     // 撤销者
+	RevokeReason  string     `json:"revoke_reason"`                               // 撤销原因
+	RevokedAt     *time.Time `json:"revoked_at"`                                  // 撤销时间
+	Creator       string     `json:"creator"`                                     // 创建者
+	CreatedAt     time.Time  `json:"created_at"`                                  // 创建时间
+	UpdatedAt     time.Time  `json:"updated_at"`                                  // 更新时间
+}
+
+func (a *RoleElevation) String() string {
+	return util.JSONMarshalToString(a)
+}
+
+// IsActiveAt 检查授权在指定时间是否有效
+func (a *RoleElevation) IsActiveAt(t time.Time) bool {
+	return a.Status == ElevationApproved && a.ValidUntil != nil && t.Before(*a.ValidUntil)
+}
+
+// RoleElevationQueryParam 查询条件
+type RoleElevationQueryParam struct {
+	PaginationParam
+	TenantID    string   `form:"-"`       // 租户ID
+	UserID      string   `form:"user_id"` // 申请用户
+	RoleID      string   `form:"role_id"` // 申请的角色
+	Status      int      `form:"status"`  // 状态(1:待审批 2:已批准 3:已拒绝 4:已撤销 5:已到期)
+	UserRoleIDs []string `form:"-"`       // 批准后授予的用户角色ID列表
+}
+
+// RoleElevationQueryOptions 查询可选参数项
+type RoleElevationQueryOptions struct {
+	OrderFields []*OrderField // 排序字段
+}
+
+// RoleElevationQueryResult 查询结果
+type RoleElevationQueryResult struct {
+	Data       RoleElevations
+	PageResult *PaginationResult
+}
+
+// RoleElevations 临时提权申请列表
+type RoleElevations []*RoleElevation
+
+// RoleElevationReview 审批或撤销参数
+type RoleElevationReview struct {
+	Comment string `json:"comment"` // 审批意见或撤销原因
+}
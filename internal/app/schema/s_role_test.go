@@ -0,0 +1,37 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleParentsWouldCycle(t *testing.T) {
+	// member -> staff -> base, auditor -> base
+	links := RoleParents{
+		{RoleID: "member", ParentID: "staff"},
+		{RoleID: "staff", ParentID: "base"},
+		{RoleID: "auditor", ParentID: "base"},
+	}
+
+	tests := []struct {
+		name      string
+		roleID    string
+		parentIDs []string
+		expected  bool
+	}{
+		{"self", "member", []string{"member"}, true},
+		{"direct", "base", []string{"staff"}, true},
+		{"indirect", "base", []string{"auditor", "member"}, true},
+		{"sibling", "auditor", []string{"staff"}, false},
+		{"replace parents", "staff", []string{"auditor"}, false},
+		{"new role", "guest", []string{"member", "auditor"}, false},
+		{"no parents", "base", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, links.WouldCycle(tt.roleID, tt.parentIDs))
+		})
+	}
+}
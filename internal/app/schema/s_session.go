@@ -0,0 +1,33 @@
+package schema
+
+import (
+	"time"
+
+	"gin-casbin/pkg/util"
+)
+
+// Session 登录会话
+type Session struct {
+	ID         string    `json:"id"`           // 会话ID
+	UserID     string    `json:"user_id"`      // 用户ID
+	UserName   string    `json:"user_name"`    // 用户名
+	TenantID   string    `json:"tenant_id"`    // 租户ID
+	UserAgent  string    `json:"user_agent"`   // 客户端
+	IP         string    `json:"ip"`           // 客户端IP
+	CreatedAt  time.Time `json:"created_at"`   // 登录时间
+	LastSeenAt time.Time `json:"last_seen_at"` // 最后活动时间
+	ExpiresAt  time.Time `json:"expires_at"`   // 到期时间
+	Current    bool      `json:"current"`      // 是否是当前会话
+}
+
+func (a *Session) String() string {
+	return util.JSONMarshalToString(a)
+}
+
+// SessionQueryParam 查询条件
+type SessionQueryParam struct {
+	TenantID string `form:"tenant_id"` // 租户ID(仅根租户用户可指定)
+}
+
+// Sessions 登录会话列表
+type Sessions []*Session
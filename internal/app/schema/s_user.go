@@ -8,6 +8,9 @@ import (
 	"gin-casbin/pkg/util"
 )
 
+// RootTenantID root用户所属租户
+const RootTenantID = "wetrue"
+
 // GetRootUser 获取root用户
 func GetRootUser() *User {
 	user := config.C.Root
@@ -19,9 +22,9 @@ func GetRootUser() *User {
 	}
 }
 
-// CheckIsRootUser 检查是否是root用户
+// CheckIsRootUser 检查是否是root用户(未启用紧急访问账户时总是返回false)
 func CheckIsRootUser(ctx context.Context, userID string) bool {
-	return GetRootUser().ID == userID
+	return config.C.Root.Enable && GetRootUser().ID == userID
 }
 
 // User 用户对象
@@ -61,6 +64,7 @@ func (a *User) CleanSecure() *User {
 // UserQueryParam 查询条件
 type UserQueryParam struct {
 	PaginationParam
+	IDs        []string `form:"-"`          // 唯一标识列表
 	UserName   string   `form:"userName"`   // 用户名
 	QueryValue string   `form:"queryValue"` // 模糊查询
 	Email      string   `form:"email"`      // EMAIL
@@ -104,6 +108,15 @@ func (a Users) ToIDs() []string {
 	return idList
 }
 
+// ToMap 转换为用户ID映射
+func (a Users) ToMap() map[string]*User {
+	m := make(map[string]*User)
+	for _, item := range a {
+		m[item.ID] = item
+	}
+	return m
+}
+
 // ToUserShows 转换为用户显示列表
 func (a Users) ToUserShows(
 	mUserRoles map[string]UserRoles,
@@ -130,17 +143,60 @@ func (a Users) ToUserShows(
 
 // UserRole 用户角色
 type UserRole struct {
-	ID      string `json:"id"`      // 唯一标识
-	UserID  string `json:"user_id"` // 用户ID
-	RoleID  string `json:"role_id"` // 角色ID
-	Creator string `json:"creator"`
+	ID         string     `json:"id"`          // 唯一标识
+	UserID     string     `json:"user_id"`     // 用户ID
+	RoleID     string     `json:"role_id"`     // 角色ID
+	ValidFrom  *time.Time `json:"valid_from"`  // 生效时间(为空表示立即生效)
+	ValidUntil *time.Time `json:"valid_until"` // 失效时间(为空表示长期有效)
+	Creator    string     `json:"creator"`
+}
+
+// 角色授权状态
+const (
+	UserRoleActive   = "active"   // 有效
+	UserRoleUpcoming = "upcoming" // 未生效
+	UserRoleExpired  = "expired"  // 已失效
+)
+
+// GetStatus 获取指定时间的授权状态
+func (a *UserRole) GetStatus(t time.Time) string {
+	if a.ValidFrom != nil && t.Before(*a.ValidFrom) {
+		return UserRoleUpcoming
+	} else if a.ValidUntil != nil && !t.Before(*a.ValidUntil) {
+		return UserRoleExpired
+	}
+	return UserRoleActive
+}
+
+// IsValidAt 检查授权在指定时间是否有效
+func (a *UserRole) IsValidAt(t time.Time) bool {
+	return a.GetStatus(t) == UserRoleActive
+}
+
+// IsTimeBound 检查是否是有有效期的授权
+func (a *UserRole) IsTimeBound() bool {
+	return a.ValidFrom != nil || a.ValidUntil != nil
+}
+
+// EqualValidity 检查有效期是否相同
+func (a *UserRole) EqualValidity(b *UserRole) bool {
+	equal := func(x, y *time.Time) bool {
+		if x == nil || y == nil {
+			return x == y
+		}
+		return x.Equal(*y)
+	}
+	return equal(a.ValidFrom, b.ValidFrom) && equal(a.ValidUntil, b.ValidUntil)
 }
 
 // UserRoleQueryParam 查询条件
 type UserRoleQueryParam struct {
 	PaginationParam
-	UserID  string   // 用户ID
-	UserIDs []string // 用户ID列表
+	UserID       string     // 用户ID
+	UserIDs      []string   // 用户ID列表
+	TimeBound    bool       // 只查询有有效期的授权
+	BoundaryFrom *time.Time // 只查询生效或失效时间在(BoundaryFrom, BoundaryTo]之间的授权
+	BoundaryTo   *time.Time // 与BoundaryFrom同时指定
 }
 
 // UserRoleQueryOptions 查询可选参数项
@@ -175,6 +231,30 @@ func (a UserRoles) ToRoleIDs() []string {
 	return list
 }
 
+// ToUserIDs 转换为用户ID列表(去重)
+func (a UserRoles) ToUserIDs() []string {
+	var list []string
+	m := make(map[string]struct{})
+	for _, item := range a {
+		if _, ok := m[item.UserID]; !ok {
+			m[item.UserID] = struct{}{}
+			list = append(list, item.UserID)
+		}
+	}
+	return list
+}
+
+// ValidAt 过滤指定时间有效的授权
+func (a UserRoles) ValidAt(t time.Time) UserRoles {
+	var list UserRoles
+	for _, item := range a {
+		if item.IsValidAt(t) {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 // ToUserIDMap 转换为用户ID映射
 func (a UserRoles) ToUserIDMap() map[string]UserRoles {
 	m := make(map[string]UserRoles)
@@ -184,6 +264,25 @@ func (a UserRoles) ToUserIDMap() map[string]UserRoles {
 	return m
 }
 
+// UserRoleGrantQueryParam 角色授权查询条件
+type UserRoleGrantQueryParam struct {
+	UserID string `form:"user_id"` // 用户ID
+	Status string `form:"status"`  // 授权状态(active/upcoming/expired，为空表示全部)
+}
+
+// UserRoleGrant 有有效期的角色授权
+type UserRoleGrant struct {
+	ID         string     `json:"id"`          // 唯一标识
+	UserID     string     `json:"user_id"`     // 用户ID
+	UserName   string     `json:"user_name"`   // 用户名
+	TenantID   string     `json:"tenant_id"`   // 租户ID
+	RoleID     string     `json:"role_id"`     // 角色ID
+	RoleName   string     `json:"role_name"`   // 角色名称
+	ValidFrom  *time.Time `json:"valid_from"`  // 生效时间
+	ValidUntil *time.Time `json:"valid_until"` // 失效时间
+	Status     string     `json:"status"`      // 授权状态
+}
+
 // ----------------------------------------UserShow--------------------------------------
 
 // UserShow 用户显示项
@@ -26,8 +26,9 @@ type ErrorResult struct {
 
 // ErrorItem 响应错误项
 type ErrorItem struct {
-	Code    int    `json:"code"`    // 错误码
-	Message string `json:"message"` // 错误信息
+	Code       int    `json:"code"`                  // 错误码
+	Message    string `json:"message"`               // 错误信息
+	DecisionID string `json:"decision_id,omitempty"` // 策略决策ID
 }
 
 // ListResult 响应列表数据
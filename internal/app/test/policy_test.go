@@ -0,0 +1,50 @@
+package test
+
+import (
+	"testing"
+
+	"gin-casbin/internal/app/module/policytest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicySuites(t *testing.T) {
+	e, err := policytest.NewCSVEnforcer("../../../configs/model.conf", "testdata/policy.csv")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	suites, err := policytest.LoadPolicySuites("testdata")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, suites)
+
+	for _, suite := range suites {
+		suite := suite
+		t.Run(suite.Name, func(t *testing.T) {
+			assert.NotEmpty(t, suite.Cases)
+			for _, f := range policytest.RunPolicySuite(e, suite) {
+				t.Error(f.String())
+			}
+		})
+	}
+}
+
+func TestPolicySuiteFailure(t *testing.T) {
+	e, err := policytest.NewCSVEnforcer("../../../configs/model.conf", "testdata/policy.csv")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	suite := &policytest.PolicySuite{
+		Name: "failure",
+		Cases: []*policytest.PolicyCase{
+			{User: "u_member", Tenant: "tenant_a", Path: "/api/v1/users", Method: "DELETE", Expect: policytest.ExpectAllow},
+			{User: "u_member", Tenant: "tenant_a", Path: "/api/v1/users", Method: "GET", Expect: "yes"},
+		},
+	}
+	failures := policytest.RunPolicySuite(e, suite)
+	if assert.Len(t, failures, 2) {
+		assert.Equal(t, policytest.ExpectDeny, failures[0].Actual)
+		assert.NotNil(t, failures[1].Err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 // 定义错误
 var (
 	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenReused  = errors.New("refresh token reused")
 )
 
 // TokenInfo 令牌信息
@@ -18,21 +19,87 @@ type TokenInfo interface {
 	GetTokenType() string
 	// 获取令牌到期时间戳
 	GetExpiresAt() int64
+	// 获取刷新令牌
+	GetRefreshToken() string
+	// 获取刷新令牌到期时间戳
+	GetRefreshExpiresAt() int64
 	// JSON编码
 	EncodeToJSON() ([]byte, error)
 }
 
+// Claims 令牌声明
+type Claims struct {
+	UserID    string   // 用户ID
+	TenantID  string   // 租户ID
+	RoleIDs   []string // 角色ID列表(签发时的快照)
+	IsAdmin   bool     // 是否管理用户
+	SessionID string   // 会话ID(同一次登录刷新出的令牌相同)
+	Issuer    string   // 签发者
+	Audience  string   // 受众
+	IssuedAt  int64    // 签发时间戳
+	ExpiresAt int64    // 到期时间戳
+}
+
+// ClaimsResolver 刷新令牌时重新获取用户当前的角色和管理标识(返回错误时拒绝刷新)
+type ClaimsResolver func(ctx context.Context, claims Claims) (Claims, error)
+
+// Session 登录会话(同一次登录刷新出的令牌属于同一个会话)
+type Session struct {
+	ID         string `json:"id"`           // 会话ID
+	UserID     string `json:"user_id"`      // 用户ID
+	TenantID   string `json:"tenant_id"`    // 租户ID
+	UserAgent  string `json:"user_agent"`   // 客户端
+	IP         string `json:"ip"`           // 客户端IP
+	CreatedAt  int64  `json:"created_at"`   // 登录时间戳
+	LastSeenAt int64  `json:"last_seen_at"` // 最后活动时间戳
+	IssuedAt   int64  `json:"issued_at"`    // 最近签发令牌时间戳
+	ExpiresAt  int64  `json:"expires_at"`   // 到期时间戳
+}
+
 // Auther 认证接口
 type Auther interface {
-	// 生成令牌
-	GenerateToken(ctx context.Context, userID string, tenantID string) (TokenInfo, error)
+	// 生成令牌(使用声明中的用户、租户、角色和管理标识，会话、签发者和受众由认证实例生成)
+	GenerateToken(ctx context.Context, claims Claims) (TokenInfo, error)
+
+	// 使用刷新令牌生成新的令牌(刷新令牌每次使用后轮换，新令牌的角色和管理标识由resolve重新获取)
+	RefreshToken(ctx context.Context, refreshToken string, resolve ClaimsResolver) (TokenInfo, error)
 
 	// 销毁令牌
 	DestroyToken(ctx context.Context, accessToken string) error
 
+	// 撤销用户在此之前签发的所有令牌
+	RevokeUserTokens(ctx context.Context, userID string) error
+
+	// 撤销租户在此之前签发的所有令牌
+	RevokeTenantTokens(ctx context.Context, tenantID string) error
+
+	// 登记令牌所属的会话(记录客户端信息)
+	RegisterSession(ctx context.Context, accessToken, userAgent, ip string) error
+
+	// 获取有效的会话(不存在或已失效时为空)
+	GetSession(ctx context.Context, sessionID string) (*Session, error)
+
+	// 查询用户的有效会话
+	QueryUserSessions(ctx context.Context, userID string) ([]*Session, error)
+
+	// 查询租户的有效会话
+	QueryTenantSessions(ctx context.Context, tenantID string) ([]*Session, error)
+
+	// 终止会话(会话下的访问令牌和刷新令牌全部失效)
+	TerminateSession(ctx context.Context, sessionID string) error
+
 	// 解析用户ID
 	ParseUserID(ctx context.Context, accessToken string) (string, string, error)
 
+	// 解析令牌声明(无需查询数据库)
+	ParseClaims(ctx context.Context, accessToken string) (*Claims, error)
+
 	// 释放资源
 	Release() error
 }
+
+// KeyPublisher 发布用于验证令牌的公钥集合(其他服务无需共享密钥即可验证令牌)
+type KeyPublisher interface {
+	// 获取JWKS(JSON编码)
+	JWKS(ctx context.Context) ([]byte, error)
+}
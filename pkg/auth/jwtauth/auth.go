@@ -2,6 +2,12 @@ package jwtauth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
 	"time"
 
 	"gin-casbin/pkg/auth"
@@ -13,10 +19,12 @@ import (
 const defaultKey = "themis"
 
 var defaultOptions = options{
-	tokenType:     "Bearer",
-	expired:       7200,
-	signingMethod: jwt.SigningMethodHS512,
-	signingKey:    []byte(defaultKey),
+	tokenType:      "Bearer",
+	issuer:         "gin-casbin",
+	expired:        7200,
+	refreshExpired: 604800,
+	signingMethod:  jwt.SigningMethodHS512,
+	signingKey:     []byte(defaultKey),
 	keyfunc: func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, auth.ErrInvalidToken
@@ -26,11 +34,15 @@ var defaultOptions = options{
 }
 
 type options struct {
-	signingMethod jwt.SigningMethod
-	signingKey    interface{}
-	keyfunc       jwt.Keyfunc
-	expired       int
-	tokenType     string
+	signingMethod  jwt.SigningMethod
+	signingKey     interface{}
+	keyfunc        jwt.Keyfunc
+	expired        int
+	refreshExpired int
+	tokenType      string
+	issuer         string
+	audience       string
+	keySet         *KeySet
 }
 
 // Option 定义参数项
@@ -57,6 +69,28 @@ func SetKeyfunc(keyFunc jwt.Keyfunc) Option {
 	}
 }
 
+// SetKeySet 设定非对称签名的密钥集合(按kid选择验证密钥，替代签名方式和签名key)
+func SetKeySet(keySet *KeySet) Option {
+	return func(o *options) {
+		o.keySet = keySet
+		o.keyfunc = keySet.Keyfunc
+	}
+}
+
+// SetIssuer 设定令牌签发者(解析时校验)
+func SetIssuer(issuer string) Option {
+	return func(o *options) {
+		o.issuer = issuer
+	}
+}
+
+// SetAudience 设定令牌受众(不为空时解析时校验)
+func SetAudience(audience string) Option {
+	return func(o *options) {
+		o.audience = audience
+	}
+}
+
 // SetExpired 设定令牌过期时长(单位秒，默认7200)
 func SetExpired(expired int) Option {
 	return func(o *options) {
@@ -64,6 +98,13 @@ func SetExpired(expired int) Option {
 	}
 }
 
+// SetRefreshExpired 设定刷新令牌过期时长(单位秒，默认604800)
+func SetRefreshExpired(expired int) Option {
+	return func(o *options) {
+		o.refreshExpired = expired
+	}
+}
+
 // New 创建认证实例
 func New(store Storer, opts ...Option) *JWTAuth {
 	o := defaultOptions
@@ -81,22 +122,51 @@ func New(store Storer, opts ...Option) *JWTAuth {
 type JWTAuth struct {
 	opts  *options
 	store Storer
+	// 会话记录的读写锁(会话索引使用存储的集合操作)
+	sessionMu sync.Mutex
 }
 
-// GenerateToken 生成令牌
-func (a *JWTAuth) GenerateToken(ctx context.Context, userID string, tenantID string) (auth.TokenInfo, error) {
+// GenerateToken 生成令牌(每次登录生成新的会话)
+func (a *JWTAuth) GenerateToken(ctx context.Context, claims auth.Claims) (auth.TokenInfo, error) {
+	sessionID, err := newRandomString(16)
+	if err != nil {
+		return nil, err
+	}
+	return a.generateToken(ctx, &refreshRecord{
+		UserID:    claims.UserID,
+		TenantID:  claims.TenantID,
+		RoleIDs:   claims.RoleIDs,
+		IsAdmin:   claims.IsAdmin,
+		SessionID: sessionID,
+	})
+}
+
+func (a *JWTAuth) generateToken(ctx context.Context, session *refreshRecord) (*tokenInfo, error) {
+	tokenID, err := newRandomString(16)
+	if err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 	expiresAt := now.Add(time.Duration(a.opts.expired) * time.Second).Unix()
 
-	token := jwt.NewWithClaims(a.opts.signingMethod, &jwt.StandardClaims{
-		IssuedAt:  now.Unix(),
-		ExpiresAt: expiresAt,
-		NotBefore: now.Unix(),
-		Subject:   userID,
-		Issuer:    tenantID,
-	})
+	claims := &tokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        tokenID,
+			Audience:  a.opts.audience,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: expiresAt,
+			NotBefore: now.Unix(),
+			Subject:   session.UserID,
+			Issuer:    a.opts.issuer,
+		},
+		TenantID:  session.TenantID,
+		RoleIDs:   session.RoleIDs,
+		IsAdmin:   session.IsAdmin,
+		SessionID: session.SessionID,
+	}
 
-	tokenString, err := token.SignedString(a.opts.signingKey)
+	tokenString, err := a.signToken(now, claims)
 	if err != nil {
 		return nil, err
 	}
@@ -106,19 +176,237 @@ func (a *JWTAuth) GenerateToken(ctx context.Context, userID string, tenantID str
 		TokenType:   a.opts.tokenType,
 		AccessToken: tokenString,
 	}
+
+	// 刷新令牌保存在存储中，未设定存储时不生成
+	err = a.callStore(func(store Storer) error {
+		refreshToken, err := newRandomString(32)
+		if err != nil {
+			return err
+		}
+
+		refreshExpiresAt := now.Add(time.Duration(a.opts.refreshExpired) * time.Second)
+		err = a.setRefreshRecord(ctx, store, refreshToken, &refreshRecord{
+			UserID:    session.UserID,
+			TenantID:  session.TenantID,
+			RoleIDs:   session.RoleIDs,
+			IsAdmin:   session.IsAdmin,
+			SessionID: session.SessionID,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: refreshExpiresAt.Unix(),
+		})
+		if err != nil {
+			return err
+		}
+
+		tokenInfo.RefreshToken = refreshToken
+		tokenInfo.RefreshExpiresAt = refreshExpiresAt.Unix()
+		return a.renewSession(ctx, store, session.SessionID, now.Unix(), refreshExpiresAt.Unix())
+	})
+	if err != nil {
+		return nil, err
+	}
 	return tokenInfo, nil
 }
 
-// 解析令牌
-func (a *JWTAuth) parseToken(tokenString string) (*jwt.StandardClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.StandardClaims{}, a.opts.keyfunc)
+// 签名令牌(设定了密钥集合时使用当前生效的密钥签名，并在令牌头中写入kid)
+func (a *JWTAuth) signToken(now time.Time, claims jwt.Claims) (string, error) {
+	keySet := a.opts.keySet
+	if keySet == nil {
+		return jwt.NewWithClaims(a.opts.signingMethod, claims).SignedString(a.opts.signingKey)
+	}
+
+	key, err := keySet.SigningKey(now)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.PrivateKey)
+}
+
+// RefreshToken 使用刷新令牌生成新的令牌(刷新令牌只能使用一次，重复使用已轮换的刷新令牌将撤销整个会话)
+//
+// 用户、租户和会话沿用刷新令牌中的记录，角色和管理标识由resolve重新获取，resolve为空时沿用签发时的快照
+func (a *JWTAuth) RefreshToken(ctx context.Context, refreshToken string, resolve auth.ClaimsResolver) (auth.TokenInfo, error) {
+	if refreshToken == "" || a.store == nil {
+		return nil, auth.ErrInvalidToken
+	}
+
+	record, err := a.getRefreshRecord(ctx, a.store, refreshToken)
+	if err != nil {
+		return nil, err
+	} else if record == nil || time.Now().Unix() >= record.ExpiresAt {
+		return nil, auth.ErrInvalidToken
+	}
+
+	if revoked, err := a.store.Check(ctx, sessionKey(record.SessionID)); err != nil {
+		return nil, err
+	} else if revoked {
+		return nil, auth.ErrInvalidToken
+	}
+
+	if revoked, err := a.checkWatermark(ctx, a.store, record.UserID, record.TenantID, record.IssuedAt); err != nil {
+		return nil, err
+	} else if revoked {
+		return nil, auth.ErrInvalidToken
+	}
+
+	if resolve != nil {
+		claims, err := resolve(ctx, auth.Claims{
+			UserID:    record.UserID,
+			TenantID:  record.TenantID,
+			RoleIDs:   record.RoleIDs,
+			IsAdmin:   record.IsAdmin,
+			SessionID: record.SessionID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		record.RoleIDs = claims.RoleIDs
+		record.IsAdmin = claims.IsAdmin
+	}
+
+	// 原子地标记刷新令牌已使用(多实例共享存储时只有一个请求能成功)，标记保留到刷新令牌到期，用于检测重复使用
+	expired := time.Unix(record.ExpiresAt, 0).Sub(time.Now())
+	if ok, err := a.store.SetNX(ctx, refreshUsedKey(refreshToken), "1", expired); err != nil {
+		return nil, err
+	} else if !ok {
+		if err := a.revokeSession(ctx, a.store, record.SessionID); err != nil {
+			return nil, err
+		}
+		return nil, auth.ErrTokenReused
+	}
+
+	return a.generateToken(ctx, record)
+}
+
+// 撤销会话(会话下的访问令牌和刷新令牌全部失效，并删除会话记录)
+func (a *JWTAuth) revokeSession(ctx context.Context, store Storer, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	err := store.Set(ctx, sessionKey(sessionID), a.maxExpiration())
+	if err != nil {
+		return err
+	}
+	return a.removeSession(ctx, store, sessionID)
+}
+
+// 令牌的最长有效时长(撤销记录只需保留到已签发的令牌全部过期)
+func (a *JWTAuth) maxExpiration() time.Duration {
+	expiration := time.Duration(a.opts.refreshExpired) * time.Second
+	if v := time.Duration(a.opts.expired) * time.Second; v > expiration {
+		expiration = v
+	}
+	return expiration
+}
+
+// RevokeUserTokens 撤销用户在此之前签发的所有令牌(包括刷新令牌)
+func (a *JWTAuth) RevokeUserTokens(ctx context.Context, userID string) error {
+	return a.callStore(func(store Storer) error {
+		return store.SetWatermark(ctx, userWatermarkKey(userID), time.Now(), a.maxExpiration())
+	})
+}
+
+// RevokeTenantTokens 撤销租户在此之前签发的所有令牌(包括刷新令牌)
+func (a *JWTAuth) RevokeTenantTokens(ctx context.Context, tenantID string) error {
+	return a.callStore(func(store Storer) error {
+		return store.SetWatermark(ctx, tenantWatermarkKey(tenantID), time.Now(), a.maxExpiration())
+	})
+}
+
+// 检查令牌的签发时间是否早于或等于用户或租户的水位线
+func (a *JWTAuth) checkWatermark(ctx context.Context, store Storer, userID, tenantID string, issuedAt int64) (bool, error) {
+	keys := []string{userWatermarkKey(userID)}
+	if tenantID != "" {
+		keys = append(keys, tenantWatermarkKey(tenantID))
+	}
+
+	for _, key := range keys {
+		t, err := store.GetWatermark(ctx, key)
+		if err != nil {
+			return false, err
+		} else if !t.IsZero() && issuedAt <= t.Unix() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (a *JWTAuth) getRefreshRecord(ctx context.Context, store Storer, refreshToken string) (*refreshRecord, error) {
+	value, ok, err := store.Get(ctx, refreshKey(refreshToken))
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, nil
+	}
+
+	var record refreshRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (a *JWTAuth) setRefreshRecord(ctx context.Context, store Storer, refreshToken string, record *refreshRecord) error {
+	buf, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	expired := time.Unix(record.ExpiresAt, 0).Sub(time.Now())
+	return store.SetValue(ctx, refreshKey(refreshToken), string(buf), expired)
+}
+
+// 存储中只保存刷新令牌的摘要
+func refreshDigest(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
+
+func refreshKey(refreshToken string) string {
+	return "refresh:" + refreshDigest(refreshToken)
+}
+
+func refreshUsedKey(refreshToken string) string {
+	return "used:" + refreshDigest(refreshToken)
+}
+
+func sessionKey(sessionID string) string {
+	return "session:" + sessionID
+}
+
+func userWatermarkKey(userID string) string {
+	return "watermark:user:" + userID
+}
+
+func tenantWatermarkKey(tenantID string) string {
+	return "watermark:tenant:" + tenantID
+}
+
+func newRandomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// 解析令牌(校验签发者和受众)
+func (a *JWTAuth) parseToken(tokenString string) (*tokenClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &tokenClaims{}, a.opts.keyfunc)
 	if err != nil {
 		return nil, err
 	} else if !token.Valid {
 		return nil, auth.ErrInvalidToken
 	}
 
-	return token.Claims.(*jwt.StandardClaims), nil
+	claims := token.Claims.(*tokenClaims)
+	if v := a.opts.issuer; v != "" && !claims.VerifyIssuer(v, true) {
+		return nil, auth.ErrInvalidToken
+	} else if v := a.opts.audience; v != "" && !claims.VerifyAudience(v, true) {
+		return nil, auth.ErrInvalidToken
+	}
+	return claims, nil
 }
 
 func (a *JWTAuth) callStore(fn func(Storer) error) error {
@@ -135,23 +423,36 @@ func (a *JWTAuth) DestroyToken(ctx context.Context, tokenString string) error {
 		return err
 	}
 
-	// 如果设定了存储，则将未过期的令牌放入
+	// 如果设定了存储，则将未过期的令牌放入，并撤销会话(刷新令牌同时失效)
 	return a.callStore(func(store Storer) error {
 		expired := time.Unix(claims.ExpiresAt, 0).Sub(time.Now())
-		return store.Set(ctx, tokenString, expired)
+		err := store.Set(ctx, tokenString, expired)
+		if err != nil {
+			return err
+		}
+		return a.revokeSession(ctx, store, claims.SessionID)
 	})
 }
 
 // ParseUserID 解析用户ID
 func (a *JWTAuth) ParseUserID(ctx context.Context, tokenString string) (string, string, error) {
+	claims, err := a.ParseClaims(ctx, tokenString)
+	if err != nil {
+		return "", "", err
+	}
+	return claims.UserID, claims.TenantID, nil
+}
+
+// ParseClaims 解析令牌声明(已销毁的令牌、已撤销的会话以及用户或租户水位线之前签发的令牌无效)
+func (a *JWTAuth) ParseClaims(ctx context.Context, tokenString string) (*auth.Claims, error) {
 	if tokenString == "" {
-		return "", "", auth.ErrInvalidToken
+		return nil, auth.ErrInvalidToken
 	}
 
 	claims, err := a.parseToken(tokenString)
 	if err != nil {
 		logrus.Errorf("%s", err)
-		return "", "", auth.ErrInvalidToken
+		return nil, auth.ErrInvalidToken
 	}
 
 	err = a.callStore(func(store Storer) error {
@@ -160,13 +461,40 @@ func (a *JWTAuth) ParseUserID(ctx context.Context, tokenString string) (string,
 		} else if exists {
 			return auth.ErrInvalidToken
 		}
+
+		if claims.SessionID != "" {
+			if revoked, err := store.Check(ctx, sessionKey(claims.SessionID)); err != nil {
+				return err
+			} else if revoked {
+				return auth.ErrInvalidToken
+			}
+		}
+
+		if revoked, err := a.checkWatermark(ctx, store, claims.Subject, claims.TenantID, claims.IssuedAt); err != nil {
+			return err
+		} else if revoked {
+			return auth.ErrInvalidToken
+		}
+
+		if claims.SessionID != "" {
+			a.touchSession(ctx, store, claims.SessionID)
+		}
 		return nil
 	})
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
-	return claims.Subject, claims.Issuer, nil
+	return claims.toClaims(), nil
+}
+
+// JWKS 获取用于验证令牌的公钥集合(未设定密钥集合时为空)
+func (a *JWTAuth) JWKS(ctx context.Context) ([]byte, error) {
+	set := &JSONWebKeySet{Keys: []*JSONWebKey{}}
+	if keySet := a.opts.keySet; keySet != nil {
+		set = keySet.JWKS(time.Now())
+	}
+	return json.Marshal(set)
 }
 
 // Release 释放资源
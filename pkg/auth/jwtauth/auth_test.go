@@ -2,8 +2,10 @@ package jwtauth
 
 import (
 	"context"
+	"sync"
 	"testing"
 
+	"gin-casbin/pkg/auth"
 	"gin-casbin/pkg/auth/jwtauth/store/buntdb"
 
 	"github.com/stretchr/testify/assert"
@@ -20,7 +22,7 @@ func TestAuth(t *testing.T) {
 	ctx := context.Background()
 	userID := "test"
 	tenantID := "tenant"
-	token, err := jwtAuth.GenerateToken(ctx, userID, tenantID)
+	token, err := jwtAuth.GenerateToken(ctx, auth.Claims{UserID: userID, TenantID: tenantID})
 	assert.Nil(t, err)
 	assert.NotNil(t, token)
 
@@ -38,3 +40,260 @@ func TestAuth(t *testing.T) {
 	assert.Empty(t, id)
 	assert.Empty(t, tid)
 }
+
+func TestRefreshToken(t *testing.T) {
+	store, err := buntdb.NewStore(":memory:")
+	assert.Nil(t, err)
+
+	jwtAuth := New(store)
+
+	defer jwtAuth.Release()
+
+	ctx := context.Background()
+	userID := "test"
+	tenantID := "tenant"
+	token, err := jwtAuth.GenerateToken(ctx, auth.Claims{UserID: userID, TenantID: tenantID})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, token.GetRefreshToken())
+	assert.Greater(t, token.GetRefreshExpiresAt(), token.GetExpiresAt())
+
+	newToken, err := jwtAuth.RefreshToken(ctx, token.GetRefreshToken(), nil)
+	assert.Nil(t, err)
+	assert.NotEqual(t, token.GetRefreshToken(), newToken.GetRefreshToken())
+
+	id, tid, err := jwtAuth.ParseUserID(ctx, newToken.GetAccessToken())
+	assert.Nil(t, err)
+	assert.Equal(t, userID, id)
+	assert.Equal(t, tenantID, tid)
+
+	// 重复使用已轮换的刷新令牌，撤销整个会话
+	_, err = jwtAuth.RefreshToken(ctx, token.GetRefreshToken(), nil)
+	assert.EqualError(t, err, "refresh token reused")
+
+	_, err = jwtAuth.RefreshToken(ctx, newToken.GetRefreshToken(), nil)
+	assert.EqualError(t, err, "invalid token")
+
+	_, _, err = jwtAuth.ParseUserID(ctx, newToken.GetAccessToken())
+	assert.EqualError(t, err, "invalid token")
+
+	_, err = jwtAuth.RefreshToken(ctx, "unknown", nil)
+	assert.EqualError(t, err, "invalid token")
+}
+
+func TestRefreshTokenConcurrentReuse(t *testing.T) {
+	store, err := buntdb.NewStore(":memory:")
+	assert.Nil(t, err)
+
+	jwtAuth := New(store)
+
+	defer jwtAuth.Release()
+
+	ctx := context.Background()
+	token, err := jwtAuth.GenerateToken(ctx, auth.Claims{UserID: "test", TenantID: "tenant"})
+	assert.Nil(t, err)
+
+	// 同一刷新令牌被并发使用时只有一个请求成功，其余请求视为重复使用
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		success int
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := jwtAuth.RefreshToken(ctx, token.GetRefreshToken(), nil); err == nil {
+				mu.Lock()
+				success++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, success)
+}
+
+func TestDestroyTokenRevokesRefreshToken(t *testing.T) {
+	store, err := buntdb.NewStore(":memory:")
+	assert.Nil(t, err)
+
+	jwtAuth := New(store)
+
+	defer jwtAuth.Release()
+
+	ctx := context.Background()
+	token, err := jwtAuth.GenerateToken(ctx, auth.Claims{UserID: "test", TenantID: "tenant"})
+	assert.Nil(t, err)
+
+	err = jwtAuth.DestroyToken(ctx, token.GetAccessToken())
+	assert.Nil(t, err)
+
+	_, err = jwtAuth.RefreshToken(ctx, token.GetRefreshToken(), nil)
+	assert.EqualError(t, err, "invalid token")
+}
+
+func TestParseClaims(t *testing.T) {
+	store, err := buntdb.NewStore(":memory:")
+	assert.Nil(t, err)
+
+	jwtAuth := New(store, SetIssuer("issuer"), SetAudience("audience"))
+
+	defer jwtAuth.Release()
+
+	ctx := context.Background()
+	token, err := jwtAuth.GenerateToken(ctx, auth.Claims{
+		UserID:   "test",
+		TenantID: "tenant",
+		RoleIDs:  []string{"role_a", "role_b"},
+		IsAdmin:  true,
+	})
+	assert.Nil(t, err)
+
+	claims, err := jwtAuth.ParseClaims(ctx, token.GetAccessToken())
+	assert.Nil(t, err)
+	assert.Equal(t, "test", claims.UserID)
+	assert.Equal(t, "tenant", claims.TenantID)
+	assert.Equal(t, []string{"role_a", "role_b"}, claims.RoleIDs)
+	assert.True(t, claims.IsAdmin)
+	assert.Equal(t, "issuer", claims.Issuer)
+	assert.Equal(t, "audience", claims.Audience)
+	assert.NotEmpty(t, claims.SessionID)
+
+	// 刷新后的令牌属于同一个会话，角色和管理标识重新获取
+	newToken, err := jwtAuth.RefreshToken(ctx, token.GetRefreshToken(), func(ctx context.Context, c auth.Claims) (auth.Claims, error) {
+		assert.Equal(t, "test", c.UserID)
+		assert.Equal(t, claims.SessionID, c.SessionID)
+		c.RoleIDs = []string{"role_a"}
+		c.IsAdmin = false
+		return c, nil
+	})
+	assert.Nil(t, err)
+	newClaims, err := jwtAuth.ParseClaims(ctx, newToken.GetAccessToken())
+	assert.Nil(t, err)
+	assert.Equal(t, claims.SessionID, newClaims.SessionID)
+	assert.Equal(t, "tenant", newClaims.TenantID)
+	assert.Equal(t, []string{"role_a"}, newClaims.RoleIDs)
+	assert.False(t, newClaims.IsAdmin)
+
+	// 重新获取声明失败时拒绝刷新，刷新令牌仍可使用
+	_, err = jwtAuth.RefreshToken(ctx, newToken.GetRefreshToken(), func(ctx context.Context, c auth.Claims) (auth.Claims, error) {
+		return c, auth.ErrInvalidToken
+	})
+	assert.EqualError(t, err, "invalid token")
+	_, err = jwtAuth.RefreshToken(ctx, newToken.GetRefreshToken(), nil)
+	assert.Nil(t, err)
+
+	// 签发者或受众不匹配
+	other := New(store, SetAudience("other"))
+	_, err = other.ParseClaims(ctx, token.GetAccessToken())
+	assert.EqualError(t, err, "invalid token")
+}
+
+func TestRevokeTokens(t *testing.T) {
+	store, err := buntdb.NewStore(":memory:")
+	assert.Nil(t, err)
+
+	jwtAuth := New(store)
+
+	defer jwtAuth.Release()
+
+	ctx := context.Background()
+	userToken, err := jwtAuth.GenerateToken(ctx, auth.Claims{UserID: "user_a", TenantID: "tenant_a"})
+	assert.Nil(t, err)
+	otherToken, err := jwtAuth.GenerateToken(ctx, auth.Claims{UserID: "user_b", TenantID: "tenant_a"})
+	assert.Nil(t, err)
+	tenantToken, err := jwtAuth.GenerateToken(ctx, auth.Claims{UserID: "user_c", TenantID: "tenant_b"})
+	assert.Nil(t, err)
+
+	// 撤销用户令牌，同一租户的其他用户不受影响
+	err = jwtAuth.RevokeUserTokens(ctx, "user_a")
+	assert.Nil(t, err)
+
+	_, _, err = jwtAuth.ParseUserID(ctx, userToken.GetAccessToken())
+	assert.EqualError(t, err, "invalid token")
+	_, err = jwtAuth.RefreshToken(ctx, userToken.GetRefreshToken(), nil)
+	assert.EqualError(t, err, "invalid token")
+
+	_, _, err = jwtAuth.ParseUserID(ctx, otherToken.GetAccessToken())
+	assert.Nil(t, err)
+
+	// 撤销租户令牌
+	err = jwtAuth.RevokeTenantTokens(ctx, "tenant_b")
+	assert.Nil(t, err)
+
+	_, _, err = jwtAuth.ParseUserID(ctx, tenantToken.GetAccessToken())
+	assert.EqualError(t, err, "invalid token")
+	_, err = jwtAuth.RefreshToken(ctx, tenantToken.GetRefreshToken(), nil)
+	assert.EqualError(t, err, "invalid token")
+
+	_, _, err = jwtAuth.ParseUserID(ctx, otherToken.GetAccessToken())
+	assert.Nil(t, err)
+}
+
+func TestSessions(t *testing.T) {
+	store, err := buntdb.NewStore(":memory:")
+	assert.Nil(t, err)
+
+	jwtAuth := New(store)
+
+	defer jwtAuth.Release()
+
+	ctx := context.Background()
+	tokenA, err := jwtAuth.GenerateToken(ctx, auth.Claims{UserID: "user_a", TenantID: "tenant"})
+	assert.Nil(t, err)
+	err = jwtAuth.RegisterSession(ctx, tokenA.GetAccessToken(), "agent_a", "10.0.0.1")
+	assert.Nil(t, err)
+
+	tokenB, err := jwtAuth.GenerateToken(ctx, auth.Claims{UserID: "user_b", TenantID: "tenant"})
+	assert.Nil(t, err)
+	err = jwtAuth.RegisterSession(ctx, tokenB.GetAccessToken(), "agent_b", "10.0.0.2")
+	assert.Nil(t, err)
+
+	sessions, err := jwtAuth.QueryUserSessions(ctx, "user_a")
+	assert.Nil(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, "agent_a", sessions[0].UserAgent)
+	assert.Equal(t, "10.0.0.1", sessions[0].IP)
+	assert.Equal(t, "tenant", sessions[0].TenantID)
+
+	sessions, err = jwtAuth.QueryTenantSessions(ctx, "tenant")
+	assert.Nil(t, err)
+	assert.Len(t, sessions, 2)
+
+	// 刷新令牌不产生新的会话
+	newTokenA, err := jwtAuth.RefreshToken(ctx, tokenA.GetRefreshToken(), nil)
+	assert.Nil(t, err)
+
+	claims, err := jwtAuth.ParseClaims(ctx, newTokenA.GetAccessToken())
+	assert.Nil(t, err)
+	session, err := jwtAuth.GetSession(ctx, claims.SessionID)
+	assert.Nil(t, err)
+	assert.NotNil(t, session)
+	assert.Equal(t, "agent_a", session.UserAgent)
+
+	// 终止会话后令牌失效，会话不再列出
+	err = jwtAuth.TerminateSession(ctx, claims.SessionID)
+	assert.Nil(t, err)
+
+	_, err = jwtAuth.ParseClaims(ctx, newTokenA.GetAccessToken())
+	assert.EqualError(t, err, "invalid token")
+	_, err = jwtAuth.RefreshToken(ctx, newTokenA.GetRefreshToken(), nil)
+	assert.EqualError(t, err, "invalid token")
+
+	session, err = jwtAuth.GetSession(ctx, claims.SessionID)
+	assert.Nil(t, err)
+	assert.Nil(t, session)
+
+	sessions, err = jwtAuth.QueryTenantSessions(ctx, "tenant")
+	assert.Nil(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, "user_b", sessions[0].UserID)
+
+	// 撤销用户令牌后会话不再列出
+	err = jwtAuth.RevokeUserTokens(ctx, "user_b")
+	assert.Nil(t, err)
+
+	sessions, err = jwtAuth.QueryTenantSessions(ctx, "tenant")
+	assert.Nil(t, err)
+	assert.Len(t, sessions, 0)
+}
@@ -0,0 +1,271 @@
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sort"
+	"time"
+
+	"gin-casbin/pkg/auth"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// 定义错误
+var (
+	ErrNoSigningKey = errors.New("no active signing key")
+)
+
+// Key 非对称签名密钥
+type Key struct {
+	ID         string            // 密钥标识(令牌头中的kid)
+	Method     jwt.SigningMethod // 签名方式
+	PrivateKey crypto.PrivateKey // 私钥(为空时只用于验证)
+	PublicKey  crypto.PublicKey  // 公钥
+	NotBefore  time.Time         // 开始用于签名的时间(零值表示立即)
+	NotAfter   time.Time         // 停止用于验证的时间(零值表示不限制)
+}
+
+// 检查密钥在指定时间是否可用于验证
+func (k *Key) verifiableAt(t time.Time) bool {
+	return k.NotAfter.IsZero() || t.Before(k.NotAfter)
+}
+
+// 检查密钥在指定时间是否可用于签名
+func (k *Key) signableAt(t time.Time) bool {
+	return k.PrivateKey != nil && !t.Before(k.NotBefore) && k.verifiableAt(t)
+}
+
+// GetSigningMethod 根据算法名称获取非对称签名方式
+func GetSigningMethod(alg string) (jwt.SigningMethod, error) {
+	switch alg {
+	case "RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA":
+		return jwt.GetSigningMethod(alg), nil
+	}
+	return nil, fmt.Errorf("unsupported signing method: %s", alg)
+}
+
+// LoadKeyFile 从PEM文件加载密钥(私钥或公钥)
+func LoadKeyFile(id, alg, path string) (*Key, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeyPEM(id, alg, buf)
+}
+
+// ParseKeyPEM 解析PEM格式的密钥(支持PKCS1/PKCS8/SEC1私钥和PKIX公钥)
+func ParseKeyPEM(id, alg string, data []byte) (*Key, error) {
+	method, err := GetSigningMethod(alg)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid pem data of key %s", id)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported pem type of key %s: %s", id, block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{
+		ID:     id,
+		Method: method,
+	}
+	switch v := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.PrivateKey, key.PublicKey = v, &v.PublicKey
+	case *ecdsa.PrivateKey:
+		key.PrivateKey, key.PublicKey = v, &v.PublicKey
+	case ed25519.PrivateKey:
+		key.PrivateKey, key.PublicKey = v, v.Public()
+	default:
+		key.PublicKey = v
+	}
+
+	if err := checkKeyMethod(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// 检查密钥类型与签名方式是否匹配
+func checkKeyMethod(key *Key) error {
+	var ok bool
+	switch pub := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		_, ok = key.Method.(*jwt.SigningMethodRSA)
+	case *ecdsa.PublicKey:
+		var m *jwt.SigningMethodECDSA
+		if m, ok = key.Method.(*jwt.SigningMethodECDSA); ok {
+			ok = pub.Curve.Params().BitSize == m.CurveBits
+		}
+	case ed25519.PublicKey:
+		ok = key.Method == SigningMethodEdDSA
+	}
+	if !ok {
+		return fmt.Errorf("key %s does not match signing method %s", key.ID, key.Method.Alg())
+	}
+	return nil
+}
+
+// NewKeySet 创建密钥集合
+func NewKeySet(keys ...*Key) (*KeySet, error) {
+	mKeys := make(map[string]*Key)
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, errors.New("key id is required")
+		} else if _, ok := mKeys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id: %s", key.ID)
+		} else if key.Method == nil || key.PublicKey == nil {
+			return nil, fmt.Errorf("invalid key: %s", key.ID)
+		} else if err := checkKeyMethod(key); err != nil {
+			return nil, err
+		}
+		mKeys[key.ID] = key
+	}
+
+	list := make([]*Key, len(keys))
+	copy(list, keys)
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].NotBefore.After(list[j].NotBefore)
+	})
+
+	return &KeySet{
+		keys:  list,
+		mKeys: mKeys,
+	}, nil
+}
+
+// KeySet 密钥集合(按生效时间轮换签名密钥，已停用的密钥在到期前仍可用于验证)
+type KeySet struct {
+	keys  []*Key // 按生效时间倒序
+	mKeys map[string]*Key
+}
+
+// SigningKey 获取指定时间的签名密钥(最近生效的私钥)
+func (a *KeySet) SigningKey(t time.Time) (*Key, error) {
+	for _, key := range a.keys {
+		if key.signableAt(t) {
+			return key, nil
+		}
+	}
+	return nil, ErrNoSigningKey
+}
+
+// Keyfunc 根据令牌头中的kid选择验证密钥
+func (a *KeySet) Keyfunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	key, ok := a.mKeys[kid]
+	if !ok || !key.verifiableAt(time.Now()) || t.Method.Alg() != key.Method.Alg() {
+		return nil, auth.ErrInvalidToken
+	}
+	return key.PublicKey, nil
+}
+
+// JWKS 获取可用于验证的公钥集合(包括尚未生效的密钥，以便验证方提前获取)
+func (a *KeySet) JWKS(t time.Time) *JSONWebKeySet {
+	set := &JSONWebKeySet{
+		Keys: []*JSONWebKey{},
+	}
+	for _, key := range a.keys {
+		if !key.verifiableAt(t) {
+			continue
+		}
+		set.Keys = append(set.Keys, newJSONWebKey(key))
+	}
+	return set
+}
+
+// JSONWebKeySet JWKS(RFC 7517)
+type JSONWebKeySet struct {
+	Keys []*JSONWebKey `json:"keys"`
+}
+
+// JSONWebKey JWK公钥
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+func newJSONWebKey(key *Key) *JSONWebKey {
+	jwk := &JSONWebKey{
+		Kid: key.ID,
+		Use: "sig",
+		Alg: key.Method.Alg(),
+	}
+
+	encode := base64.RawURLEncoding.EncodeToString
+	switch pub := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encode(pub.N.Bytes())
+		jwk.E = encode(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = curveName(pub.Curve)
+		jwk.X = encode(padBytes(pub.X.Bytes(), size))
+		jwk.Y = encode(padBytes(pub.Y.Bytes(), size))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = encode(pub)
+	}
+	return jwk
+}
+
+func curveName(curve elliptic.Curve) string {
+	switch curve {
+	case elliptic.P256():
+		return "P-256"
+	case elliptic.P384():
+		return "P-384"
+	case elliptic.P521():
+		return "P-521"
+	}
+	return curve.Params().Name
+}
+
+func padBytes(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	buf := make([]byte, size)
+	copy(buf[size-len(b):], b)
+	return buf
+}
@@ -0,0 +1,109 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"gin-casbin/pkg/auth"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestKeyPEM(t *testing.T, alg string) []byte {
+	var (
+		priv interface{}
+		err  error
+	)
+	switch alg {
+	case "RS256":
+		priv, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ES256":
+		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "EdDSA":
+		_, priv, err = ed25519.GenerateKey(rand.Reader)
+	}
+	assert.Nil(t, err)
+
+	buf, err := x509.MarshalPKCS8PrivateKey(priv)
+	assert.Nil(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: buf})
+}
+
+func TestKeySetSigning(t *testing.T) {
+	ctx := context.Background()
+	for _, alg := range []string{"RS256", "ES256", "EdDSA"} {
+		key, err := ParseKeyPEM(alg+"-1", alg, newTestKeyPEM(t, alg))
+		assert.Nil(t, err)
+
+		keySet, err := NewKeySet(key)
+		assert.Nil(t, err)
+
+		jwtAuth := New(nil, SetKeySet(keySet))
+		token, err := jwtAuth.GenerateToken(ctx, auth.Claims{UserID: "test", TenantID: "tenant"})
+		assert.Nil(t, err, alg)
+
+		id, tid, err := jwtAuth.ParseUserID(ctx, token.GetAccessToken())
+		assert.Nil(t, err, alg)
+		assert.Equal(t, "test", id)
+		assert.Equal(t, "tenant", tid)
+
+		// 密钥类型与签名方式不匹配
+		_, err = ParseKeyPEM(alg+"-2", "RS512", newTestKeyPEM(t, "EdDSA"))
+		assert.NotNil(t, err)
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	oldKey, err := ParseKeyPEM("old", "ES256", newTestKeyPEM(t, "ES256"))
+	assert.Nil(t, err)
+	oldKey.NotBefore = now.Add(-time.Hour)
+
+	newKey, err := ParseKeyPEM("new", "EdDSA", newTestKeyPEM(t, "EdDSA"))
+	assert.Nil(t, err)
+	newKey.NotBefore = now.Add(time.Hour)
+
+	keySet, err := NewKeySet(oldKey, newKey)
+	assert.Nil(t, err)
+
+	key, err := keySet.SigningKey(now)
+	assert.Nil(t, err)
+	assert.Equal(t, "old", key.ID)
+
+	key, err = keySet.SigningKey(now.Add(2 * time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, "new", key.ID)
+
+	// 未生效的密钥也会发布，以便验证方提前获取
+	buf, err := New(nil, SetKeySet(keySet)).JWKS(ctx)
+	assert.Nil(t, err)
+	var jwks JSONWebKeySet
+	assert.Nil(t, json.Unmarshal(buf, &jwks))
+	assert.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "EC", jwks.Keys[1].Kty)
+	assert.Equal(t, "P-256", jwks.Keys[1].Crv)
+	assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+
+	// 停用的密钥签发的令牌失效
+	jwtAuth := New(nil, SetKeySet(keySet))
+	token, err := jwtAuth.GenerateToken(ctx, auth.Claims{UserID: "test", TenantID: "tenant"})
+	assert.Nil(t, err)
+
+	oldKey.NotAfter = now.Add(-time.Minute)
+	_, _, err = jwtAuth.ParseUserID(ctx, token.GetAccessToken())
+	assert.EqualError(t, err, "invalid token")
+
+	_, err = keySet.SigningKey(now)
+	assert.Equal(t, ErrNoSigningKey, err)
+}
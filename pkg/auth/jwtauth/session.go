@@ -0,0 +1,248 @@
+package jwtauth
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"gin-casbin/pkg/auth"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 最后活动时间的更新间隔(单位秒，避免每次请求都写存储)
+const sessionTouchInterval = 60
+
+// RegisterSession 登记令牌所属的会话(记录客户端信息，未设定存储时忽略)
+func (a *JWTAuth) RegisterSession(ctx context.Context, accessToken, userAgent, ip string) error {
+	claims, err := a.parseToken(accessToken)
+	if err != nil {
+		return auth.ErrInvalidToken
+	} else if claims.SessionID == "" {
+		return auth.ErrInvalidToken
+	}
+
+	return a.callStore(func(store Storer) error {
+		now := time.Now().Unix()
+		session := &auth.Session{
+			ID:         claims.SessionID,
+			UserID:     claims.Subject,
+			TenantID:   claims.TenantID,
+			UserAgent:  userAgent,
+			IP:         ip,
+			CreatedAt:  now,
+			LastSeenAt: now,
+			IssuedAt:   claims.IssuedAt,
+			ExpiresAt:  now + int64(a.opts.refreshExpired),
+		}
+
+		a.sessionMu.Lock()
+		err := a.setSession(ctx, store, session)
+		a.sessionMu.Unlock()
+		if err != nil {
+			return err
+		}
+
+		err = store.SAdd(ctx, userSessionsKey(session.UserID), session.ID, a.maxExpiration())
+		if err != nil {
+			return err
+		}
+
+		if session.TenantID != "" {
+			return store.SAdd(ctx, tenantSessionsKey(session.TenantID), session.ID, a.maxExpiration())
+		}
+		return nil
+	})
+}
+
+// GetSession 获取有效的会话(不存在、已终止或已撤销时为空)
+func (a *JWTAuth) GetSession(ctx context.Context, sessionID string) (*auth.Session, error) {
+	var session *auth.Session
+	err := a.callStore(func(store Storer) error {
+		item, err := a.getValidSession(ctx, store, sessionID)
+		if err != nil {
+			return err
+		}
+		session = item
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// QueryUserSessions 查询用户的有效会话(按最后活动时间降序)
+func (a *JWTAuth) QueryUserSessions(ctx context.Context, userID string) ([]*auth.Session, error) {
+	return a.querySessions(ctx, userSessionsKey(userID))
+}
+
+// QueryTenantSessions 查询租户的有效会话(按最后活动时间降序)
+func (a *JWTAuth) QueryTenantSessions(ctx context.Context, tenantID string) ([]*auth.Session, error) {
+	return a.querySessions(ctx, tenantSessionsKey(tenantID))
+}
+
+// TerminateSession 终止会话(会话下的访问令牌和刷新令牌全部失效)
+func (a *JWTAuth) TerminateSession(ctx context.Context, sessionID string) error {
+	return a.callStore(func(store Storer) error {
+		return a.revokeSession(ctx, store, sessionID)
+	})
+}
+
+// 查询索引下的有效会话，并清理索引中已失效的会话
+func (a *JWTAuth) querySessions(ctx context.Context, indexKey string) ([]*auth.Session, error) {
+	sessions := make([]*auth.Session, 0)
+	err := a.callStore(func(store Storer) error {
+		ids, err := store.SMembers(ctx, indexKey)
+		if err != nil {
+			return err
+		}
+
+		var invalidIDs []string
+		for _, id := range ids {
+			session, err := a.getValidSession(ctx, store, id)
+			if err != nil {
+				return err
+			} else if session == nil {
+				invalidIDs = append(invalidIDs, id)
+				continue
+			}
+			sessions = append(sessions, session)
+		}
+		return store.SRem(ctx, indexKey, invalidIDs...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt > sessions[j].LastSeenAt
+	})
+	return sessions, nil
+}
+
+// 获取会话，已过期、已撤销或在用户(租户)水位线之前签发的会话视为无效
+func (a *JWTAuth) getValidSession(ctx context.Context, store Storer, sessionID string) (*auth.Session, error) {
+	if sessionID == "" {
+		return nil, nil
+	}
+
+	session, err := a.getSession(ctx, store, sessionID)
+	if err != nil {
+		return nil, err
+	} else if session == nil || time.Now().Unix() >= session.ExpiresAt {
+		return nil, nil
+	}
+
+	if revoked, err := store.Check(ctx, sessionKey(sessionID)); err != nil {
+		return nil, err
+	} else if revoked {
+		return nil, nil
+	}
+
+	if revoked, err := a.checkWatermark(ctx, store, session.UserID, session.TenantID, session.IssuedAt); err != nil {
+		return nil, err
+	} else if revoked {
+		return nil, nil
+	}
+	return session, nil
+}
+
+// 刷新令牌时更新会话的签发时间和到期时间(未登记的会话忽略)
+func (a *JWTAuth) renewSession(ctx context.Context, store Storer, sessionID string, issuedAt, expiresAt int64) error {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+
+	session, err := a.getSession(ctx, store, sessionID)
+	if err != nil || session == nil {
+		return err
+	}
+
+	session.IssuedAt = issuedAt
+	session.LastSeenAt = issuedAt
+	session.ExpiresAt = expiresAt
+	return a.setSession(ctx, store, session)
+}
+
+// 使用令牌时更新会话的最后活动时间(更新失败不影响令牌校验)
+func (a *JWTAuth) touchSession(ctx context.Context, store Storer, sessionID string) {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+
+	session, err := a.getSession(ctx, store, sessionID)
+	if err != nil {
+		logrus.Errorf("%s", err)
+		return
+	}
+
+	now := time.Now().Unix()
+	if session == nil || now-session.LastSeenAt < sessionTouchInterval {
+		return
+	}
+
+	session.LastSeenAt = now
+	if err := a.setSession(ctx, store, session); err != nil {
+		logrus.Errorf("%s", err)
+	}
+}
+
+// 删除会话记录并从索引中移除
+func (a *JWTAuth) removeSession(ctx context.Context, store Storer, sessionID string) error {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+
+	session, err := a.getSession(ctx, store, sessionID)
+	if err != nil || session == nil {
+		return err
+	}
+
+	err = store.SRem(ctx, userSessionsKey(session.UserID), sessionID)
+	if err != nil {
+		return err
+	}
+
+	if session.TenantID != "" {
+		err = store.SRem(ctx, tenantSessionsKey(session.TenantID), sessionID)
+		if err != nil {
+			return err
+		}
+	}
+	return store.Delete(ctx, sessionInfoKey(sessionID))
+}
+
+func (a *JWTAuth) getSession(ctx context.Context, store Storer, sessionID string) (*auth.Session, error) {
+	value, ok, err := store.Get(ctx, sessionInfoKey(sessionID))
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, nil
+	}
+
+	var session auth.Session
+	if err := json.Unmarshal([]byte(value), &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (a *JWTAuth) setSession(ctx context.Context, store Storer, session *auth.Session) error {
+	buf, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	expired := time.Unix(session.ExpiresAt, 0).Sub(time.Now())
+	return store.SetValue(ctx, sessionInfoKey(session.ID), string(buf), expired)
+}
+
+func sessionInfoKey(sessionID string) string {
+	return "session-info:" + sessionID
+}
+
+func userSessionsKey(userID string) string {
+	return "sessions:user:" + userID
+}
+
+func tenantSessionsKey(tenantID string) string {
+	return "sessions:tenant:" + tenantID
+}
@@ -11,6 +11,24 @@ type Storer interface {
 	Set(ctx context.Context, tokenString string, expiration time.Duration) error
 	// 检查令牌是否存在
 	Check(ctx context.Context, tokenString string) (bool, error)
+	// 存储键值数据，并指定到期时间
+	SetValue(ctx context.Context, key, value string, expiration time.Duration) error
+	// 键不存在时存储键值数据，并指定到期时间(原子操作，返回是否存储成功)
+	SetNX(ctx context.Context, key, value string, expiration time.Duration) (bool, error)
+	// 获取键值数据
+	Get(ctx context.Context, key string) (string, bool, error)
+	// 删除键
+	Delete(ctx context.Context, key string) error
+	// 向集合添加成员，并指定到期时间(原子操作)
+	SAdd(ctx context.Context, key, member string, expiration time.Duration) error
+	// 从集合删除成员(原子操作)
+	SRem(ctx context.Context, key string, members ...string) error
+	// 获取集合的全部成员
+	SMembers(ctx context.Context, key string) ([]string, error)
+	// 设定水位线(早于或等于该时间签发的令牌无效)，并指定到期时间
+	SetWatermark(ctx context.Context, key string, t time.Time, expiration time.Duration) error
+	// 获取水位线(未设定时为零值)
+	GetWatermark(ctx context.Context, key string) (time.Time, error)
 	// 关闭存储
 	Close() error
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/tidwall/buntdb"
@@ -42,6 +44,78 @@ func (a *Store) Set(ctx context.Context, tokenString string, expiration time.Dur
 	})
 }
 
+// SetValue ...
+func (a *Store) SetValue(ctx context.Context, key, value string, expiration time.Duration) error {
+	return a.db.Update(func(tx *buntdb.Tx) error {
+		var opts *buntdb.SetOptions
+		if expiration > 0 {
+			opts = &buntdb.SetOptions{Expires: true, TTL: expiration}
+		}
+		_, _, err := tx.Set(key, value, opts)
+		return err
+	})
+}
+
+// SetNX ...
+func (a *Store) SetNX(ctx context.Context, key, value string, expiration time.Duration) (bool, error) {
+	var ok bool
+	err := a.db.Update(func(tx *buntdb.Tx) error {
+		if _, err := tx.Get(key); err == nil {
+			return nil
+		} else if err != buntdb.ErrNotFound {
+			return err
+		}
+
+		var opts *buntdb.SetOptions
+		if expiration > 0 {
+			opts = &buntdb.SetOptions{Expires: true, TTL: expiration}
+		}
+		_, _, err := tx.Set(key, value, opts)
+		ok = err == nil
+		return err
+	})
+	return ok, err
+}
+
+// Get ...
+func (a *Store) Get(ctx context.Context, key string) (string, bool, error) {
+	var (
+		value string
+		ok    bool
+	)
+	err := a.db.View(func(tx *buntdb.Tx) error {
+		val, err := tx.Get(key)
+		if err != nil {
+			if err == buntdb.ErrNotFound {
+				return nil
+			}
+			return err
+		}
+		value, ok = val, true
+		return nil
+	})
+	return value, ok, err
+}
+
+// SetWatermark ...
+func (a *Store) SetWatermark(ctx context.Context, key string, t time.Time, expiration time.Duration) error {
+	return a.SetValue(ctx, key, strconv.FormatInt(t.UnixNano(), 10), expiration)
+}
+
+// GetWatermark ...
+func (a *Store) GetWatermark(ctx context.Context, key string) (time.Time, error) {
+	val, ok, err := a.Get(ctx, key)
+	if err != nil || !ok {
+		return time.Time{}, err
+	}
+
+	nsec, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, nsec), nil
+}
+
 // Delete 删除键
 func (a *Store) Delete(ctx context.Context, tokenString string) error {
 	return a.db.Update(func(tx *buntdb.Tx) error {
@@ -53,6 +127,45 @@ func (a *Store) Delete(ctx context.Context, tokenString string) error {
 	})
 }
 
+// 集合成员存储为以集合键为前缀的键(成员各自到期)
+func setMemberKey(key, member string) string {
+	return key + "\x00" + member
+}
+
+// SAdd ...
+func (a *Store) SAdd(ctx context.Context, key, member string, expiration time.Duration) error {
+	return a.SetValue(ctx, setMemberKey(key, member), member, expiration)
+}
+
+// SRem ...
+func (a *Store) SRem(ctx context.Context, key string, members ...string) error {
+	return a.db.Update(func(tx *buntdb.Tx) error {
+		for _, member := range members {
+			_, err := tx.Delete(setMemberKey(key, member))
+			if err != nil && err != buntdb.ErrNotFound {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SMembers ...
+func (a *Store) SMembers(ctx context.Context, key string) ([]string, error) {
+	var members []string
+	prefix := setMemberKey(key, "")
+	err := a.db.View(func(tx *buntdb.Tx) error {
+		return tx.AscendGreaterOrEqual("", prefix, func(k, v string) bool {
+			if !strings.HasPrefix(k, prefix) {
+				return false
+			}
+			members = append(members, v)
+			return true
+		})
+	})
+	return members, err
+}
+
 // Check ...
 func (a *Store) Check(ctx context.Context, tokenString string) (bool, error) {
 	var exists bool
@@ -3,6 +3,7 @@ package buntdb
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -22,6 +23,70 @@ func TestStore(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, true, b)
 
+	err = store.SetValue(ctx, key, "value", 0)
+	assert.Nil(t, err)
+
+	v, ok, err := store.Get(ctx, key)
+	assert.Nil(t, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "value", v)
+
+	ok, err = store.SetNX(ctx, key, "other", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, false, ok)
+
+	err = store.Delete(ctx, key)
+	assert.Nil(t, err)
+
+	_, ok, err = store.Get(ctx, key)
+	assert.Nil(t, err)
+	assert.Equal(t, false, ok)
+
+	ok, err = store.SetNX(ctx, key, "value", time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, true, ok)
+
+	err = store.Delete(ctx, key)
+	assert.Nil(t, err)
+
+	wt, err := store.GetWatermark(ctx, key)
+	assert.Nil(t, err)
+	assert.True(t, wt.IsZero())
+
+	now := time.Now()
+	err = store.SetWatermark(ctx, key, now, time.Minute)
+	assert.Nil(t, err)
+
+	wt, err = store.GetWatermark(ctx, key)
+	assert.Nil(t, err)
+	assert.True(t, now.Equal(wt))
+
 	err = store.Delete(ctx, key)
 	assert.Nil(t, err)
 }
+
+func TestStoreSet(t *testing.T) {
+	store, err := NewStore(":memory:")
+	assert.Nil(t, err)
+
+	defer store.Close()
+
+	ctx := context.Background()
+	assert.Nil(t, store.SAdd(ctx, "set", "a", time.Minute))
+	assert.Nil(t, store.SAdd(ctx, "set", "b", time.Minute))
+	assert.Nil(t, store.SAdd(ctx, "set", "a", time.Minute))
+	assert.Nil(t, store.SAdd(ctx, "set:other", "c", time.Minute))
+
+	members, err := store.SMembers(ctx, "set")
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"a", "b"}, members)
+
+	assert.Nil(t, store.SRem(ctx, "set", "a", "missing"))
+	members, err = store.SMembers(ctx, "set")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"b"}, members)
+
+	members, err = store.SMembers(ctx, "none")
+	assert.Nil(t, err)
+	assert.Empty(t, members)
+}
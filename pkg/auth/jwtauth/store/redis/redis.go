@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/go-redis/redis"
@@ -48,10 +49,13 @@ func NewStoreWithClusterClient(cli *redis.ClusterClient, keyPrefix string) *Stor
 type redisClienter interface {
 	Get(key string) *redis.StringCmd
 	Set(key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	SetNX(key string, value interface{}, expiration time.Duration) *redis.BoolCmd
 	Expire(key string, expiration time.Duration) *redis.BoolCmd
 	Exists(keys ...string) *redis.IntCmd
 	TxPipeline() redis.Pipeliner
 	Del(keys ...string) *redis.IntCmd
+	SRem(key string, members ...interface{}) *redis.IntCmd
+	SMembers(key string) *redis.StringSliceCmd
 	Close() error
 }
 
@@ -71,15 +75,86 @@ func (s *Store) Set(ctx context.Context, tokenString string, expiration time.Dur
 	return cmd.Err()
 }
 
+// SetValue ...
+func (s *Store) SetValue(ctx context.Context, key, value string, expiration time.Duration) error {
+	cmd := s.cli.Set(s.wrapperKey(key), value, expiration)
+	return cmd.Err()
+}
+
+// SetNX ...
+func (s *Store) SetNX(ctx context.Context, key, value string, expiration time.Duration) (bool, error) {
+	return s.cli.SetNX(s.wrapperKey(key), value, expiration).Result()
+}
+
+// Get ...
+func (s *Store) Get(ctx context.Context, key string) (string, bool, error) {
+	value, err := s.cli.Get(s.wrapperKey(key)).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// SetWatermark ...
+func (s *Store) SetWatermark(ctx context.Context, key string, t time.Time, expiration time.Duration) error {
+	return s.SetValue(ctx, key, strconv.FormatInt(t.UnixNano(), 10), expiration)
+}
+
+// GetWatermark ...
+func (s *Store) GetWatermark(ctx context.Context, key string) (time.Time, error) {
+	val, ok, err := s.Get(ctx, key)
+	if err != nil || !ok {
+		return time.Time{}, err
+	}
+
+	nsec, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, nsec), nil
+}
+
 // Delete ...
 func (s *Store) Delete(ctx context.Context, tokenString string) error {
-	cmd := s.cli.Del(tokenString)
+	cmd := s.cli.Del(s.wrapperKey(tokenString))
 	if err := cmd.Err(); err != nil {
 		return err
 	}
 	return nil
 }
 
+// SAdd ...
+func (s *Store) SAdd(ctx context.Context, key, member string, expiration time.Duration) error {
+	key = s.wrapperKey(key)
+	pipe := s.cli.TxPipeline()
+	pipe.SAdd(key, member)
+	if expiration > 0 {
+		pipe.Expire(key, expiration)
+	}
+	_, err := pipe.Exec()
+	return err
+}
+
+// SRem ...
+func (s *Store) SRem(ctx context.Context, key string, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	values := make([]interface{}, len(members))
+	for i, member := range members {
+		values[i] = member
+	}
+	return s.cli.SRem(s.wrapperKey(key), values...).Err()
+}
+
+// SMembers ...
+func (s *Store) SMembers(ctx context.Context, key string) ([]string, error) {
+	return s.cli.SMembers(s.wrapperKey(key)).Result()
+}
+
 // Check ...
 func (s *Store) Check(ctx context.Context, tokenString string) (bool, error) {
 	cmd := s.cli.Exists(s.wrapperKey(tokenString))
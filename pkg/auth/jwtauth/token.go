@@ -2,13 +2,19 @@ package jwtauth
 
 import (
 	"encoding/json"
+
+	"gin-casbin/pkg/auth"
+
+	jwt "github.com/dgrijalva/jwt-go"
 )
 
 // tokenInfo 令牌信息
 type tokenInfo struct {
-	AccessToken string `json:"access_token"` // 访问令牌
-	TokenType   string `json:"token_type"`   // 令牌类型
-	ExpiresAt   int64  `json:"expires_at"`   // 令牌到期时间
+	AccessToken      string `json:"access_token"`       // 访问令牌
+	TokenType        string `json:"token_type"`         // 令牌类型
+	ExpiresAt        int64  `json:"expires_at"`         // 令牌到期时间
+	RefreshToken     string `json:"refresh_token"`      // 刷新令牌
+	RefreshExpiresAt int64  `json:"refresh_expires_at"` // 刷新令牌到期时间
 }
 
 func (t *tokenInfo) GetAccessToken() string {
@@ -23,6 +29,48 @@ func (t *tokenInfo) GetExpiresAt() int64 {
 	return t.ExpiresAt
 }
 
+func (t *tokenInfo) GetRefreshToken() string {
+	return t.RefreshToken
+}
+
+func (t *tokenInfo) GetRefreshExpiresAt() int64 {
+	return t.RefreshExpiresAt
+}
+
 func (t *tokenInfo) EncodeToJSON() ([]byte, error) {
 	return json.Marshal(t)
 }
+
+// tokenClaims 令牌声明(jti为每个令牌唯一，sid为同一次登录刷新出的令牌共用)
+type tokenClaims struct {
+	jwt.StandardClaims
+	TenantID  string   `json:"tid,omitempty"`   // 租户ID
+	RoleIDs   []string `json:"roles,omitempty"` // 角色ID列表
+	IsAdmin   bool     `json:"adm,omitempty"`   // 是否管理用户
+	SessionID string   `json:"sid,omitempty"`   // 会话ID
+}
+
+func (c *tokenClaims) toClaims() *auth.Claims {
+	return &auth.Claims{
+		UserID:    c.Subject,
+		TenantID:  c.TenantID,
+		RoleIDs:   c.RoleIDs,
+		IsAdmin:   c.IsAdmin,
+		SessionID: c.SessionID,
+		Issuer:    c.Issuer,
+		Audience:  c.Audience,
+		IssuedAt:  c.IssuedAt,
+		ExpiresAt: c.ExpiresAt,
+	}
+}
+
+// refreshRecord 刷新令牌的存储数据
+type refreshRecord struct {
+	UserID    string   `json:"user_id"`    // 用户ID
+	TenantID  string   `json:"tenant_id"`  // 租户ID
+	RoleIDs   []string `json:"role_ids"`   // 角色ID列表
+	IsAdmin   bool     `json:"is_admin"`   // 是否管理用户
+	SessionID string   `json:"session_id"` // 会话ID(同一次登录轮换出的所有令牌)
+	IssuedAt  int64    `json:"issued_at"`  // 签发时间
+	ExpiresAt int64    `json:"expires_at"` // 到期时间
+}
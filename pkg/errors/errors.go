@@ -7,11 +7,13 @@ import (
 // 定义别名
 var (
 	New          = errors.New
+	Errorf       = errors.Errorf
 	Wrap         = errors.Wrap
 	Wrapf        = errors.Wrapf
 	WithStack    = errors.WithStack
 	WithMessage  = errors.WithMessage
 	WithMessagef = errors.WithMessagef
+	Cause        = errors.Cause
 )
 
 // 定义错误
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"gin-casbin/pkg/watcher"
+
+	"github.com/lib/pq"
+)
+
+// NOTIFY消息的最大长度(字节)
+const maxPayloadSize = 8000
+
+// Config postgres配置参数
+type Config struct {
+	DSN     string // 连接串
+	Channel string // 监听频道
+}
+
+// NewWatcher 创建基于postgres LISTEN/NOTIFY的策略变更监听实例
+func NewWatcher(cfg *Config) (*watcher.Watcher, error) {
+	db, err := sql.Open("postgres", cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+	return watcher.New(NewPubSub(db, cfg.DSN, cfg.Channel))
+}
+
+// NewPubSub 使用数据库连接创建发布订阅实例
+func NewPubSub(db *sql.DB, dsn, channel string) *PubSub {
+	return &PubSub{
+		db:      db,
+		dsn:     dsn,
+		channel: channel,
+		done:    make(chan struct{}),
+	}
+}
+
+// PubSub postgres发布订阅
+type PubSub struct {
+	db       *sql.DB
+	dsn      string
+	channel  string
+	listener *pq.Listener
+	done     chan struct{}
+}
+
+// Publish 发布消息
+func (a *PubSub) Publish(payload string) error {
+	if len(payload) >= maxPayloadSize {
+		return watcher.ErrPayloadTooLarge
+	}
+	_, err := a.db.Exec("SELECT pg_notify($1, $2)", a.channel, payload)
+	return err
+}
+
+// Subscribe 订阅消息
+func (a *PubSub) Subscribe(fn func(payload string)) error {
+	listener := pq.NewListener(a.dsn, 10*time.Second, time.Minute, nil)
+	if err := listener.Listen(a.channel); err != nil {
+		_ = listener.Close()
+		return err
+	}
+	a.listener = listener
+
+	go func() {
+		for {
+			select {
+			case n := <-listener.Notify:
+				// 重新连接后会收到nil，期间的消息可能已丢失，由定时全量加载兜底
+				if n != nil {
+					fn(n.Extra)
+				}
+			case <-time.After(90 * time.Second):
+				go func() {
+					_ = listener.Ping()
+				}()
+			case <-a.done:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Close 关闭
+func (a *PubSub) Close() error {
+	close(a.done)
+	if a.listener != nil {
+		_ = a.listener.Close()
+	}
+	return a.db.Close()
+}
@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"gin-casbin/pkg/watcher"
+
+	"github.com/go-redis/redis"
+)
+
+// 默认的消息最大长度(字节)，大消息会同时阻塞所有订阅节点
+const defaultMaxPayloadSize = 1 << 20
+
+// Config redis配置参数
+type Config struct {
+	Addr           string // 地址(IP:Port)
+	DB             int    // 数据库
+	Password       string // 密码
+	Channel        string // 订阅频道
+	MaxPayloadSize int    // 消息最大长度(字节，超过时通知其他节点全量重新加载，默认1MB)
+}
+
+// NewWatcher 创建基于redis发布订阅的策略变更监听实例
+func NewWatcher(cfg *Config) (*watcher.Watcher, error) {
+	cli := redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		DB:       cfg.DB,
+		Password: cfg.Password,
+	})
+	return watcher.New(NewPubSub(cli, cfg.Channel, cfg.MaxPayloadSize))
+}
+
+// NewPubSub 使用redis客户端创建发布订阅实例(maxPayloadSize为0时使用默认值)
+func NewPubSub(cli *redis.Client, channel string, maxPayloadSize int) *PubSub {
+	if maxPayloadSize <= 0 {
+		maxPayloadSize = defaultMaxPayloadSize
+	}
+	return &PubSub{
+		cli:            cli,
+		channel:        channel,
+		maxPayloadSize: maxPayloadSize,
+	}
+}
+
+// PubSub redis发布订阅
+type PubSub struct {
+	cli            *redis.Client
+	channel        string
+	maxPayloadSize int
+	sub            *redis.PubSub
+}
+
+// Publish 发布消息
+func (a *PubSub) Publish(payload string) error {
+	if len(payload) > a.maxPayloadSize {
+		return watcher.ErrPayloadTooLarge
+	}
+	return a.cli.Publish(a.channel, payload).Err()
+}
+
+// Subscribe 订阅消息
+func (a *PubSub) Subscribe(fn func(payload string)) error {
+	sub := a.cli.Subscribe(a.channel)
+	if _, err := sub.Receive(); err != nil {
+		_ = sub.Close()
+		return err
+	}
+	a.sub = sub
+
+	go func() {
+		for msg := range sub.Channel() {
+			fn(msg.Payload)
+		}
+	}()
+	return nil
+}
+
+// Close 关闭
+func (a *PubSub) Close() error {
+	if a.sub != nil {
+		_ = a.sub.Close()
+	}
+	return a.cli.Close()
+}
@@ -0,0 +1,144 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"gin-casbin/pkg/watcher"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+const testChannel = "casbin"
+
+func newTestWatcher(t *testing.T, s *miniredis.Miniredis, maxPayloadSize int) (*watcher.Watcher, chan string) {
+	cli := redis.NewClient(&redis.Options{
+		Addr:       s.Addr(),
+		MaxRetries: 3,
+	})
+	w, err := watcher.New(NewPubSub(cli, testChannel, maxPayloadSize))
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+
+	ch := make(chan string, 10)
+	assert.Nil(t, w.SetUpdateCallback(func(s string) { ch <- s }))
+	return w, ch
+}
+
+func receiveMessage(t *testing.T, ch chan string) *watcher.Message {
+	select {
+	case payload := <-ch:
+		msg, err := watcher.ParseMessage(payload)
+		assert.Nil(t, err)
+		return msg
+	case <-time.After(time.Second):
+		return nil
+	}
+}
+
+// 等待订阅数达到预期(订阅和重新连接在后台进行)
+func waitSubscribers(t *testing.T, s *miniredis.Miniredis, n int) {
+	for i := 0; i < 100; i++ {
+		if s.PubSubNumSub(testChannel)[testChannel] == n {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("expected %d subscribers", n)
+}
+
+func TestWatcher(t *testing.T) {
+	s, err := miniredis.Run()
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer s.Close()
+
+	w1, ch1 := newTestWatcher(t, s, 0)
+	defer w1.Close()
+	w2, ch2 := newTestWatcher(t, s, 0)
+	defer w2.Close()
+	waitSubscribers(t, s, 2)
+
+	err = w1.Publish(&watcher.Message{
+		Method:         watcher.MethodUpdate,
+		AddedGroupings: [][]string{{"u1", "r1", "t1"}},
+	})
+	assert.Nil(t, err)
+
+	msg := receiveMessage(t, ch2)
+	if assert.NotNil(t, msg) {
+		assert.Equal(t, watcher.MethodUpdate, msg.Method)
+		assert.Equal(t, w1.ID(), msg.Source)
+		assert.Equal(t, [][]string{{"u1", "r1", "t1"}}, msg.AddedGroupings)
+	}
+
+	// 不接收自身发出的消息
+	assert.Nil(t, receiveMessage(t, ch1))
+
+	// 无法解析的消息被忽略
+	s.Publish(testChannel, "not json")
+	assert.Nil(t, receiveMessage(t, ch1))
+}
+
+func TestWatcherPayloadTooLarge(t *testing.T) {
+	s, err := miniredis.Run()
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer s.Close()
+
+	w1, _ := newTestWatcher(t, s, 100)
+	defer w1.Close()
+	w2, ch := newTestWatcher(t, s, 0)
+	defer w2.Close()
+	waitSubscribers(t, s, 2)
+
+	var rules [][]string
+	for i := 0; i < 20; i++ {
+		rules = append(rules, []string{"r1", "t1", "/api/v1/users/:id", "GET"})
+	}
+	err = w1.Publish(&watcher.Message{
+		Method:        watcher.MethodUpdate,
+		AddedPolicies: rules,
+	})
+	assert.Nil(t, err)
+
+	// 消息过大时通知其他节点全量重新加载
+	msg := receiveMessage(t, ch)
+	if assert.NotNil(t, msg) {
+		assert.Equal(t, watcher.MethodReload, msg.Method)
+		assert.Empty(t, msg.AddedPolicies)
+	}
+}
+
+func TestWatcherReconnect(t *testing.T) {
+	s, err := miniredis.Run()
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer s.Close()
+
+	w1, _ := newTestWatcher(t, s, 0)
+	defer w1.Close()
+	w2, ch := newTestWatcher(t, s, 0)
+	defer w2.Close()
+	waitSubscribers(t, s, 2)
+
+	// 服务重启后订阅自动恢复
+	s.Close()
+	if !assert.Nil(t, s.Restart()) {
+		return
+	}
+	waitSubscribers(t, s, 2)
+
+	assert.Nil(t, w1.Update())
+	msg := receiveMessage(t, ch)
+	if assert.NotNil(t, msg) {
+		assert.Equal(t, watcher.MethodReload, msg.Method)
+		assert.Equal(t, w1.ID(), msg.Source)
+	}
+}
@@ -0,0 +1,129 @@
+package watcher
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/google/uuid"
+)
+
+var _ persist.Watcher = (*Watcher)(nil)
+
+// 定义错误
+var (
+	ErrPayloadTooLarge = errors.New("watcher payload too large")
+)
+
+// 定义消息方法
+const (
+	MethodReload = "reload" // 全量重新加载
+	MethodUpdate = "update" // 增量更新
+)
+
+// Message 策略变更消息
+type Message struct {
+	Source           string     `json:"source"`                      // 发送节点
+	Method           string     `json:"method"`                      // 消息方法
+	AddedPolicies    [][]string `json:"added_policies,omitempty"`    // 新增的p规则
+	RemovedPolicies  [][]string `json:"removed_policies,omitempty"`  // 删除的p规则
+	AddedGroupings   [][]string `json:"added_groupings,omitempty"`   // 新增的g规则
+	RemovedGroupings [][]string `json:"removed_groupings,omitempty"` // 删除的g规则
+}
+
+// ParseMessage 解析消息
+func ParseMessage(payload string) (*Message, error) {
+	var msg Message
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// PubSub 消息发布订阅
+type PubSub interface {
+	// 发布消息
+	Publish(payload string) error
+	// 订阅消息(非阻塞)
+	Subscribe(fn func(payload string)) error
+	// 关闭
+	Close() error
+}
+
+// New 创建策略变更监听实例
+func New(ps PubSub) (*Watcher, error) {
+	w := &Watcher{
+		id: uuid.New().String(),
+		ps: ps,
+	}
+
+	if err := ps.Subscribe(w.receive); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Watcher 策略变更监听(通过PubSub在多个节点间广播)
+type Watcher struct {
+	id       string
+	ps       PubSub
+	lock     sync.RWMutex
+	callback func(string)
+}
+
+// ID 节点标识
+func (w *Watcher) ID() string {
+	return w.id
+}
+
+func (w *Watcher) receive(payload string) {
+	msg, err := ParseMessage(payload)
+	if err != nil || msg.Source == w.id {
+		return
+	}
+
+	w.lock.RLock()
+	callback := w.callback
+	w.lock.RUnlock()
+
+	if callback != nil {
+		callback(payload)
+	}
+}
+
+// SetUpdateCallback sets the callback function that the watcher will call
+// when the policy in DB has been changed by other instances.
+func (w *Watcher) SetUpdateCallback(fn func(string)) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.callback = fn
+	return nil
+}
+
+// Update calls the update callback of other instances to synchronize their policy.
+// It is usually called after changing the policy in DB.
+func (w *Watcher) Update() error {
+	return w.Publish(&Message{Method: MethodReload})
+}
+
+// Publish 发布策略变更消息(消息过大时通知其他节点全量重新加载)
+func (w *Watcher) Publish(msg *Message) error {
+	msg.Source = w.id
+
+	buf, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	err = w.ps.Publish(string(buf))
+	if err == ErrPayloadTooLarge && msg.Method != MethodReload {
+		return w.Update()
+	}
+	return err
+}
+
+// Close stops and releases the watcher.
+func (w *Watcher) Close() {
+	_ = w.ps.Close()
+}
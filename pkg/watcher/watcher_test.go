@@ -0,0 +1,133 @@
+package watcher
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 本地内存消息代理(模拟redis发布订阅)
+type memoryBroker struct {
+	lock sync.Mutex
+	subs []func(string)
+}
+
+func (b *memoryBroker) NewPubSub() PubSub {
+	return &memoryPubSub{broker: b}
+}
+
+type memoryPubSub struct {
+	broker *memoryBroker
+	limit  int
+}
+
+func (a *memoryPubSub) Publish(payload string) error {
+	if a.limit > 0 && len(payload) > a.limit {
+		return ErrPayloadTooLarge
+	}
+
+	a.broker.lock.Lock()
+	subs := append([]func(string){}, a.broker.subs...)
+	a.broker.lock.Unlock()
+
+	for _, fn := range subs {
+		go fn(payload)
+	}
+	return nil
+}
+
+func (a *memoryPubSub) Subscribe(fn func(payload string)) error {
+	a.broker.lock.Lock()
+	defer a.broker.lock.Unlock()
+	a.broker.subs = append(a.broker.subs, fn)
+	return nil
+}
+
+func (a *memoryPubSub) Close() error {
+	return nil
+}
+
+func receiveMessage(t *testing.T, ch chan string) *Message {
+	select {
+	case payload := <-ch:
+		msg, err := ParseMessage(payload)
+		assert.Nil(t, err)
+		return msg
+	case <-time.After(time.Second):
+		return nil
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	broker := new(memoryBroker)
+
+	w1, err := New(broker.NewPubSub())
+	assert.Nil(t, err)
+	defer w1.Close()
+
+	w2, err := New(broker.NewPubSub())
+	assert.Nil(t, err)
+	defer w2.Close()
+
+	ch1 := make(chan string, 10)
+	ch2 := make(chan string, 10)
+	assert.Nil(t, w1.SetUpdateCallback(func(s string) { ch1 <- s }))
+	assert.Nil(t, w2.SetUpdateCallback(func(s string) { ch2 <- s }))
+
+	err = w1.Publish(&Message{
+		Method:         MethodUpdate,
+		AddedGroupings: [][]string{{"t1::u1", "r1"}},
+	})
+	assert.Nil(t, err)
+
+	msg := receiveMessage(t, ch2)
+	if assert.NotNil(t, msg) {
+		assert.Equal(t, MethodUpdate, msg.Method)
+		assert.Equal(t, w1.ID(), msg.Source)
+		assert.Equal(t, [][]string{{"t1::u1", "r1"}}, msg.AddedGroupings)
+	}
+
+	// 不接收自身发出的消息
+	assert.Nil(t, receiveMessage(t, ch1))
+
+	err = w2.Update()
+	assert.Nil(t, err)
+
+	msg = receiveMessage(t, ch1)
+	if assert.NotNil(t, msg) {
+		assert.Equal(t, MethodReload, msg.Method)
+	}
+}
+
+func TestWatcherPayloadTooLarge(t *testing.T) {
+	broker := new(memoryBroker)
+
+	w1, err := New(&memoryPubSub{broker: broker, limit: 100})
+	assert.Nil(t, err)
+	defer w1.Close()
+
+	w2, err := New(broker.NewPubSub())
+	assert.Nil(t, err)
+	defer w2.Close()
+
+	ch := make(chan string, 10)
+	assert.Nil(t, w2.SetUpdateCallback(func(s string) { ch <- s }))
+
+	var rules [][]string
+	for i := 0; i < 20; i++ {
+		rules = append(rules, []string{"r1", "/api/v1/users/:id", "GET"})
+	}
+	err = w1.Publish(&Message{
+		Method:        MethodUpdate,
+		AddedPolicies: rules,
+	})
+	assert.Nil(t, err)
+
+	msg := receiveMessage(t, ch)
+	if assert.NotNil(t, msg) {
+		assert.Equal(t, MethodReload, msg.Method)
+		assert.Empty(t, msg.AddedPolicies)
+	}
+}